@@ -11,11 +11,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"github.com/gorilla/mux"
 	"github.com/moby/moby/api/server/httputils"
 	"github.com/moby/moby/api/types/versions"
+	"golang.org/x/net/websocket"
 
 	"github.com/runfinch/finch-daemon/api/response"
 	"github.com/runfinch/finch-daemon/api/types"
@@ -50,6 +52,7 @@ func (h *handler) attach(w http.ResponseWriter, r *http.Request) {
 	// setup stop channel to communicate with logviewer,
 	stopChannel := make(chan os.Signal, 1)
 	signal.Notify(stopChannel, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(stopChannel)
 	go checkConnection(conn, func() {
 		stopChannel <- os.Interrupt
 	})
@@ -94,6 +97,99 @@ func (h *handler) attach(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// attachWebSocket handles the http request for attaching containers over a websocket, for clients which cannot
+// hijack the connection like browsers. The streams are never multiplexed as the websocket frames delimit them,
+// and the frames of the client are forwarded to the stdin of the container if requested.
+// Modified from https://github.com/moby/moby/blob/5a9201ff477dd0f855d8f7fe59e9d59c4d90ac37/api/server/router/container/container_routes.go#L718.
+func (h *handler) attachWebSocket(w http.ResponseWriter, r *http.Request) {
+	// done is closed when the attach ends, which closes the websocket connection.
+	done := make(chan struct{})
+	// served is closed once the websocket server, if started, is done with the request, which it then owns.
+	var served chan struct{}
+	// reading is done once the frames of the client are no longer read.
+	var reading sync.WaitGroup
+
+	// setup stop channel to communicate with logviewer, which is notified when the client closes the websocket.
+	stopChannel := make(chan os.Signal, 1)
+	signal.Notify(stopChannel, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(stopChannel)
+
+	useStdin := httputils.BoolValue(r, "stdin")
+	stdin, stdinWriter := io.Pipe()
+
+	setupStreams := func() (io.Writer, io.Writer, chan os.Signal, func(), error) {
+		wsChan := make(chan *websocket.Conn)
+		served = make(chan struct{})
+		srv := websocket.Server{Handler: func(conn *websocket.Conn) {
+			wsChan <- conn
+			<-done
+		}}
+		go func() {
+			srv.ServeHTTP(w, r)
+			close(served)
+		}()
+
+		var conn *websocket.Conn
+		select {
+		case conn = <-wsChan:
+		case <-served:
+			return nil, nil, nil, nil, fmt.Errorf("failed to upgrade the connection to a websocket")
+		}
+		if versions.GreaterThanOrEqualTo(httputils.VersionFromContext(r.Context()), "1.28") {
+			conn.PayloadType = websocket.BinaryFrame
+		}
+
+		// the frames of the client are discarded unless they are forwarded to stdin, until the websocket is closed.
+		in := io.Discard
+		if useStdin {
+			in = stdinWriter
+		}
+		reading.Add(1)
+		go func() {
+			defer reading.Done()
+			io.Copy(in, conn)
+			stdinWriter.Close()
+			select {
+			case stopChannel <- os.Interrupt:
+			default:
+			}
+		}()
+		return conn, conn, stopChannel, func() {}, nil
+	}
+
+	opts := &types.AttachOptions{
+		GetStreams: setupStreams,
+		UseStdin:   useStdin,
+		UseStdout:  httputils.BoolValue(r, "stdout"),
+		UseStderr:  httputils.BoolValue(r, "stderr"),
+		Logs:       httputils.BoolValue(r, "logs"),
+		Stream:     httputils.BoolValue(r, "stream"),
+		MuxStreams: false,
+		Stdin:      stdin,
+	}
+
+	cid := mux.Vars(r)["id"]
+	err := h.service.Attach(r.Context(), cid, opts)
+	close(done)
+	// closing stdin unblocks the forwarding of a client frame which is no longer read.
+	stdin.Close()
+	if served != nil {
+		// the connection is closed once the websocket server is done, which ends the reads of the client frames.
+		<-served
+		reading.Wait()
+		if err != nil {
+			h.logger.Errorf("failed to attach to container %s over websocket: %s", cid, err)
+		}
+		return
+	}
+
+	code := http.StatusInternalServerError
+	if errdefs.IsNotFound(err) {
+		code = http.StatusNotFound
+	}
+	response.JSON(w, code, response.NewError(err))
+}
+
 // checkUpgradeStatus checks if the connection needs to be upgraded and returns the correct
 // type and response.
 func checkUpgradeStatus(ctx context.Context, upgrade bool) (string, string) {
@@ -17,6 +17,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/moby/moby/api/server/httputils"
+	"golang.org/x/net/websocket"
 
 	"github.com/runfinch/finch-daemon/api/types"
 
@@ -135,6 +136,113 @@ var _ = Describe("Container Attach API", func() {
 			Expect(rr.Closed()).Should(BeTrue())
 		})
 	})
+	Context("websocket handler", func() {
+		var (
+			router *mux.Router
+			srv    *httptest.Server
+		)
+		BeforeEach(func() {
+			router = mux.NewRouter()
+			router.HandleFunc("/containers/{id}/attach/ws", h.attachWebSocket)
+			srv = httptest.NewServer(router)
+		})
+		AfterEach(func() {
+			srv.Close()
+		})
+		dial := func(query string) (*websocket.Conn, error) {
+			return websocket.Dial(strings.Replace(srv.URL, "http", "ws", 1)+"/containers/123/attach/ws?"+query, "", srv.URL)
+		}
+		It("should stream the container output over the websocket without multiplexing it", func() {
+			service.EXPECT().Attach(gomock.Any(), "123", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, opts *types.AttachOptions) error {
+					Expect(opts.UseStdout).Should(BeTrue())
+					Expect(opts.UseStderr).Should(BeTrue())
+					Expect(opts.Logs).Should(BeTrue())
+					Expect(opts.Stream).Should(BeFalse())
+					Expect(opts.MuxStreams).Should(BeFalse())
+					stdout, stderr, _, printSuccessResp, err := opts.GetStreams()
+					Expect(err).ShouldNot(HaveOccurred())
+					printSuccessResp()
+					fmt.Fprint(stdout, "out")
+					fmt.Fprint(stderr, "err")
+					return nil
+				})
+
+			conn, err := dial("stdout=1&stderr=1&logs=1")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer conn.Close()
+			var msg string
+			Expect(websocket.Message.Receive(conn, &msg)).Should(Succeed())
+			Expect(msg).Should(Equal("out"))
+			Expect(websocket.Message.Receive(conn, &msg)).Should(Succeed())
+			Expect(msg).Should(Equal("err"))
+			Expect(websocket.Message.Receive(conn, &msg)).Should(MatchError(io.EOF))
+		})
+		It("should stop streaming when the client closes the websocket", func() {
+			stopped := make(chan struct{})
+			service.EXPECT().Attach(gomock.Any(), "123", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, opts *types.AttachOptions) error {
+					_, _, stopChannel, _, err := opts.GetStreams()
+					Expect(err).ShouldNot(HaveOccurred())
+					<-stopChannel
+					close(stopped)
+					return nil
+				})
+
+			conn, err := dial("stdout=1&stream=1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(conn.Close()).Should(Succeed())
+			Eventually(stopped).Should(BeClosed())
+		})
+		It("should forward the frames of the client to stdin", func() {
+			forwarded := make(chan struct{})
+			service.EXPECT().Attach(gomock.Any(), "123", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, opts *types.AttachOptions) error {
+					Expect(opts.UseStdin).Should(BeTrue())
+					_, _, _, _, err := opts.GetStreams()
+					Expect(err).ShouldNot(HaveOccurred())
+					input, err := io.ReadAll(opts.Stdin)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(string(input)).Should(Equal("input"))
+					close(forwarded)
+					return nil
+				})
+
+			conn, err := dial("stdin=1&stream=1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(websocket.Message.Send(conn, "input")).Should(Succeed())
+			Expect(conn.Close()).Should(Succeed())
+			Eventually(forwarded).Should(BeClosed())
+		})
+		It("should return a 404 error for container not found", func() {
+			service.EXPECT().Attach(gomock.Any(), "123", gomock.Any()).Return(
+				errdefs.NewNotFound(fmt.Errorf("no such container: 123")))
+			req, _ = http.NewRequest(http.MethodGet, "/containers/123/attach/ws", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "123"})
+			rec := httptest.NewRecorder()
+
+			h.attachWebSocket(rec, req)
+
+			Expect(rec.Code).Should(Equal(http.StatusNotFound))
+			Expect(rec.Body).Should(MatchJSON(`{"message": "no such container: 123"}`))
+		})
+		It("should log errors once the websocket is established", func() {
+			service.EXPECT().Attach(gomock.Any(), "123", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, opts *types.AttachOptions) error {
+					_, _, _, _, err := opts.GetStreams()
+					Expect(err).ShouldNot(HaveOccurred())
+					return fmt.Errorf("attach error")
+				})
+			logged := make(chan struct{})
+			logger.EXPECT().Errorf("failed to attach to container %s over websocket: %s", "123", fmt.Errorf("attach error")).Do(
+				func(string, ...interface{}) { close(logged) })
+
+			conn, err := dial("stdout=1")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer conn.Close()
+			Eventually(logged).Should(BeClosed())
+		})
+	})
 	Context("testing the checkUpgradeStatus helper function", func() {
 		var (
 			defHeader      func(ct string) string
@@ -24,7 +24,7 @@ type Service interface {
 	Start(ctx context.Context, cid string, options ncTypes.ContainerStartOptions) error
 	Stop(ctx context.Context, cid string, option ncTypes.ContainerStopOptions) error
 	Restart(ctx context.Context, cid string, options ncTypes.ContainerRestartOptions) error
-	Create(ctx context.Context, image string, cmd []string, createOpt ncTypes.ContainerCreateOptions, netOpt ncTypes.NetworkOptions) (string, error)
+	Create(ctx context.Context, image string, cmd []string, createOpt ncTypes.ContainerCreateOptions, netOpt ncTypes.NetworkOptions, req *types.ContainerCreateRequest) (string, []string, error)
 	Inspect(ctx context.Context, cid string, size bool) (*types.Container, error)
 	WriteFilesAsTarArchive(filePath string, writer io.Writer, slashDot bool) error
 	Attach(ctx context.Context, cid string, opts *types.AttachOptions) error
@@ -55,6 +55,7 @@ func RegisterHandlers(r types.VersionedRouter, service Service, conf *config.Con
 	r.HandleFunc("/{id:.*}/json", h.inspect, http.MethodGet)
 	r.HandleFunc("/{id:.*}/archive", h.getArchive, http.MethodGet)
 	r.HandleFunc("/{id:.*}/attach", h.attach, http.MethodPost)
+	r.HandleFunc("/{id:.*}/attach/ws", h.attachWebSocket, http.MethodGet)
 	r.HandleFunc("/json", h.list, http.MethodGet)
 	r.HandleFunc("/{id:.*}/rename", h.rename, http.MethodPost)
 	r.HandleFunc("/{id:.*}/logs", h.logs, http.MethodGet)
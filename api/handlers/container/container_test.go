@@ -88,7 +88,7 @@ var _ = Describe("Container API", func() {
 		It("should call container create method", func() {
 			// setup mocks
 			body := []byte(`{"Image": "test-image"}`)
-			service.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("", fmt.Errorf("error from create api"))
+			service.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("", nil, fmt.Errorf("error from create api"))
 			req, _ = http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 			// call the API to check if it returns the error generated from create method
 			router.ServeHTTP(rr, req)
@@ -29,7 +29,8 @@ import (
 const errGatheringDeviceInfo = "error gathering device information while adding custom device"
 
 type containerCreateResponse struct {
-	ID string `json:"Id"`
+	ID       string   `json:"Id"`
+	Warnings []string `json:"Warnings"`
 }
 
 func (h *handler) create(w http.ResponseWriter, r *http.Request) {
@@ -213,6 +214,11 @@ func (h *handler) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	globalOpt := ncTypes.GlobalCommandOptions(*h.Config)
+	// snapshotter is a finch extension creating the container, and unpacking its image, with another snapshotter
+	// than the configured one, e.g. to lazily pull large images with stargz or soci.
+	if snapshotter := r.URL.Query().Get("snapshotter"); snapshotter != "" {
+		globalOpt.Snapshotter = snapshotter
+	}
 	createOpt := ncTypes.ContainerCreateOptions{
 		Stdout:   nil,
 		Stderr:   nil,
@@ -246,6 +252,8 @@ func (h *handler) create(w http.ResponseWriter, r *http.Request) {
 		CPUShares:            uint64(req.HostConfig.CPUShares), // CPU shares (relative weight)
 		CPUQuota:             CpuQuota,                         // CPUQuota limits the CPU CFS (Completely Fair Scheduler) quota
 		CPUPeriod:            uint64(req.HostConfig.CPUPeriod),
+		CPURealtimePeriod:    uint64(req.HostConfig.CPURealtimePeriod),
+		CPURealtimeRuntime:   uint64(req.HostConfig.CPURealtimeRuntime),
 		Memory:               memory,            // memory limit (in bytes)
 		MemorySwap:           memorySwap,        // Total memory usage (memory + swap); set `-1` to enable unlimited swap
 		MemoryReservation:    memoryReservation, // Memory soft limit (in bytes)
@@ -351,7 +359,7 @@ func (h *handler) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := namespaces.WithNamespace(r.Context(), h.Config.Namespace)
-	cid, err := h.service.Create(ctx, req.Image, req.Cmd, createOpt, netOpt)
+	cid, warnings, err := h.service.Create(ctx, req.Image, req.Cmd, createOpt, netOpt, &req)
 	if err != nil {
 		var code int
 		switch {
@@ -361,6 +369,8 @@ func (h *handler) create(w http.ResponseWriter, r *http.Request) {
 			code = http.StatusBadRequest
 		case errdefs.IsConflict(err):
 			code = http.StatusConflict
+		case errdefs.IsForbiddenError(err):
+			code = http.StatusForbidden
 		default:
 			code = http.StatusInternalServerError
 		}
@@ -368,7 +378,10 @@ func (h *handler) create(w http.ResponseWriter, r *http.Request) {
 		response.SendErrorResponse(w, code, err)
 		return
 	}
-	response.JSON(w, http.StatusCreated, containerCreateResponse{cid})
+	if warnings == nil {
+		warnings = []string{}
+	}
+	response.JSON(w, http.StatusCreated, containerCreateResponse{ID: cid, Warnings: warnings})
 }
 
 // translateTmpfs converts a map of tmpfs mounts to a slice of strings in the format "DEST:OPTIONS".
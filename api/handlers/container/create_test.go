@@ -47,7 +47,7 @@ var _ = Describe("Container Create API ", func() {
 		createOpt = getDefaultCreateOpt(c)
 		netOpt = getDefaultNetOpt()
 		cid = "123"
-		jsonResponse = `{"Id": "123"}`
+		jsonResponse = `{"Id": "123", "Warnings": []}`
 		h = newHandler(service, &c, logger)
 		rr = httptest.NewRecorder()
 	})
@@ -57,8 +57,8 @@ var _ = Describe("Container Create API ", func() {
 			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 
 			// service mock returns container id and nil error upon success.
-			service.EXPECT().Create(gomock.Any(), "test-image", gomock.Nil(), equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", gomock.Nil(), equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -66,6 +66,18 @@ var _ = Describe("Container Create API ", func() {
 			Expect(rr.Body).Should(MatchJSON(jsonResponse))
 		})
 
+		It("should return the warnings of the service", func() {
+			body := []byte(`{"Image": "test-image"}`)
+			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
+
+			service.EXPECT().Create(gomock.Any(), "test-image", gomock.Nil(), equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, []string{"test warning"}, nil)
+
+			h.create(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusCreated))
+			Expect(rr.Body).Should(MatchJSON(`{"Id": "123", "Warnings": ["test warning"]}`))
+		})
+
 		It("should set the Cmd argument", func() {
 			body := []byte(`{
 				"Image": "test-image",
@@ -73,8 +85,8 @@ var _ = Describe("Container Create API ", func() {
 			}`)
 			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 
-			service.EXPECT().Create(gomock.Any(), "test-image", []string{"echo", "hello world"}, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", []string{"echo", "hello world"}, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -125,8 +137,8 @@ var _ = Describe("Container Create API ", func() {
 				PortMappings:         []gocni.PortMapping{portMaps[1], portMaps[0]},
 			}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), anyOf(netOpt1, netOpt2)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), anyOf(netOpt1, netOpt2), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -160,8 +172,8 @@ var _ = Describe("Container Create API ", func() {
 			}`)
 			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -181,8 +193,8 @@ var _ = Describe("Container Create API ", func() {
 			// define expected network mode
 			netOpt.NetworkSlice = []string{"net1"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -198,8 +210,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.Name = "test-cont"
 			createOpt.Platform = "arm64"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -239,8 +251,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.StopTimeout = 500
 			createOpt.Memory = "209715200"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -262,8 +274,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.Memory = "209715200"
 			createOpt.CPUShares = 1
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -289,8 +301,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.LogDriver = "json-file"
 			createOpt.LogOpt = []string{"key=value"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -318,8 +330,8 @@ var _ = Describe("Container Create API ", func() {
 			netOpt.DNSSearchDomains = []string{"test.com"}
 			netOpt.AddHost = []string{"test-host:127.0.0.1"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -348,8 +360,8 @@ var _ = Describe("Container Create API ", func() {
 				"test-vol3",
 			}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -369,8 +381,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.CPUPeriod = 100000
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -389,8 +401,8 @@ var _ = Describe("Container Create API ", func() {
 
 			// expected create options
 			createOpt.CPUQuota = 50000
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -406,8 +418,8 @@ var _ = Describe("Container Create API ", func() {
 
 			// expected create options
 			createOpt.CPUQuota = -1
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -428,8 +440,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.CPUSetCPUs = "0,1"
 			createOpt.CPUSetMems = "0,3"
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -452,8 +464,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.MemoryReservation = "209710"
 			createOpt.MemorySwap = "514288000"
 			createOpt.MemorySwappiness64 = 25
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -473,8 +485,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.CapDrop = []string{"MKNOD"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -494,8 +506,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.GroupAdd = []string{"someGroup"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -515,8 +527,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.Privileged = true
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -536,8 +548,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.Ulimit = []string{"nofile=1024:2048", "nproc=1024:4048"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -557,8 +569,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.PidsLimit = 200
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -578,8 +590,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.CidFile = "/lib/example.txt"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -591,8 +603,8 @@ var _ = Describe("Container Create API ", func() {
 			body := []byte(`{"Image": "test-image"}`)
 			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				"", errdefs.NewNotFound(errors.New("error message")))
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				"", nil, errdefs.NewNotFound(errors.New("error message")))
 
 			// handler should return error message with 404 status code.
 			h.create(rr, req)
@@ -603,32 +615,58 @@ var _ = Describe("Container Create API ", func() {
 			body := []byte(`{"Image": "test-image"}`)
 			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				"", errdefs.NewInvalidFormat(errors.New("error message")))
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				"", nil, errdefs.NewInvalidFormat(errors.New("error message")))
 
 			// handler should return error message with 400 status code.
 			h.create(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
 		})
 
+		It("should create the container with the requested snapshotter", func() {
+			body := []byte(`{"Image": "test-image"}`)
+			req, _ := http.NewRequest(http.MethodPost, "/containers/create?snapshotter=soci", bytes.NewReader(body))
+			createOpt.GOptions.Snapshotter = "soci"
+			createOpt.ImagePullOpt.GOptions.Snapshotter = "soci"
+
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
+
+			// handler should return 201 status code.
+			h.create(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusCreated))
+		})
+
 		It("should return 409 if the container already exists", func() {
 			body := []byte(`{"Image": "test-image"}`)
 			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				"", errdefs.NewConflict(errors.New("error message")))
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				"", nil, errdefs.NewConflict(errors.New("error message")))
 
 			// handler should return error message with 409 status code.
 			h.create(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusConflict))
 		})
 
+		It("should return 403 if the image fails verification", func() {
+			body := []byte(`{"Image": "test-image"}`)
+			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
+
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				"", nil, errdefs.NewForbidden(errors.New("image verification failed")))
+
+			// handler should return error message with 403 status code.
+			h.create(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusForbidden))
+		})
+
 		It("should return 500 for internal failures", func() {
 			body := []byte(`{"Image": "test-image"}`)
 			req, _ := http.NewRequest(http.MethodPost, "/containers/create", bytes.NewReader(body))
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				"", errors.New("error message"))
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				"", nil, errors.New("error message"))
 
 			// handler should return error message with 500 status code.
 			h.create(rr, req)
@@ -665,8 +703,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected network options
 			netOpt.NetworkSlice = []string{"none"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -683,8 +721,8 @@ var _ = Describe("Container Create API ", func() {
 
 			// expected network options
 			netOpt.MACAddress = "12:34:56:78:9a:bc"
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -703,8 +741,8 @@ var _ = Describe("Container Create API ", func() {
 
 			// expected network options
 			createOpt.OomKillDisable = true
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -723,8 +761,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected network options
 			createOpt.BlkioWeight = 300
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -787,8 +825,8 @@ var _ = Describe("Container Create API ", func() {
 				"/dev/sda:2000",
 			}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -807,8 +845,8 @@ var _ = Describe("Container Create API ", func() {
 
 			createOpt.VolumesFrom = []string{"parent", "other:ro"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			h.create(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusCreated))
@@ -829,8 +867,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.Tmpfs = []string{"/run:rw,noexec,nosuid,size=65536k"}
 			netOpt.UTSNamespace = "host"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -850,8 +888,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.Pid = "host"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -871,8 +909,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.IPC = "host"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -896,8 +934,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.Sysctl = []string{"net.ipv4.ip_forward=1"}
 			createOpt.Runtime = "crun"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -919,8 +957,8 @@ var _ = Describe("Container Create API ", func() {
 			createOpt.ReadOnly = true
 			createOpt.SecurityOpt = []string{"seccomp=/path/to/custom_seccomp.json", "apparmor=unconfined"}
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -1000,8 +1038,8 @@ var _ = Describe("Container Create API ", func() {
 			// expected create options
 			createOpt.Cgroupns = "host"
 
-			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt)).Return(
-				cid, nil)
+			service.EXPECT().Create(gomock.Any(), "test-image", nil, equalTo(createOpt), equalTo(netOpt), gomock.Any()).Return(
+				cid, nil, nil)
 
 			// handler should return success message with 201 status code.
 			h.create(rr, req)
@@ -74,6 +74,7 @@ func (h *handler) logs(w http.ResponseWriter, r *http.Request) {
 		Until:      httputils.Int64ValueOrZero(r, "until"),
 		Timestamps: httputils.BoolValueOrDefault(r, "timestamps", false),
 		Tail:       r.Form.Get("tail"),
+		Details:    httputils.BoolValueOrDefault(r, "details", false),
 		MuxStreams: true,
 	}
 
@@ -116,6 +116,7 @@ var _ = Describe("Container Logs API", func() {
 				Until:      11,
 				Timestamps: true,
 				Tail:       "all",
+				Details:    true,
 				MuxStreams: true,
 			}
 			service.EXPECT().Logs(gomock.Any(), cid, logsOptsEqualTo(expectedOpts)).Return(nil)
@@ -126,7 +127,8 @@ var _ = Describe("Container Logs API", func() {
 				"since=10&"+
 				"until=11&"+
 				"timestamps=1&"+
-				"tail=all", nil)
+				"tail=all&"+
+				"details=1", nil)
 			req = mux.SetURLVars(req, vars)
 
 			h.logs(rr, req)
@@ -185,6 +187,9 @@ func (e *logsOptsMatcher) Matches(x interface{}) bool {
 	if e.obj.Tail != y.Tail {
 		e.mismatches = append(e.mismatches, "Tail")
 	}
+	if e.obj.Details != y.Details {
+		e.mismatches = append(e.mismatches, "Details")
+	}
 	if e.obj.MuxStreams != y.MuxStreams {
 		e.mismatches = append(e.mismatches, "MuxStreams")
 	}
@@ -44,12 +44,14 @@ func (h *handler) pause(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errdefs.IsNotFound(err):
 			code = http.StatusNotFound
+		case errdefs.IsNotModified(err):
+			code = http.StatusNotModified
 		case errdefs.IsConflict(err):
 			code = http.StatusConflict
 		default:
 			code = http.StatusInternalServerError
 		}
-		response.JSON(w, code, response.NewError(err))
+		response.SendErrorResponse(w, code, err)
 		return
 	}
 
@@ -81,6 +81,19 @@ var _ = Describe("Container Pause API", func() {
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 		})
 
+		It("should return 304 when service returns a not modified error", func() {
+			req, err := http.NewRequest(http.MethodPost, "/containers/id1/pause", nil)
+			Expect(err).Should(BeNil())
+			req = mux.SetURLVars(req, map[string]string{"id": "id1"})
+
+			service.EXPECT().Pause(gomock.Any(), "id1", gomock.Any()).Return(
+				errdefs.NewNotModified(fmt.Errorf("container already paused")))
+
+			h.pause(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusNotModified))
+			Expect(rr.Body.Len()).Should(BeZero())
+		})
+
 		It("should return 409 when service returns a conflict error", func() {
 			req, err := http.NewRequest(http.MethodPost, "/containers/id1/pause", nil)
 			Expect(err).Should(BeNil())
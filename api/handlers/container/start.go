@@ -54,6 +54,8 @@ func (h *handler) start(w http.ResponseWriter, r *http.Request) {
 			code = http.StatusNotFound
 		case errdefs.IsNotModified(err):
 			code = http.StatusNotModified
+		case errdefs.IsConflict(err):
+			code = http.StatusConflict
 		default:
 			code = http.StatusInternalServerError
 		}
@@ -76,6 +76,13 @@ var _ = Describe("Container Start API ", func() {
 			h.start(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotModified))
 		})
+		It("should return 409 conflict error when container is paused", func() {
+			service.EXPECT().Start(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				errdefs.NewConflict(fmt.Errorf("cannot start a paused container, try unpause instead")))
+
+			h.start(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusConflict))
+		})
 		It("should pass detachKeys to the service", func() {
 			// Set up the request with detachKeys query parameter
 			req, _ = http.NewRequest(http.MethodPost, "/containers/123/start?detachKeys=ctrl-p,ctrl-q", nil)
@@ -5,9 +5,7 @@ package exec
 
 import (
 	"context"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/containerd/nerdctl/v2/pkg/config"
 
@@ -19,7 +17,7 @@ import (
 type Service interface {
 	Start(ctx context.Context, options *types.ExecStartOptions) error
 	Resize(ctx context.Context, options *types.ExecResizeOptions) error
-	Inspect(ctx context.Context, conId string, execId string) (*types.ExecInspect, error)
+	Inspect(ctx context.Context, execId string) (*types.ExecInspect, error)
 }
 
 // RegisterHandlers registers all the supported endpoints related to exec APIs.
@@ -27,9 +25,9 @@ func RegisterHandlers(r types.VersionedRouter, service Service, conf *config.Con
 	h := newHandler(service, conf, logger)
 
 	r.SetPrefix("/exec")
-	r.HandleFunc("/{id:.*}/start", h.start, http.MethodPost)
-	r.HandleFunc("/{id:.*}/resize", h.resize, http.MethodPost)
-	r.HandleFunc("/{id:.*}/json", h.inspect, http.MethodGet)
+	r.HandleFunc("/{id}/start", h.start, http.MethodPost)
+	r.HandleFunc("/{id}/resize", h.resize, http.MethodPost)
+	r.HandleFunc("/{id}/json", h.inspect, http.MethodGet)
 }
 
 // newHandler creates the handler that serves all exec APIs.
@@ -46,13 +44,3 @@ type handler struct {
 	config  *config.Config
 	logger  flog.Logger
 }
-
-// parseExecId breaks down execId into a container ID and process ID.
-func parseExecId(execId string) (string, string, error) {
-	splitId := strings.Split(execId, "/")
-	if len(splitId) != 2 {
-		return "", "", fmt.Errorf("invalid exec id: %s", execId)
-	}
-
-	return splitId[0], splitId[1], nil
-}
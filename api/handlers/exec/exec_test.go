@@ -49,8 +49,8 @@ var _ = Describe("Exec API", func() {
 	})
 	Context("handlers", func() {
 		It("should call exec start method", func() {
-			req, _ = http.NewRequest(http.MethodPost, "/exec/123/exec-123/start", bytes.NewReader([]byte(`{"detach": true}`)))
-			service.EXPECT().Inspect(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+			req, _ = http.NewRequest(http.MethodPost, "/exec/exec-123/start", bytes.NewReader([]byte(`{"detach": true}`)))
+			service.EXPECT().Inspect(gomock.Any(), gomock.Any()).Return(nil, nil)
 			service.EXPECT().Start(gomock.Any(), gomock.Any()).Return(errors.New("start error"))
 
 			router.ServeHTTP(rr, req)
@@ -58,7 +58,7 @@ var _ = Describe("Exec API", func() {
 			Expect(rr.Body).Should(MatchJSON(`{"message": "start error"}`))
 		})
 		It("should call exec resize method", func() {
-			req, _ = http.NewRequest(http.MethodPost, "/exec/123/exec-123/resize?h=123&w=123", nil)
+			req, _ = http.NewRequest(http.MethodPost, "/exec/exec-123/resize?h=123&w=123", nil)
 			service.EXPECT().Resize(gomock.Any(), gomock.Any()).Return(errors.New("resize error"))
 
 			router.ServeHTTP(rr, req)
@@ -66,8 +66,8 @@ var _ = Describe("Exec API", func() {
 			Expect(rr.Body).Should(MatchJSON(`{"message": "resize error"}`))
 		})
 		It("should call exec inspect method", func() {
-			service.EXPECT().Inspect(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("inspect error"))
-			req, _ = http.NewRequest(http.MethodGet, "/exec/123/exec-123/json", nil)
+			service.EXPECT().Inspect(gomock.Any(), gomock.Any()).Return(nil, errors.New("inspect error"))
+			req, _ = http.NewRequest(http.MethodGet, "/exec/exec-123/json", nil)
 
 			router.ServeHTTP(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
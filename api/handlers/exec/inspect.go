@@ -16,13 +16,7 @@ import (
 func (h *handler) inspect(w http.ResponseWriter, r *http.Request) {
 	execId := mux.Vars(r)["id"]
 	ctx := namespaces.WithNamespace(r.Context(), h.config.Namespace)
-	conId, procId, err := parseExecId(execId)
-	if err != nil {
-		response.JSON(w, http.StatusBadRequest, response.NewError(err))
-		return
-	}
-
-	inspect, err := h.service.Inspect(ctx, conId, procId)
+	inspect, err := h.service.Inspect(ctx, execId)
 	if err != nil {
 		var code int
 		switch {
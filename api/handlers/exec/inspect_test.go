@@ -40,9 +40,9 @@ var _ = Describe("Exec Inspect API", func() {
 		h = newHandler(service, &conf, logger)
 		rr = httptest.NewRecorder()
 		var err error
-		req, err = http.NewRequest(http.MethodGet, "/exec/123/exec-123/inspect", nil)
+		req, err = http.NewRequest(http.MethodGet, "/exec/exec-123/inspect", nil)
 		Expect(err).Should(BeNil())
-		req = mux.SetURLVars(req, map[string]string{"id": "123/exec-123"})
+		req = mux.SetURLVars(req, map[string]string{"id": "exec-123"})
 		execInspect = &types.ExecInspect{
 			ID:       "exec-123",
 			Running:  true,
@@ -67,21 +67,21 @@ var _ = Describe("Exec Inspect API", func() {
 	})
 	Context("handler", func() {
 		It("should return 200 on successful inspect", func() {
-			service.EXPECT().Inspect(gomock.Any(), "123", "exec-123").Return(execInspect, nil)
+			service.EXPECT().Inspect(gomock.Any(), "exec-123").Return(execInspect, nil)
 
 			h.inspect(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
 			Expect(rr.Body).Should(MatchJSON(inspectStr))
 		})
 		It("should return 404 if the exec instance is not found", func() {
-			service.EXPECT().Inspect(gomock.Any(), "123", "exec-123").Return(nil, errdefs.NewNotFound(fmt.Errorf("inspect error")))
+			service.EXPECT().Inspect(gomock.Any(), "exec-123").Return(nil, errdefs.NewNotFound(fmt.Errorf("inspect error")))
 
 			h.inspect(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 			Expect(rr.Body).Should(MatchJSON(`{"message": "inspect error"}`))
 		})
 		It("should return 500 on any other error", func() {
-			service.EXPECT().Inspect(gomock.Any(), "123", "exec-123").Return(nil, fmt.Errorf("inspect error"))
+			service.EXPECT().Inspect(gomock.Any(), "exec-123").Return(nil, fmt.Errorf("inspect error"))
 
 			h.inspect(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
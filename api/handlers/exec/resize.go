@@ -30,15 +30,8 @@ func (h *handler) resize(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cid, procId, err := parseExecId(execId)
-	if err != nil {
-		response.JSON(w, http.StatusBadRequest, response.NewError(err))
-		return
-	}
-
 	resizeOptions := &types.ExecResizeOptions{
-		ConID:  cid,
-		ExecID: procId,
+		ExecID: execId,
 		Height: height,
 		Width:  width,
 	}
@@ -47,6 +40,8 @@ func (h *handler) resize(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errdefs.IsNotFound(err):
 			code = http.StatusNotFound
+		case errdefs.IsConflict(err):
+			code = http.StatusConflict
 		default:
 			code = http.StatusInternalServerError
 		}
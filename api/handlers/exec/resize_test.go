@@ -37,14 +37,13 @@ var _ = Describe("Exec Resize API", func() {
 		h = newHandler(service, &conf, logger)
 		rr = httptest.NewRecorder()
 		var err error
-		req, err = http.NewRequest(http.MethodPost, "/exec/123/exec-123/resize?h=123&w=123", nil)
+		req, err = http.NewRequest(http.MethodPost, "/exec/exec-123/resize?h=123&w=123", nil)
 		Expect(err).Should(BeNil())
-		req = mux.SetURLVars(req, map[string]string{"id": "123/exec-123"})
+		req = mux.SetURLVars(req, map[string]string{"id": "exec-123"})
 	})
 	Context("handler", func() {
 		It("should return 200 on successful resize", func() {
 			service.EXPECT().Resize(gomock.Any(), &types.ExecResizeOptions{
-				ConID:  "123",
 				ExecID: "exec-123",
 				Height: 123,
 				Width:  123,
@@ -55,7 +54,6 @@ var _ = Describe("Exec Resize API", func() {
 		})
 		It("should return 404 if the exec instance is not found", func() {
 			service.EXPECT().Resize(gomock.Any(), &types.ExecResizeOptions{
-				ConID:  "123",
 				ExecID: "exec-123",
 				Height: 123,
 				Width:  123,
@@ -65,9 +63,19 @@ var _ = Describe("Exec Resize API", func() {
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 			Expect(rr.Body).Should(MatchJSON(`{"message": "not found"}`))
 		})
+		It("should return 409 if the container is not running", func() {
+			service.EXPECT().Resize(gomock.Any(), &types.ExecResizeOptions{
+				ExecID: "exec-123",
+				Height: 123,
+				Width:  123,
+			}).Return(errdefs.NewConflict(errors.New("container 123 is not running")))
+
+			h.resize(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusConflict))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "container 123 is not running"}`))
+		})
 		It("should return 500 on any other error", func() {
 			service.EXPECT().Resize(gomock.Any(), &types.ExecResizeOptions{
-				ConID:  "123",
 				ExecID: "exec-123",
 				Height: 123,
 				Width:  123,
@@ -78,27 +86,27 @@ var _ = Describe("Exec Resize API", func() {
 			Expect(rr.Body).Should(MatchJSON(`{"message": "inspect error"}`))
 		})
 		It("should return 400 if h is not specified", func() {
-			badReq, err := http.NewRequest(http.MethodPost, "/exec/123/exec-123/resize?w=123", nil)
+			badReq, err := http.NewRequest(http.MethodPost, "/exec/exec-123/resize?w=123", nil)
 			Expect(err).Should(BeNil())
-			badReq = mux.SetURLVars(badReq, map[string]string{"id": "123/exec-123"})
+			badReq = mux.SetURLVars(badReq, map[string]string{"id": "exec-123"})
 
 			h.resize(rr, badReq)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
 			Expect(rr.Body).Should(MatchJSON(`{"message": "query parameter h required"}`))
 		})
 		It("should return 400 if w is not specified", func() {
-			badReq, err := http.NewRequest(http.MethodPost, "/exec/123/exec-123/resize?h=123", nil)
+			badReq, err := http.NewRequest(http.MethodPost, "/exec/exec-123/resize?h=123", nil)
 			Expect(err).Should(BeNil())
-			badReq = mux.SetURLVars(badReq, map[string]string{"id": "123/exec-123"})
+			badReq = mux.SetURLVars(badReq, map[string]string{"id": "exec-123"})
 
 			h.resize(rr, badReq)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
 			Expect(rr.Body).Should(MatchJSON(`{"message": "query parameter w required"}`))
 		})
 		It("should return 400 if a query param is not an int", func() {
-			badReq, err := http.NewRequest(http.MethodPost, "/exec/123/exec-123/resize?h=foo&w=123", nil)
+			badReq, err := http.NewRequest(http.MethodPost, "/exec/exec-123/resize?h=foo&w=123", nil)
 			Expect(err).Should(BeNil())
-			badReq = mux.SetURLVars(badReq, map[string]string{"id": "123/exec-123"})
+			badReq = mux.SetURLVars(badReq, map[string]string{"id": "exec-123"})
 
 			h.resize(rr, badReq)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
@@ -117,7 +125,7 @@ var _ = Describe("Exec Resize API", func() {
 			Expect(err.Error()).Should(Equal("query parameter none required"))
 		})
 		It("should return error if the query param is not an integer", func() {
-			badReq, err := http.NewRequest(http.MethodPost, "/exec/123/exec-123/resize?foo=bar", nil)
+			badReq, err := http.NewRequest(http.MethodPost, "/exec/exec-123/resize?foo=bar", nil)
 			Expect(err).Should(BeNil())
 
 			_, err = getQueryParamInt(badReq, "foo")
@@ -31,12 +31,6 @@ func (h *handler) start(w http.ResponseWriter, r *http.Request) {
 	)
 	ctx := namespaces.WithNamespace(r.Context(), h.config.Namespace)
 
-	cid, procId, err := parseExecId(execId)
-	if err != nil {
-		response.JSON(w, http.StatusBadRequest, response.NewError(err))
-		return
-	}
-
 	if r.Body == nil {
 		response.JSON(w, http.StatusBadRequest, response.NewErrorFromMsg("body should not be empty"))
 		return
@@ -91,8 +85,7 @@ func (h *handler) start(w http.ResponseWriter, r *http.Request) {
 
 	startOptions := &types.ExecStartOptions{
 		ExecStartCheck:  execStartCheck,
-		ConID:           cid,
-		ExecID:          procId,
+		ExecID:          execId,
 		Stdin:           stdin,
 		Stdout:          stdout,
 		Stderr:          stderr,
@@ -50,9 +50,9 @@ var _ = Describe("Exec Start API", func() {
 		Context("bad request", func() {
 			It("should return 400 if the request body is empty", func() {
 				var err error
-				req, err = http.NewRequest(http.MethodPost, "/exec/123/exec-123/start", nil)
+				req, err = http.NewRequest(http.MethodPost, "/exec/exec-123/start", nil)
 				Expect(err).Should(BeNil())
-				req = mux.SetURLVars(req, map[string]string{"id": "123/exec-123"})
+				req = mux.SetURLVars(req, map[string]string{"id": "exec-123"})
 
 				h.start(rr, req)
 				Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
@@ -60,9 +60,9 @@ var _ = Describe("Exec Start API", func() {
 			})
 			It("should return 400 if the body reader returns an error", func() {
 				var err error
-				req, err = http.NewRequest(http.MethodPost, "/exec/123/exec-123/start", NewErrorReader())
+				req, err = http.NewRequest(http.MethodPost, "/exec/exec-123/start", NewErrorReader())
 				Expect(err).Should(BeNil())
-				req = mux.SetURLVars(req, map[string]string{"id": "123/exec-123"})
+				req = mux.SetURLVars(req, map[string]string{"id": "exec-123"})
 
 				h.start(rr, req)
 				Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
@@ -70,9 +70,9 @@ var _ = Describe("Exec Start API", func() {
 			})
 			It("should return 400 if the request body is not an ExecStartCheck", func() {
 				var err error
-				req, err = http.NewRequest(http.MethodPost, "/exec/123/exec-123/start", bytes.NewReader([]byte("foo")))
+				req, err = http.NewRequest(http.MethodPost, "/exec/exec-123/start", bytes.NewReader([]byte("foo")))
 				Expect(err).Should(BeNil())
-				req = mux.SetURLVars(req, map[string]string{"id": "123/exec-123"})
+				req = mux.SetURLVars(req, map[string]string{"id": "exec-123"})
 
 				h.start(rr, req)
 				Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
@@ -88,12 +88,11 @@ var _ = Describe("Exec Start API", func() {
 				}
 				reqBody, err := json.Marshal(opts)
 				Expect(err).Should(BeNil())
-				req, err = http.NewRequest(http.MethodPost, "/exec/123/exec-123/start", bytes.NewReader(reqBody))
+				req, err = http.NewRequest(http.MethodPost, "/exec/exec-123/start", bytes.NewReader(reqBody))
 				Expect(err).Should(BeNil())
-				req = mux.SetURLVars(req, map[string]string{"id": "123/exec-123"})
+				req = mux.SetURLVars(req, map[string]string{"id": "exec-123"})
 				startOpts = &types.ExecStartOptions{
 					ExecStartCheck: opts,
-					ConID:          "123",
 					ExecID:         "exec-123",
 					Stdin:          nil,
 					Stdout:         nil,
@@ -113,6 +112,13 @@ var _ = Describe("Exec Start API", func() {
 				Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 				Expect(rr.Body).Should(MatchJSON(`{"message": "not found"}`))
 			})
+			It("should return 409 if the exec instance has already run", func() {
+				service.EXPECT().Start(gomock.Any(), startOpts).Return(errdefs.NewConflict(fmt.Errorf("exec instance exec-123 has already run")))
+
+				h.start(rr, req)
+				Expect(rr).Should(HaveHTTPStatus(http.StatusConflict))
+				Expect(rr.Body).Should(MatchJSON(`{"message": "exec instance exec-123 has already run"}`))
+			})
 			It("should return 500 if it fails to start", func() {
 				service.EXPECT().Start(gomock.Any(), startOpts).Return(fmt.Errorf("failed to start"))
 
@@ -132,9 +138,9 @@ var _ = Describe("Exec Start API", func() {
 				}
 				reqBody, err := json.Marshal(opts)
 				Expect(err).Should(BeNil())
-				req, err = http.NewRequest(http.MethodPost, "/exec/123/exec-123/start", bytes.NewReader(reqBody))
+				req, err = http.NewRequest(http.MethodPost, "/exec/exec-123/start", bytes.NewReader(reqBody))
 				Expect(err).Should(BeNil())
-				req = mux.SetURLVars(req, map[string]string{"id": "123/exec-123"})
+				req = mux.SetURLVars(req, map[string]string{"id": "exec-123"})
 				rr = hj.NewRecorder(nil)
 			})
 			It("should return 500 if Hijacking the connection fails", func() {
@@ -148,21 +154,21 @@ var _ = Describe("Exec Start API", func() {
 				Expect(rr.Body().Bytes()).Should(MatchJSON(fmt.Sprintf(`{"message": "%s"}`, hijackErrMsg)))
 			})
 			It("should return 404 if the exec instance is not found", func() {
-				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdsAndCheck("123", "exec-123", opts)).Return(errdefs.NewNotFound(fmt.Errorf("not found")))
+				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdAndCheck("exec-123", opts)).Return(errdefs.NewNotFound(fmt.Errorf("not found")))
 
 				h.start(rr, req)
 				rrBody := (*(rr.Body())).String()
 				Expect(rrBody).Should(Equal("HTTP/1.1 404 Not Found\r\nContent-Type: application/json\r\n\r\n{\"message\":\"not found\"}\r\n"))
 			})
 			It("should return 409 if the container is not running", func() {
-				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdsAndCheck("123", "exec-123", opts)).Return(errdefs.NewConflict(fmt.Errorf("not running")))
+				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdAndCheck("exec-123", opts)).Return(errdefs.NewConflict(fmt.Errorf("not running")))
 
 				h.start(rr, req)
 				rrBody := (*(rr.Body())).String()
 				Expect(rrBody).Should(Equal("HTTP/1.1 409 Conflict\r\nContent-Type: application/json\r\n\r\n{\"message\":\"not running\"}\r\n"))
 			})
 			It("should return 500 on other errors", func() {
-				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdsAndCheck("123", "exec-123", opts)).Return(fmt.Errorf("start error"))
+				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdAndCheck("exec-123", opts)).Return(fmt.Errorf("start error"))
 
 				h.start(rr, req)
 				rrBody := (*(rr.Body())).String()
@@ -170,7 +176,7 @@ var _ = Describe("Exec Start API", func() {
 			})
 			It("should correctly upgrade if requested", func() {
 				req.Header.Set("Upgrade", "foo")
-				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdsAndCheck("123", "exec-123", opts)).DoAndReturn(
+				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdAndCheck("exec-123", opts)).DoAndReturn(
 					func(ctx context.Context, execStartOptions *types.ExecStartOptions) error {
 						execStartOptions.SuccessResponse()
 						return nil
@@ -183,7 +189,7 @@ var _ = Describe("Exec Start API", func() {
 					"application/vnd.docker.raw-stream")))
 			})
 			It("should stream output from the started process", func() {
-				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdsAndCheck("123", "exec-123", opts)).DoAndReturn(
+				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdAndCheck("exec-123", opts)).DoAndReturn(
 					func(ctx context.Context, execStartOptions *types.ExecStartOptions) error {
 						execStartOptions.SuccessResponse()
 						rrBody := (*(rr.Body())).String()
@@ -203,7 +209,7 @@ var _ = Describe("Exec Start API", func() {
 				h.start(rr, req)
 			})
 			It("should print any errors from Start to the connection if the success response has been returned", func() {
-				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdsAndCheck("123", "exec-123", opts)).DoAndReturn(
+				service.EXPECT().Start(gomock.Any(), execStartOptionsWithIdAndCheck("exec-123", opts)).DoAndReturn(
 					func(ctx context.Context, execStartOptions *types.ExecStartOptions) error {
 						execStartOptions.SuccessResponse()
 						return fmt.Errorf("start error")
@@ -256,16 +262,14 @@ func (h *responseRecorderWithMockHijack) Hijack() (net.Conn, *bufio.ReadWriter,
 	return h.mockHijack()
 }
 
-func execStartOptionsWithIdsAndCheck(conId string, execId string, check *types.ExecStartCheck) gomock.Matcher {
+func execStartOptionsWithIdAndCheck(execId string, check *types.ExecStartCheck) gomock.Matcher {
 	return &execStartOptionsMatcher{
-		conId:  conId,
 		execId: execId,
 		check:  check,
 	}
 }
 
 type execStartOptionsMatcher struct {
-	conId  string
 	execId string
 	check  *types.ExecStartCheck
 }
@@ -275,9 +279,6 @@ func (m *execStartOptionsMatcher) Matches(x interface{}) bool {
 	if !ok {
 		return false
 	}
-	if m.conId != matchOpts.ConID {
-		return false
-	}
 	if m.execId != matchOpts.ExecID {
 		return false
 	}
@@ -297,5 +298,5 @@ func (m *execStartOptionsMatcher) Matches(x interface{}) bool {
 }
 
 func (m *execStartOptionsMatcher) String() string {
-	return fmt.Sprintf("*types.ExecStartOptions with ConId: %s, ExecId: %s, ExecStartCheck: %v", m.conId, m.execId, m.check)
+	return fmt.Sprintf("*types.ExecStartOptions with ExecId: %s, ExecStartCheck: %v", m.execId, m.check)
 }
@@ -5,6 +5,7 @@ package image
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/containerd/containerd/v2/pkg/namespaces"
@@ -16,7 +17,20 @@ import (
 )
 
 func (h *handler) export(w http.ResponseWriter, r *http.Request) {
-	name := mux.Vars(r)["name"]
+	h.exportImages(w, r, []string{mux.Vars(r)["name"]})
+}
+
+// exportMultiple exports the images of the names query parameter in one tarball.
+func (h *handler) exportMultiple(w http.ResponseWriter, r *http.Request) {
+	names := r.URL.Query()["names"]
+	if len(names) == 0 {
+		response.SendErrorResponse(w, http.StatusBadRequest, fmt.Errorf("names must be specified"))
+		return
+	}
+	h.exportImages(w, r, names)
+}
+
+func (h *handler) exportImages(w http.ResponseWriter, r *http.Request, names []string) {
 	ctx := namespaces.WithNamespace(r.Context(), h.Config.Namespace)
 
 	var platform *ocispec.Platform
@@ -29,7 +43,7 @@ func (h *handler) export(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/x-tar")
-	err := h.service.Export(ctx, name, platform, w)
+	err := h.service.Export(ctx, names, platform, w)
 	if err != nil {
 		var code int
 		switch {
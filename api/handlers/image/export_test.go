@@ -13,6 +13,7 @@ import (
 	"github.com/gorilla/mux"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.uber.org/mock/gomock"
 
 	"github.com/runfinch/finch-daemon/mocks/mocks_image"
@@ -48,7 +49,7 @@ var _ = Describe("Image Export API", func() {
 		It("should return 200 status code upon success", func() {
 			service.EXPECT().Export(
 				gomock.Any(),
-				name,
+				[]string{name},
 				gomock.Any(),
 				gomock.Any(),
 			).Return(nil)
@@ -58,14 +59,14 @@ var _ = Describe("Image Export API", func() {
 			Expect(rr.Header().Get("Content-Type")).Should(Equal("application/x-tar"))
 		})
 		It("should return 404 status code if image not found", func() {
-			service.EXPECT().Export(gomock.Any(), name, gomock.Any(), gomock.Any()).Return(errdefs.NewNotFound(fmt.Errorf("no such image")))
+			service.EXPECT().Export(gomock.Any(), []string{name}, gomock.Any(), gomock.Any()).Return(errdefs.NewNotFound(fmt.Errorf("no such image")))
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any())
 
 			h.export(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 		})
 		It("should return 500 status code if service returns an error", func() {
-			service.EXPECT().Export(gomock.Any(), name, gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+			service.EXPECT().Export(gomock.Any(), []string{name}, gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any())
 
 			h.export(rr, req)
@@ -80,10 +81,39 @@ var _ = Describe("Image Export API", func() {
 		It("should parse valid platform JSON and pass to service", func() {
 			platformJSON := `{"os":"linux","architecture":"amd64"}`
 			req.URL.RawQuery = url.Values{"platform": {platformJSON}}.Encode()
-			service.EXPECT().Export(gomock.Any(), name, gomock.Any(), gomock.Any()).Return(nil)
+			service.EXPECT().Export(gomock.Any(), []string{name}, gomock.Any(), gomock.Any()).Return(nil)
 
 			h.export(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
 		})
+		It("should export all the images of the names query parameter", func() {
+			req, err := http.NewRequest(http.MethodGet, "/images/get?names=alpine&names=busybox:latest&platform="+
+				url.QueryEscape(`{"os":"linux","architecture":"arm64"}`), nil)
+			Expect(err).Should(BeNil())
+			service.EXPECT().Export(gomock.Any(), []string{"alpine", "busybox:latest"},
+				&ocispec.Platform{OS: "linux", Architecture: "arm64"}, gomock.Any()).Return(nil)
+
+			h.exportMultiple(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Header().Get("Content-Type")).Should(Equal("application/x-tar"))
+		})
+		It("should return 404 status code if one of the images is not found", func() {
+			req, err := http.NewRequest(http.MethodGet, "/images/get?names=alpine&names=missing", nil)
+			Expect(err).Should(BeNil())
+			service.EXPECT().Export(gomock.Any(), []string{"alpine", "missing"}, gomock.Any(), gomock.Any()).
+				Return(errdefs.NewNotFound(fmt.Errorf("no such image: missing")))
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any())
+
+			h.exportMultiple(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "no such image: missing"}`))
+		})
+		It("should return 400 status code if no names are specified", func() {
+			req, err := http.NewRequest(http.MethodGet, "/images/get", nil)
+			Expect(err).Should(BeNil())
+
+			h.exportMultiple(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
+		})
 	})
 })
@@ -0,0 +1,77 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+	"go.uber.org/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+var _ = Describe("Image History API", func() {
+	var (
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		service  *mocks_image.MockService
+		h        *handler
+		rr       *httptest.ResponseRecorder
+		name     string
+		req      *http.Request
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		logger = mocks_logger.NewLogger(mockCtrl)
+		service = mocks_image.NewMockService(mockCtrl)
+		c := config.Config{}
+		h = newHandler(service, &c, logger)
+		rr = httptest.NewRecorder()
+		name = "test-image"
+		var err error
+		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/images/%s/history", name), nil)
+		Expect(err).Should(BeNil())
+		req = mux.SetURLVars(req, map[string]string{"name": name})
+		logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+	})
+	Context("handler", func() {
+		It("should return 200 status code with the history of the image", func() {
+			service.EXPECT().History(gomock.Any(), name).Return([]types.ImageHistoryItem{
+				{ID: "sha256:123", Created: 1700000000, CreatedBy: "CMD [\"sh\"]", Tags: []string{"test-image:latest"}},
+				{ID: "<missing>", Created: 1700000000, CreatedBy: "ADD rootfs.tar /", Size: 100},
+			}, nil)
+
+			h.history(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Body).Should(MatchJSON(`[
+				{"Id": "sha256:123", "Created": 1700000000, "CreatedBy": "CMD [\"sh\"]", "Tags": ["test-image:latest"], "Size": 0, "Comment": ""},
+				{"Id": "<missing>", "Created": 1700000000, "CreatedBy": "ADD rootfs.tar /", "Tags": null, "Size": 100, "Comment": ""}
+			]`))
+		})
+		It("should return 404 status code if image was not found", func() {
+			service.EXPECT().History(gomock.Any(), name).Return(nil, errdefs.NewNotFound(fmt.Errorf("no such image")))
+
+			h.history(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "no such image"}`))
+		})
+		It("should return 500 status code if service returns an error message", func() {
+			service.EXPECT().History(gomock.Any(), name).Return(nil, fmt.Errorf("error"))
+
+			h.history(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "error"}`))
+		})
+	})
+})
@@ -9,7 +9,6 @@ import (
 	"net/http"
 
 	"github.com/containerd/nerdctl/v2/pkg/config"
-	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	dockertypes "github.com/docker/cli/cli/config/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
@@ -19,14 +18,16 @@ import (
 
 //go:generate mockgen --destination=../../../mocks/mocks_image/imagesvc.go -package=mocks_image github.com/runfinch/finch-daemon/api/handlers/image Service
 type Service interface {
-	List(ctx context.Context) ([]types.ImageSummary, error)
-	Pull(ctx context.Context, name, tag, platform string, authCfg *dockertypes.AuthConfig, outStream io.Writer) error
-	Push(ctx context.Context, name, tag string, authCfg *dockertypes.AuthConfig, outStream io.Writer) (*types.PushResult, error)
-	Remove(ctx context.Context, name string, force bool) (deleted, untagged []string, err error)
+	List(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	Pull(ctx context.Context, name, tag, platform, snapshotter string, authCfg *dockertypes.AuthConfig, outStream io.Writer) error
+	Import(ctx context.Context, options types.ImageImportOptions, outStream io.Writer) error
+	Push(ctx context.Context, name, tag string, recipients []string, authCfg *dockertypes.AuthConfig, outStream io.Writer) error
+	Remove(ctx context.Context, name string, force, noprune bool) (untagged, deleted []string, err error)
 	Tag(ctx context.Context, srcImg string, repo, tag string) error
-	Inspect(ctx context.Context, name string) (*dockercompat.Image, error)
+	Inspect(ctx context.Context, name string, platform *ocispec.Platform) (*types.ImageInspect, error)
+	History(ctx context.Context, name string) ([]types.ImageHistoryItem, error)
 	Load(ctx context.Context, inStream io.Reader, outStream io.Writer, quiet bool) error
-	Export(ctx context.Context, name string, platform *ocispec.Platform, outStream io.Writer) error
+	Export(ctx context.Context, names []string, platform *ocispec.Platform, outStream io.Writer) error
 }
 
 func RegisterHandlers(r types.VersionedRouter, service Service, conf *config.Config, logger flog.Logger) {
@@ -35,12 +36,14 @@ func RegisterHandlers(r types.VersionedRouter, service Service, conf *config.Con
 	r.SetPrefix("/images")
 	r.HandleFunc("/create", h.pull, http.MethodPost)
 	r.HandleFunc("/load", h.load, http.MethodPost)
+	r.HandleFunc("/get", h.exportMultiple, http.MethodGet)
 	r.HandleFunc("/json", h.list, http.MethodGet)
 	r.HandleFunc("/{name:.*}", h.remove, http.MethodDelete)
 	r.HandleFunc("/{name:.*}/get", h.export, http.MethodGet)
 	r.HandleFunc("/{name:.*}/push", h.push, http.MethodPost)
 	r.HandleFunc("/{name:.*}/tag", h.tag, http.MethodPost)
 	r.HandleFunc("/{name:.*}/json", h.inspect, http.MethodGet)
+	r.HandleFunc("/{name:.*}/history", h.history, http.MethodGet)
 }
 
 func newHandler(service Service, conf *config.Config, logger flog.Logger) *handler {
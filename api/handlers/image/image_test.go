@@ -50,7 +50,7 @@ var _ = Describe("Image API ", func() {
 	Context("handler", func() {
 		It("should call image inspect method", func() {
 			// setup mocks
-			service.EXPECT().Inspect(gomock.Any(), "test-image").Return(nil, errors.New("error from inspect api"))
+			service.EXPECT().Inspect(gomock.Any(), "test-image", nil).Return(nil, errors.New("error from inspect api"))
 			req, _ = http.NewRequest(http.MethodGet, "/images/test-image/json", nil)
 			// call the API to check if it returns the error generated from the inspect method
 			router.ServeHTTP(rr, req)
@@ -64,6 +64,7 @@ var _ = Describe("Image API ", func() {
 				"test-image",
 				"test-tag",
 				"test-platform",
+				"",
 				gomock.Any(),
 				gomock.Any(),
 			).Return(errors.New("error from pull api"))
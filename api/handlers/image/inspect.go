@@ -4,10 +4,12 @@
 package image
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/gorilla/mux"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/runfinch/finch-daemon/api/response"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
@@ -16,7 +18,17 @@ import (
 func (h *handler) inspect(w http.ResponseWriter, r *http.Request) {
 	name := mux.Vars(r)["name"]
 	ctx := namespaces.WithNamespace(r.Context(), h.Config.Namespace)
-	image, err := h.service.Inspect(ctx, name)
+
+	var platform *ocispec.Platform
+	if platformJSON := r.URL.Query().Get("platform"); platformJSON != "" {
+		platform = &ocispec.Platform{}
+		if err := json.Unmarshal([]byte(platformJSON), platform); err != nil {
+			response.SendErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	image, err := h.service.Inspect(ctx, name, platform)
 	// map the error into http status code and send response.
 	if err != nil {
 		var code int
@@ -10,12 +10,13 @@ import (
 	"net/http/httptest"
 
 	"github.com/containerd/nerdctl/v2/pkg/config"
-	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"go.uber.org/mock/gomock"
 	"github.com/gorilla/mux"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
+	"github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/mocks/mocks_image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
@@ -30,7 +31,7 @@ var _ = Describe("Image Inspect API", func() {
 		rr       *httptest.ResponseRecorder
 		name     string
 		req      *http.Request
-		resp     dockercompat.Image
+		resp     types.ImageInspect
 		respJSON []byte
 	)
 	BeforeEach(func() {
@@ -46,26 +47,48 @@ var _ = Describe("Image Inspect API", func() {
 		req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/images/%s/json", name), nil)
 		Expect(err).Should(BeNil())
 		req = mux.SetURLVars(req, map[string]string{"name": name})
-		resp = dockercompat.Image{
+		resp = types.ImageInspect{
 			ID:          name,
 			RepoTags:    []string{"test-image:latest"},
 			RepoDigests: []string{"test-image@test-digest"},
 			Size:        100,
+			GraphDriver: types.GraphDriverData{Name: "overlayfs"},
 		}
 		respJSON, err = json.Marshal(resp)
 		Expect(err).Should(BeNil())
 	})
 	Context("handler", func() {
 		It("should return inspect object and 200 status code upon success", func() {
-			service.EXPECT().Inspect(gomock.Any(), name).Return(&resp, nil)
+			service.EXPECT().Inspect(gomock.Any(), name, nil).Return(&resp, nil)
 
 			// handler should return response object with 200 status code
 			h.inspect(rr, req)
 			Expect(rr.Body).Should(MatchJSON(respJSON))
 			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
 		})
+		It("should inspect the image for the platform of the query", func() {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(`/images/%s/json?platform={"os":"linux","architecture":"arm64"}`, name), nil)
+			Expect(err).Should(BeNil())
+			req = mux.SetURLVars(req, map[string]string{"name": name})
+			platform := &ocispec.Platform{OS: "linux", Architecture: "arm64"}
+			service.EXPECT().Inspect(gomock.Any(), name, platform).Return(&resp, nil)
+
+			// handler should return response object with 200 status code
+			h.inspect(rr, req)
+			Expect(rr.Body).Should(MatchJSON(respJSON))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+		})
+		It("should return 400 status code if the platform is malformed", func() {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/images/%s/json?platform=linux", name), nil)
+			Expect(err).Should(BeNil())
+			req = mux.SetURLVars(req, map[string]string{"name": name})
+
+			// handler should return 400 status code without calling the service
+			h.inspect(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
+		})
 		It("should return 404 status code if image was not found", func() {
-			service.EXPECT().Inspect(gomock.Any(), name).Return(nil, errdefs.NewNotFound(fmt.Errorf("no such image")))
+			service.EXPECT().Inspect(gomock.Any(), name, nil).Return(nil, errdefs.NewNotFound(fmt.Errorf("no such image")))
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
 
 			// handler should return error message with 404 status code
@@ -74,7 +97,7 @@ var _ = Describe("Image Inspect API", func() {
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 		})
 		It("should return 500 status code if service returns an error message", func() {
-			service.EXPECT().Inspect(gomock.Any(), name).Return(nil, fmt.Errorf("error"))
+			service.EXPECT().Inspect(gomock.Any(), name, nil).Return(nil, fmt.Errorf("error"))
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
 
 			// handler should return error message
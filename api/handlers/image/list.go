@@ -4,16 +4,72 @@
 package image
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/containerd/containerd/v2/pkg/namespaces"
 
 	"github.com/runfinch/finch-daemon/api/response"
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
 func (h *handler) list(w http.ResponseWriter, r *http.Request) {
-	resp, err := h.service.List(r.Context())
+	ctx := namespaces.WithNamespace(r.Context(), h.Config.Namespace)
+	q := r.URL.Query()
+	all, err := parseBoolQP(q, "all")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.NewErrorFromMsg(fmt.Sprintf("invalid query parameter \"all\": %s", err)))
+		return
+	}
+	digests, err := parseBoolQP(q, "digests")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.NewErrorFromMsg(fmt.Sprintf("invalid query parameter \"digests\": %s", err)))
+		return
+	}
+	sharedSize, err := parseBoolQP(q, "shared-size")
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.NewErrorFromMsg(fmt.Sprintf("invalid query parameter \"shared-size\": %s", err)))
+		return
+	}
+	filters, err := types.ParseFilterArgs(q)
 	if err != nil {
-		response.JSON(w, http.StatusInternalServerError, response.NewError(err))
+		response.JSON(w, http.StatusBadRequest, response.NewErrorFromMsg(fmt.Sprintf("invalid query parameter \"filters\": %s", err)))
+		return
+	}
+	options := types.ImageListOptions{
+		All:        all,
+		Digests:    digests,
+		SharedSize: sharedSize,
+	}
+	for filterType, filterList := range filters.ToLegacyFormat() {
+		for _, f := range filterList {
+			options.Filters = append(options.Filters, fmt.Sprintf("%s=%s", filterType, f))
+		}
+	}
+
+	resp, err := h.service.List(ctx, options)
+	if err != nil {
+		var code int
+		switch {
+		case errdefs.IsInvalidFormat(err):
+			code = http.StatusBadRequest
+		default:
+			code = http.StatusInternalServerError
+		}
+		response.JSON(w, code, response.NewError(err))
 		return
 	}
 	response.JSON(w, http.StatusOK, resp)
 }
+
+// parseBoolQP parses a boolean query parameter, which is false if it is not set.
+func parseBoolQP(q url.Values, key string) (bool, error) {
+	v := q.Get(key)
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
@@ -0,0 +1,112 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/containerd/nerdctl/v2/pkg/config"
+	"go.uber.org/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+var _ = Describe("Image List API", func() {
+	var (
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		service  *mocks_image.MockService
+		h        *handler
+		rr       *httptest.ResponseRecorder
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		logger = mocks_logger.NewLogger(mockCtrl)
+		service = mocks_image.NewMockService(mockCtrl)
+		c := config.Config{}
+		h = newHandler(service, &c, logger)
+		rr = httptest.NewRecorder()
+	})
+	newRequest := func(query string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "/images/json?"+query, nil)
+		Expect(err).Should(BeNil())
+		return req
+	}
+	Context("handler", func() {
+		It("should return 200 status code with the image summaries", func() {
+			service.EXPECT().List(gomock.Any(), types.ImageListOptions{}).Return([]types.ImageSummary{
+				{
+					ID: "sha256:123", RepoTags: []string{"alpine:latest"}, RepoDigests: []string{"alpine@sha256:123"}, SharedSize: -1,
+					Snapshotters: []string{"overlayfs"},
+				},
+			}, nil)
+
+			h.list(rr, newRequest(""))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Body).Should(MatchJSON(`[{
+				"Id": "sha256:123",
+				"ParentId": "",
+				"RepoTags": ["alpine:latest"],
+				"RepoDigests": ["alpine@sha256:123"],
+				"Created": 0,
+				"Size": 0,
+				"SharedSize": -1,
+				"VirtualSize": 0,
+				"Labels": null,
+				"Containers": 0,
+				"Snapshotters": ["overlayfs"]
+			}]`))
+		})
+		It("should pass the query parameters and filters to the service", func() {
+			filters := url.QueryEscape(`{"dangling":{"false":true},"label":{"foo=bar":true}}`)
+			service.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, options types.ImageListOptions) ([]types.ImageSummary, error) {
+					Expect(options.All).Should(BeTrue())
+					Expect(options.Digests).Should(BeTrue())
+					Expect(options.SharedSize).Should(BeTrue())
+					Expect(options.Filters).Should(ConsistOf("dangling=false", "label=foo=bar"))
+					return []types.ImageSummary{}, nil
+				})
+
+			h.list(rr, newRequest("all=1&digests=true&shared-size=true&filters="+filters))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Body).Should(MatchJSON(`[]`))
+		})
+		It("should return 400 status code if a boolean query parameter is invalid", func() {
+			h.list(rr, newRequest("shared-size=maybe"))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
+		})
+		It("should return 400 status code if the digests query parameter is invalid", func() {
+			h.list(rr, newRequest("digests=maybe"))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
+		})
+		It("should return 400 status code if the filters are invalid", func() {
+			h.list(rr, newRequest("filters=invalid"))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
+		})
+		It("should return 400 status code if the service rejects a filter", func() {
+			service.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, errdefs.NewInvalidFormat(fmt.Errorf("invalid filter \"foo=bar\"")))
+
+			h.list(rr, newRequest(""))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "invalid filter \"foo=bar\""}`))
+		})
+		It("should return 500 status code if service returns an error message", func() {
+			service.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
+
+			h.list(rr, newRequest(""))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "error"}`))
+		})
+	})
+})
@@ -17,7 +17,8 @@ func (h *handler) load(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		quiet = false
 	}
-	out := response.NewStreamWriter(w)
+	// the load progress and the loaded images are sent to the response writer as JSON stream.
+	out := response.NewJSONMessageWriter(w)
 	err = h.service.Load(ctx, r.Body, out, quiet)
 	if err != nil {
 		out.WriteError(http.StatusInternalServerError, err)
@@ -4,7 +4,9 @@
 package image
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 
@@ -57,6 +59,20 @@ var _ = Describe("Image Load API", func() {
 			h.load(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
 		})
+		It("should stream the messages of the quiet load", func() {
+			req, _ = http.NewRequest(http.MethodPost, "/images/load?quiet=1", nil)
+			service.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any(), true).DoAndReturn(
+				func(_ context.Context, _ io.Reader, outStream io.Writer, _ bool) error {
+					_, err := outStream.Write([]byte(`{"stream":"Loaded image: test-image:latest\n"}` + "\n"))
+					return err
+				})
+
+			// handler should write the messages as JSON stream
+			h.load(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Header().Get("Content-Type")).Should(Equal("application/json"))
+			Expect(rr.Body).Should(MatchJSON(`{"stream":"Loaded image: test-image:latest\n"}`))
+		})
 		It("should return 500 status code if service returns an error message", func() {
 			service.EXPECT().Load(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
@@ -12,15 +12,16 @@ import (
 
 	"github.com/runfinch/finch-daemon/api/auth"
 	"github.com/runfinch/finch-daemon/api/response"
+	"github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
-// The /images/create API pulls the image specified by given name and tag.
-// Importing images is not supported.
+// The /images/create API pulls the image specified by given name and tag,
+// or imports an image from a rootfs tarball if fromSrc is specified.
 func (h *handler) pull(w http.ResponseWriter, r *http.Request) {
-	warnings := handleUnsupportedParams(r)
-	for warning := range warnings {
-		h.logger.Warn(warning)
+	if r.URL.Query().Get("fromSrc") != "" {
+		h.importImage(w, r)
+		return
 	}
 
 	// get auth creds from header
@@ -38,11 +39,14 @@ func (h *handler) pull(w http.ResponseWriter, r *http.Request) {
 	}
 
 	platform := r.URL.Query().Get("platform")
+	// snapshotter is a finch extension unpacking the image into another snapshotter than the configured one,
+	// e.g. to lazily pull large images with stargz or soci.
+	snapshotter := r.URL.Query().Get("snapshotter")
 
 	// start the pull job and send status updates to the response writer as JSON stream
 	ctx := namespaces.WithNamespace(r.Context(), h.Config.Namespace)
-	streamWriter := response.NewPullJobWriter(w)
-	err = h.service.Pull(ctx, name, tag, platform, authCfg, streamWriter)
+	streamWriter := response.NewJSONMessageWriter(w)
+	err = h.service.Pull(ctx, name, tag, platform, snapshotter, authCfg, streamWriter)
 	if err != nil {
 		var code int
 		switch {
@@ -50,34 +54,44 @@ func (h *handler) pull(w http.ResponseWriter, r *http.Request) {
 			code = http.StatusNotFound
 		case errdefs.IsInvalidFormat(err):
 			code = http.StatusBadRequest
+		case errdefs.IsForbiddenError(err):
+			code = http.StatusForbidden
 		default:
 			code = http.StatusInternalServerError
 		}
 		h.logger.Debugf("Create Image API failed. Status code %d, Message: %s", code, err)
 		streamWriter.WriteError(code, err)
-		return
 	}
-	streamWriter.Write([]byte(fmt.Sprintf("Pulled %s:%s\n", name, tag)))
 }
 
-func handleUnsupportedParams(r *http.Request) []string {
-	// unsupported query parameters: fromSrc, repo, message, changes.
-	// fromSrc, repo, and message are only used when importing images.
-	warnings := []string{}
-	if r.URL.Query().Get("fromSrc") != "" {
-		warnings = append(warnings, "fromSrc parameter specified but importing images is not supported")
-	}
-	if r.URL.Query().Get("repo") != "" {
-		warnings = append(warnings, "repo parameter specified but importing images is not supported")
-	}
-	if r.URL.Query().Get("message") != "" {
-		warnings = append(warnings, "message parameter specified but importing images is not supported")
-	}
-	if r.URL.Query().Get("changes") != "" {
-		warnings = append(warnings, "changes parameter is not supported")
+// importImage imports an image from the rootfs tarball in the request body (fromSrc=-) or in a local file.
+func (h *handler) importImage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	options := types.ImageImportOptions{
+		Source:   q.Get("fromSrc"),
+		Stdin:    r.Body,
+		Repo:     q.Get("repo"),
+		Tag:      q.Get("tag"),
+		Message:  q.Get("message"),
+		Changes:  q["changes"],
+		Platform: q.Get("platform"),
 	}
 
-	return warnings
+	ctx := namespaces.WithNamespace(r.Context(), h.Config.Namespace)
+	streamWriter := response.NewStreamWriter(w)
+	if err := h.service.Import(ctx, options, streamWriter); err != nil {
+		var code int
+		switch {
+		case errdefs.IsNotFound(err):
+			code = http.StatusNotFound
+		case errdefs.IsInvalidFormat(err):
+			code = http.StatusBadRequest
+		default:
+			code = http.StatusInternalServerError
+		}
+		h.logger.Debugf("Import Image API failed. Status code %d, Message: %s", code, err)
+		streamWriter.WriteError(code, err)
+	}
 }
 
 var splitRE = regexp.MustCompile(`[@:]`)
@@ -12,6 +12,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/containerd/nerdctl/v2/pkg/config"
 	dockertypes "github.com/docker/cli/cli/config/types"
@@ -21,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/runfinch/finch-daemon/api/response"
+	"github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/mocks/mocks_image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
@@ -64,6 +66,7 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				"",
+				"",
 				gomock.Any(),
 				gomock.Any(),
 			).Return(nil)
@@ -85,6 +88,29 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				platform,
+				"",
+				gomock.Any(),
+				gomock.Any(),
+			).Return(nil)
+
+			// handler should return 200 status code
+			h.pull(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+		})
+		It("should pull the image into the requested snapshotter", func() {
+			req, err := http.NewRequest(
+				http.MethodPost,
+				fmt.Sprintf("/images/create?fromImage=%s&tag=%s&snapshotter=stargz", name, tag),
+				nil,
+			)
+			Expect(err).Should(BeNil())
+
+			service.EXPECT().Pull(
+				gomock.Any(),
+				name,
+				tag,
+				"",
+				"stargz",
 				gomock.Any(),
 				gomock.Any(),
 			).Return(nil)
@@ -114,6 +140,7 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				"",
+				"",
 				&authCfg,
 				gomock.Any(),
 			).Return(nil)
@@ -137,6 +164,7 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				"",
+				"",
 				gomock.Any(),
 				gomock.Any(),
 			).Return(nil)
@@ -158,6 +186,7 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				platform,
+				"",
 				gomock.Any(),
 				gomock.Any(),
 			).Return(errdefs.NewNotFound(fmt.Errorf("no such image")))
@@ -167,7 +196,7 @@ var _ = Describe("Image Pull API", func() {
 			Expect(rr.Body).Should(MatchJSON(`{"message": "no such image"}`))
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 		})
-		It("should return 500 status code if service returns an error message", func() {
+		It("should return 403 status code if the image fails verification", func() {
 			req, err := http.NewRequest(
 				http.MethodPost,
 				fmt.Sprintf("/images/create?fromImage=%s&tag=%s&platform=%s", name, tag, platform),
@@ -180,19 +209,20 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				platform,
+				"",
 				gomock.Any(),
 				gomock.Any(),
-			).Return(fmt.Errorf("error"))
+			).Return(errdefs.NewForbidden(fmt.Errorf("image verification failed")))
 
-			// handler should return error message with 500 status code
+			// handler should return error message with 403 status code
 			h.pull(rr, req)
-			Expect(rr.Body).Should(MatchJSON(`{"message": "error"}`))
-			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "image verification failed"}`))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusForbidden))
 		})
-		It("should log warnings if unsupported parameters are specified", func() {
+		It("should return 500 status code if service returns an error message", func() {
 			req, err := http.NewRequest(
 				http.MethodPost,
-				fmt.Sprintf("/images/create?fromImage=%s&fromSrc=%s&tag=%s&change=abcd", name, name, tag),
+				fmt.Sprintf("/images/create?fromImage=%s&tag=%s&platform=%s", name, tag, platform),
 				nil,
 			)
 			Expect(err).Should(BeNil())
@@ -201,16 +231,16 @@ var _ = Describe("Image Pull API", func() {
 				gomock.Any(),
 				name,
 				tag,
+				platform,
 				"",
 				gomock.Any(),
 				gomock.Any(),
-			).Return(nil)
-
-			logger.EXPECT().Warn(gomock.Any()).Times(2)
+			).Return(fmt.Errorf("error"))
 
-			// handler should return error message with 400 status code
+			// handler should return error message with 500 status code
 			h.pull(rr, req)
-			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "error"}`))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
 		})
 		It("should return 400 status code if image is not specified", func() {
 			req, err := http.NewRequest(
@@ -249,13 +279,13 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				"",
+				"",
 				gomock.Any(),
 				gomock.Any(),
-			).DoAndReturn(func(_ context.Context, _, _, _ string, _ *dockertypes.AuthConfig, sw io.Writer) error {
-				sw.Write([]byte("this message should be ignored\n"))
-				sw.Write([]byte("resolved image\n")) // the streamwriter will start streaming when a message contains substring "resolved"
-				sw.Write([]byte("pulling image\n"))
-				sw.Write([]byte("pulling complete\n"))
+			).DoAndReturn(func(_ context.Context, _, _, _, _ string, _ *dockertypes.AuthConfig, sw io.Writer) error {
+				sw.Write([]byte(`{"status":"Pulling from library/test-image","id":"test-tag"}` + "\n"))
+				sw.Write([]byte(`{"status":"Downloading","progressDetail":{"current":1,"total":2},"id":"abc"}` + "\n"))
+				sw.Write([]byte(`{"status":"Status: Downloaded newer image for test-image:test-tag"}` + "\n"))
 				return nil
 			})
 
@@ -272,11 +302,11 @@ var _ = Describe("Image Pull API", func() {
 				Expect(err).Should(BeNil())
 				outputs = append(outputs, stream)
 			}
-			Expect(len(outputs)).Should(Equal(4))
-			Expect(outputs[0]).Should(Equal(response.StreamResponse{Stream: "resolved image\n"}))
-			Expect(outputs[1]).Should(Equal(response.StreamResponse{Stream: "pulling image\n"}))
-			Expect(outputs[2]).Should(Equal(response.StreamResponse{Stream: "pulling complete\n"}))
-			Expect(outputs[3]).Should(Equal(response.StreamResponse{Stream: fmt.Sprintf("Pulled %s:%s\n", name, tag)}))
+			Expect(outputs).Should(Equal([]response.StreamResponse{
+				{Status: "Pulling from library/test-image", ID: "test-tag"},
+				{Status: "Downloading", Progress: &jsonmessage.JSONProgress{Current: 1, Total: 2}, ID: "abc"},
+				{Status: "Status: Downloaded newer image for test-image:test-tag"},
+			}))
 		})
 		It("should send 200 status code and display an error message after streaming", func() {
 			req, err := http.NewRequest(
@@ -291,12 +321,12 @@ var _ = Describe("Image Pull API", func() {
 				name,
 				tag,
 				"",
+				"",
 				gomock.Any(),
 				gomock.Any(),
-			).DoAndReturn(func(_ context.Context, _, _, _ string, _ *dockertypes.AuthConfig, sw io.Writer) error {
-				sw.Write([]byte("this message should be ignored\n"))
-				sw.Write([]byte("resolved image\n")) // the streamwriter will start streaming when a message contains substring "resolved"
-				sw.Write([]byte("pulling image\n"))
+			).DoAndReturn(func(_ context.Context, _, _, _, _ string, _ *dockertypes.AuthConfig, sw io.Writer) error {
+				sw.Write([]byte(`{"status":"Pulling from library/test-image","id":"test-tag"}` + "\n"))
+				sw.Write([]byte(`{"status":"Pulling fs layer","id":"abc"}` + "\n"))
 				return fmt.Errorf("error pulling")
 			})
 
@@ -314,12 +344,70 @@ var _ = Describe("Image Pull API", func() {
 				outputs = append(outputs, stream)
 			}
 			Expect(len(outputs)).Should(Equal(3))
-			Expect(outputs[0]).Should(Equal(response.StreamResponse{Stream: "resolved image\n"}))
-			Expect(outputs[1]).Should(Equal(response.StreamResponse{Stream: "pulling image\n"}))
+			Expect(outputs[0]).Should(Equal(response.StreamResponse{Status: "Pulling from library/test-image", ID: "test-tag"}))
+			Expect(outputs[1]).Should(Equal(response.StreamResponse{Status: "Pulling fs layer", ID: "abc"}))
 			Expect(outputs[2]).Should(Equal(response.StreamResponse{
 				Error:        &jsonmessage.JSONError{Code: http.StatusInternalServerError, Message: "error pulling"},
 				ErrorMessage: "error pulling",
 			}))
 		})
 	})
+	Context("import handler", func() {
+		It("should import the request body with the query parameters", func() {
+			req, err := http.NewRequest(
+				http.MethodPost,
+				"/images/create?fromSrc=-&repo=test-image&tag=test-tag&message=imported&changes=CMD%20sh&changes=ENV%20FOO%3Dbar&platform=linux/arm64",
+				strings.NewReader("rootfs"),
+			)
+			Expect(err).Should(BeNil())
+
+			service.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, options types.ImageImportOptions, sw io.Writer) error {
+					Expect(options.Source).Should(Equal("-"))
+					Expect(options.Repo).Should(Equal("test-image"))
+					Expect(options.Tag).Should(Equal("test-tag"))
+					Expect(options.Message).Should(Equal("imported"))
+					Expect(options.Changes).Should(Equal([]string{"CMD sh", "ENV FOO=bar"}))
+					Expect(options.Platform).Should(Equal("linux/arm64"))
+					Expect(io.ReadAll(options.Stdin)).Should(Equal([]byte("rootfs")))
+					_, err := sw.Write([]byte("sha256:123\n"))
+					return err
+				})
+
+			h.pull(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Body).Should(MatchJSON(`{"stream": "sha256:123\n"}`))
+		})
+		It("should return 400 status code if the changes are invalid", func() {
+			req, err := http.NewRequest(http.MethodPost, "/images/create?fromSrc=-&changes=FROM%20scratch", nil)
+			Expect(err).Should(BeNil())
+
+			service.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				errdefs.NewInvalidFormat(fmt.Errorf("FROM is not a valid change command")))
+
+			h.pull(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "FROM is not a valid change command"}`))
+		})
+		It("should return 404 status code if the source file is not found", func() {
+			req, err := http.NewRequest(http.MethodPost, "/images/create?fromSrc=file:///rootfs.tar", nil)
+			Expect(err).Should(BeNil())
+
+			service.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				errdefs.NewNotFound(fmt.Errorf("import source not found: /rootfs.tar")))
+
+			h.pull(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
+		})
+		It("should return 500 status code if service returns an error message", func() {
+			req, err := http.NewRequest(http.MethodPost, "/images/create?fromSrc=-", nil)
+			Expect(err).Should(BeNil())
+
+			service.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+
+			h.pull(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
+			Expect(rr.Body).Should(MatchJSON(`{"message": "error"}`))
+		})
+	})
 })
@@ -4,7 +4,6 @@
 package image
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -25,8 +24,10 @@ func (h *handler) push(w http.ResponseWriter, r *http.Request) {
 
 	// start the push job and send status updates to the response writer as JSON stream
 	ctx := namespaces.WithNamespace(r.Context(), h.Config.Namespace)
-	streamWriter := response.NewStreamWriter(w)
-	result, err := h.service.Push(ctx, mux.Vars(r)["name"], r.URL.Query().Get("tag"), authCfg, streamWriter)
+	streamWriter := response.NewJSONMessageWriter(w)
+	// encryptionRecipient is a finch extension encrypting the layers of the image for each given recipient.
+	recipients := r.URL.Query()["encryptionRecipient"]
+	err = h.service.Push(ctx, mux.Vars(r)["name"], r.URL.Query().Get("tag"), recipients, authCfg, streamWriter)
 	if err != nil {
 		var code int
 		switch {
@@ -39,15 +40,5 @@ func (h *handler) push(w http.ResponseWriter, r *http.Request) {
 		}
 		h.logger.Debugf("Push Image API failed. Status code %d, Message: %s", code, err)
 		streamWriter.WriteError(code, err)
-		return
-	}
-
-	// send push result as out-of-band aux data
-	if result != nil {
-		auxData, err := json.Marshal(result)
-		if err != nil {
-			return
-		}
-		streamWriter.WriteAux(auxData)
 	}
 }
@@ -15,6 +15,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/config"
 	dockertypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/gorilla/mux"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -79,17 +80,15 @@ var _ = Describe("Image Push API", func() {
 				Password: "test-password",
 			}
 
-			// stream messages
-			streamMsg := []string{"Pushing image", "Pushed"}
-
-			service.EXPECT().Push(gomock.Any(), name, tag, gomock.Any(), gomock.Any()).
-				DoAndReturn(func(ctx context.Context, name, tag string, authCfg *dockertypes.AuthConfig, outStream io.Writer) (*types.PushResult, error) {
+			service.EXPECT().Push(gomock.Any(), name, tag, nil, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, name, tag string, _ []string, authCfg *dockertypes.AuthConfig, outStream io.Writer) error {
 					Expect(authCfg.Username).Should(Equal(expectedAuthCfg.Username))
 					Expect(authCfg.Password).Should(Equal(expectedAuthCfg.Password))
-					for _, msg := range streamMsg {
-						outStream.Write([]byte(msg))
-					}
-					return result, nil
+					outStream.Write([]byte(`{"status":"Pushing","progressDetail":{"current":1,"total":2},"id":"abc"}` + "\n"))
+					outStream.Write([]byte(`{"status":"Pushed","id":"abc"}` + "\n"))
+					aux := jsonmessage.JSONMessage{Progress: &jsonmessage.JSONProgress{}, Aux: &auxMsg}
+					Expect(json.NewEncoder(outStream).Encode(aux)).Should(Succeed())
+					return nil
 				})
 
 			// handler should return 200 status code
@@ -105,11 +104,30 @@ var _ = Describe("Image Push API", func() {
 				Expect(err).Should(BeNil())
 				outputs = append(outputs, stream)
 			}
-			Expect(len(outputs)).Should(Equal(len(streamMsg) + 1))
-			for i, msg := range streamMsg {
-				Expect(outputs[i]).Should(Equal(response.StreamResponse{Stream: msg}))
-			}
-			Expect(outputs[len(outputs)-1]).Should(Equal(response.StreamResponse{Aux: &auxMsg}))
+			Expect(outputs).Should(Equal([]response.StreamResponse{
+				{Status: "Pushing", Progress: &jsonmessage.JSONProgress{Current: 1, Total: 2}, ID: "abc"},
+				{Status: "Pushed", ID: "abc"},
+				{Progress: &jsonmessage.JSONProgress{}, Aux: &auxMsg},
+			}))
+		})
+		It("should pass the encryption recipients to the service", func() {
+			req.URL.RawQuery += "&encryptionRecipient=jwe:/keys/a.pem&encryptionRecipient=pkcs7:/keys/b.crt"
+			service.EXPECT().Push(gomock.Any(), name, tag, []string{"jwe:/keys/a.pem", "pkcs7:/keys/b.crt"}, gomock.Any(), gomock.Any()).
+				Return(nil)
+
+			// handler should return 200 status code
+			h.push(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+		})
+		It("should return 400 status code if the encryption recipients are invalid", func() {
+			req.URL.RawQuery += "&encryptionRecipient=jwe:/keys/missing.pem"
+			service.EXPECT().Push(gomock.Any(), name, tag, []string{"jwe:/keys/missing.pem"}, gomock.Any(), gomock.Any()).
+				Return(errdefs.NewInvalidFormat(fmt.Errorf("failed to load the encryption recipients")))
+
+			// handler should return error message with 400 status code
+			h.push(rr, req)
+			Expect(rr.Body).Should(MatchJSON(`{"message": "failed to load the encryption recipients"}`))
+			Expect(rr).Should(HaveHTTPStatus(http.StatusBadRequest))
 		})
 		It("should return 500 status code due to invalid auth header", func() {
 			req.Header.Set(auth.AuthHeader, "Invalid token")
@@ -123,9 +141,10 @@ var _ = Describe("Image Push API", func() {
 				gomock.Any(),
 				name,
 				tag,
+				nil,
 				gomock.Any(),
 				gomock.Any(),
-			).Return(nil, errdefs.NewNotFound(fmt.Errorf("no such image")))
+			).Return(errdefs.NewNotFound(fmt.Errorf("no such image")))
 
 			// handler should return error message with 404 status code
 			h.push(rr, req)
@@ -137,9 +156,10 @@ var _ = Describe("Image Push API", func() {
 				gomock.Any(),
 				name,
 				tag,
+				nil,
 				gomock.Any(),
 				gomock.Any(),
-			).Return(nil, fmt.Errorf("some error"))
+			).Return(fmt.Errorf("some error"))
 
 			// handler should return error message with 500 status code
 			h.push(rr, req)
@@ -147,7 +167,7 @@ var _ = Describe("Image Push API", func() {
 			Expect(rr).Should(HaveHTTPStatus(http.StatusInternalServerError))
 		})
 		It("should return 200 status code but return auth error message as stream response", func() {
-			streamMsg := "Pushing image"
+			streamMsg := `{"status":"Preparing","id":"abc"}`
 			errMsg := "auth error"
 
 			// pass empty auth header.
@@ -156,15 +176,16 @@ var _ = Describe("Image Push API", func() {
 				gomock.Any(),
 				name,
 				tag,
+				nil,
 				gomock.Any(),
 				gomock.Any(),
-			).DoAndReturn(func(ctx context.Context, name, tag string, authCfg *dockertypes.AuthConfig, outStream io.Writer) (*types.PushResult, error) {
+			).DoAndReturn(func(ctx context.Context, name, tag string, _ []string, authCfg *dockertypes.AuthConfig, outStream io.Writer) error {
 				// username and password should be empty
 				Expect(authCfg.Username).Should(BeEmpty())
 				Expect(authCfg.Password).Should(BeEmpty())
 				// mimic service is trying to push the image and failed with auth error.
-				outStream.Write([]byte(streamMsg))
-				return nil, fmt.Errorf("%s", errMsg)
+				outStream.Write([]byte(streamMsg + "\n"))
+				return fmt.Errorf("%s", errMsg)
 			})
 
 			// handler should return error message with 500 status code
@@ -172,7 +193,7 @@ var _ = Describe("Image Push API", func() {
 			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
 			data, err := io.ReadAll(rr.Body)
 			Expect(err).ShouldNot(HaveOccurred())
-			Expect(string(data)).Should(ContainSubstring(streamMsg))
+			Expect(string(data)).Should(ContainSubstring(`"status":"Preparing"`))
 			Expect(string(data)).Should(And(ContainSubstring(errMsg), ContainSubstring(`"errorDetail"`)))
 		})
 	})
@@ -14,7 +14,6 @@ import (
 )
 
 const (
-	// TODO: Figure out how to add removeResponseUntaggedImage to the response.
 	removeResponseUntaggedKey = "Untagged"
 	removeResponseDeletedKey  = "Deleted"
 )
@@ -26,7 +25,11 @@ func (h *handler) remove(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		force = false
 	}
-	deleted, untagged, err := h.service.Remove(r.Context(), name, force)
+	noprune, err := strconv.ParseBool(r.URL.Query().Get("noprune"))
+	if err != nil {
+		noprune = false
+	}
+	untagged, deleted, err := h.service.Remove(r.Context(), name, force, noprune)
 	if err != nil {
 		var code int
 		switch {
@@ -43,6 +46,7 @@ func (h *handler) remove(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, h.buildRemoveResp(untagged, deleted))
 }
 
+// buildRemoveResp lists the untagged references of the image followed by the deleted images.
 func (handler) buildRemoveResp(untagged, deleted []string) []map[string]string {
 	resp := make([]map[string]string, 0, len(deleted)+len(untagged))
 	push := func(key string, items []string) {
@@ -45,14 +45,20 @@ var _ = Describe("Image Remove API", func() {
 	})
 	Context("handler", func() {
 		It("should return  200 status code upon success", func() {
-			service.EXPECT().Remove(gomock.Any(), name, false).Return([]string{"12345"}, []string{"12345"}, nil)
+			service.EXPECT().Remove(gomock.Any(), name, false, false).Return(
+				[]string{"test-image:latest"}, []string{"sha256:12345", "sha256:67890"}, nil)
 
-			// handler should return response object with 200 status code
+			// handler should return the untagged references followed by the deleted images
 			h.remove(rr, req)
 			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+			Expect(rr.Body).Should(MatchJSON(`[
+				{"Untagged": "test-image:latest"},
+				{"Deleted": "sha256:12345"},
+				{"Deleted": "sha256:67890"}
+			]`))
 		})
 		It("should return 404 status code if image was not found", func() {
-			service.EXPECT().Remove(gomock.Any(), name, false).Return(nil, nil, errdefs.NewNotFound(fmt.Errorf("no such image")))
+			service.EXPECT().Remove(gomock.Any(), name, false, false).Return(nil, nil, errdefs.NewNotFound(fmt.Errorf("no such image")))
 
 			// handler should return error message with 404 status code
 			h.remove(rr, req)
@@ -60,7 +66,7 @@ var _ = Describe("Image Remove API", func() {
 			Expect(rr).Should(HaveHTTPStatus(http.StatusNotFound))
 		})
 		It("should return 409 status code if image is being used", func() {
-			service.EXPECT().Remove(gomock.Any(), name, false).Return(nil, nil,
+			service.EXPECT().Remove(gomock.Any(), name, false, false).Return(nil, nil,
 				errdefs.NewConflict(fmt.Errorf("in use")))
 
 			// handler should return error message with 409 status code
@@ -69,7 +75,7 @@ var _ = Describe("Image Remove API", func() {
 			Expect(rr).Should(HaveHTTPStatus(http.StatusConflict))
 		})
 		It("should return 500 status code if service returns an error message", func() {
-			service.EXPECT().Remove(gomock.Any(), name, false).Return(nil, nil, fmt.Errorf("error"))
+			service.EXPECT().Remove(gomock.Any(), name, false, false).Return(nil, nil, fmt.Errorf("error"))
 
 			// handler should return error message
 			h.remove(rr, req)
@@ -81,7 +87,7 @@ var _ = Describe("Image Remove API", func() {
 			req = mux.SetURLVars(req, map[string]string{"name": name})
 
 			Expect(err).Should(BeNil())
-			service.EXPECT().Remove(gomock.Any(), name, true).Return([]string{"12345"}, []string{"12345"}, nil)
+			service.EXPECT().Remove(gomock.Any(), name, true, false).Return([]string{"12345"}, []string{"12345"}, nil)
 
 			// handler should return response object with 200 status code
 			h.remove(rr, req)
@@ -92,7 +98,29 @@ var _ = Describe("Image Remove API", func() {
 			req = mux.SetURLVars(req, map[string]string{"name": name})
 
 			Expect(err).Should(BeNil())
-			service.EXPECT().Remove(gomock.Any(), name, false).Return([]string{"12345"}, []string{"12345"}, nil)
+			service.EXPECT().Remove(gomock.Any(), name, false, false).Return([]string{"12345"}, []string{"12345"}, nil)
+
+			// handler should return response object with 200 status code
+			h.remove(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+		})
+		It("should pass noprune flag to service", func() {
+			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/images/%s?noprune=1", name), nil)
+			req = mux.SetURLVars(req, map[string]string{"name": name})
+
+			Expect(err).Should(BeNil())
+			service.EXPECT().Remove(gomock.Any(), name, false, true).Return([]string{"test-image:latest"}, []string{}, nil)
+
+			// handler should return response object with 200 status code
+			h.remove(rr, req)
+			Expect(rr).Should(HaveHTTPStatus(http.StatusOK))
+		})
+		It("should pass noprune flag as false to service", func() {
+			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/images/%s?noprune=0", name), nil)
+			req = mux.SetURLVars(req, map[string]string{"name": name})
+
+			Expect(err).Should(BeNil())
+			service.EXPECT().Remove(gomock.Any(), name, false, false).Return([]string{"test-image:latest"}, []string{"12345"}, nil)
 
 			// handler should return response object with 200 status code
 			h.remove(rr, req)
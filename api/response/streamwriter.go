@@ -5,8 +5,8 @@ package response
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strings"
 	"sync"
 
 	"github.com/docker/docker/pkg/jsonmessage"
@@ -16,16 +16,16 @@ import (
 //
 // From https://github.com/moby/moby/blob/v24.0.4/pkg/jsonmessage/jsonmessage.go#L145-L158
 type StreamResponse struct {
-	Stream string `json:"stream,omitempty"`
-	// TODO: Status          string        `json:"status,omitempty"`
-	// TODO: Progress        *JSONProgress `json:"progressDetail,omitempty"`
-	// TODO: ProgressMessage string        `json:"progress,omitempty"` // deprecated
-	// TODO: ID              string        `json:"id,omitempty"`
-	// TODO: From            string        `json:"from,omitempty"`
-	// TODO: Time            int64         `json:"time,omitempty"`
-	// TODO: TimeNano        int64         `json:"timeNano,omitempty"`
-	Error        *jsonmessage.JSONError `json:"errorDetail,omitempty"`
-	ErrorMessage string                 `json:"error,omitempty"` // deprecated
+	Stream          string                    `json:"stream,omitempty"`
+	Status          string                    `json:"status,omitempty"`
+	Progress        *jsonmessage.JSONProgress `json:"progressDetail,omitempty"`
+	ProgressMessage string                    `json:"progress,omitempty"` // deprecated
+	ID              string                    `json:"id,omitempty"`
+	From            string                    `json:"from,omitempty"`
+	Time            int64                     `json:"time,omitempty"`
+	TimeNano        int64                     `json:"timeNano,omitempty"`
+	Error           *jsonmessage.JSONError    `json:"errorDetail,omitempty"`
+	ErrorMessage    string                    `json:"error,omitempty"` // deprecated
 	// Aux contains out-of-band data, such as digests for push signing and image id after building.
 	Aux *json.RawMessage `json:"aux,omitempty"`
 }
@@ -56,22 +56,29 @@ func NewStreamWriter(w http.ResponseWriter) *StreamWriter {
 // Write function is implementation of Writer interface. This function converts the stdout and stderr output into
 // json stream and send it though http response.
 func (sw *StreamWriter) Write(b []byte) (n int, err error) {
+	if err = sw.WriteMessage(StreamResponse{Stream: string(b)}); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// WriteMessage sends a Docker-compatible JSON message, such as a progress update, to the JSON stream.
+func (sw *StreamWriter) WriteMessage(msg StreamResponse) error {
 	// set response header and status code only once
 	sw.initializer.Do(func() {
 		sw.responseWriter.Header().Set("Content-Type", "application/json")
 		sw.responseWriter.WriteHeader(http.StatusOK)
 	})
 
-	err = sw.jsonEncoder.Encode(StreamResponse{Stream: string(b)})
-	if err != nil {
-		return 0, err
+	if err := sw.jsonEncoder.Encode(msg); err != nil {
+		return err
 	}
 	if sw.flusher != nil {
 		// flush after each write so the client can receive status
 		// updates as they happen
 		sw.flusher.Flush()
 	}
-	return len(b), nil
+	return nil
 }
 
 // WriteError sends a Docker-compatible error message and status code as a
@@ -102,72 +109,41 @@ func (sw *StreamWriter) WriteError(code int, err error) error {
 // WriteAux sends raw data as a Docker-compatible auxiliary response,
 // such as digests for pushed image or image id after building.
 func (sw *StreamWriter) WriteAux(data []byte) error {
-	// set response header and status code only once
-	sw.initializer.Do(func() {
-		sw.responseWriter.Header().Set("Content-Type", "application/json")
-		sw.responseWriter.WriteHeader(http.StatusOK)
-	})
-
 	aux := json.RawMessage(data)
-	err := sw.jsonEncoder.Encode(StreamResponse{Aux: &aux})
-	if err != nil {
-		return err
-	}
-	if sw.flusher != nil {
-		sw.flusher.Flush()
-	}
-
-	return nil
+	return sw.WriteMessage(StreamResponse{Aux: &aux})
 }
 
-type pullJobWriter struct {
+type jsonMessageWriter struct {
 	StreamWriter
-	resolved bool
-	mx       sync.Mutex
 }
 
-// NewPullJobWriter is an extension of StreamWriter that sends image pull status updates from
-// nerdctl to http response as a JSON stream. It ensures that the image is resolved before
-// writing anything to the response body, so that any resolver errors can be sent to the client
-// directly with an appropriate status code.
-func NewPullJobWriter(w http.ResponseWriter) *pullJobWriter {
-	pw := &pullJobWriter{
+// NewJSONMessageWriter is an extension of StreamWriter that sends the JSON messages written by a service, such as
+// image pull and push status updates, to http response as a JSON stream. The services only write the messages once
+// the operation has started, so that any earlier errors can be sent to the client directly with an appropriate
+// status code.
+func NewJSONMessageWriter(w http.ResponseWriter) *jsonMessageWriter {
+	mw := &jsonMessageWriter{
 		StreamWriter: StreamWriter{
 			responseWriter: w,
 			jsonEncoder:    json.NewEncoder(w),
 		},
-		resolved: false,
 	}
 	// check if the writer implements http.Flusher interface and assign it to flusher
 	if flusher, ok := w.(http.Flusher); ok && flusher != nil {
-		pw.flusher = flusher
+		mw.flusher = flusher
 	}
-	return pw
+	return mw
 }
 
-// Write function is implementation of Writer interface, similar to that of StreamWriter.
-// However, until the image is resolved, nothing is written to the response body and header remains unset.
-func (pw *pullJobWriter) Write(b []byte) (n int, err error) {
-	if !pw.IsResolved() {
-		str := string(b)
-		if strings.Contains(str, "resolved") {
-			pw.Resolve()
-		} else {
-			return 0, nil
-		}
+// Write function is implementation of Writer interface. Unlike StreamWriter, each write is a JSON message
+// written as is rather than an output wrapped in a JSON stream object.
+func (mw *jsonMessageWriter) Write(b []byte) (n int, err error) {
+	var msg StreamResponse
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return 0, fmt.Errorf("invalid JSON message: %w", err)
 	}
-
-	return pw.StreamWriter.Write(b)
-}
-
-func (pw *pullJobWriter) IsResolved() bool {
-	pw.mx.Lock()
-	defer pw.mx.Unlock()
-	return pw.resolved
-}
-
-func (pw *pullJobWriter) Resolve() {
-	pw.mx.Lock()
-	defer pw.mx.Unlock()
-	pw.resolved = true
+	if err := mw.WriteMessage(msg); err != nil {
+		return 0, err
+	}
+	return len(b), nil
 }
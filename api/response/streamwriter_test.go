@@ -209,63 +209,48 @@ var _ = Describe("API Response ", func() {
 		})
 	})
 
-	Context("pullJobWriter", func() {
-		It("should write messages as JSON stream objects after being resolved", func() {
-			resolvedMsg := []byte("resolved image")
-			resolvedResp := []byte(fmt.Sprintf(`{"stream":"%s"}`+"\n", resolvedMsg))
+	Context("jsonMessageWriter", func() {
+		It("should write the JSON messages as is to the JSON stream and set header once", func() {
+			statusMsg := []byte(`{"status":"Pulling fs layer","id":"abc"}` + "\n")
+			progressMsg := []byte(`{"status":"Downloading","progressDetail":{"current":1,"total":2},"id":"abc"}` + "\n")
 
 			// expected calls to response writer
 			respWriter.EXPECT().Header().Return(respHeader)
 			respWriter.EXPECT().WriteHeader(http.StatusOK)
-			respWriter.EXPECT().Write(resolvedResp).Return(len(resolvedMsg), nil)
-			respWriter.EXPECT().Write(resp2).Return(len(msg2), nil)
-
-			// pulljobwriter should only write messages after being resolved and set header once
-			sw := NewPullJobWriter(respWriter)
-			n, err := sw.Write(msg1)
-			Expect(err).Should(BeNil())
-			Expect(n).Should(Equal(0))
-			Expect(respHeader).Should(BeEmpty())
-			Expect(sw.IsResolved()).Should(BeFalse())
+			respWriter.EXPECT().Write(statusMsg).Return(len(statusMsg), nil)
+			respWriter.EXPECT().Write(progressMsg).Return(len(progressMsg), nil)
 
-			n, err = sw.Write(resolvedMsg)
+			sw := NewJSONMessageWriter(respWriter)
+			n, err := sw.Write(statusMsg)
 			Expect(err).Should(BeNil())
-			Expect(n).Should(Equal(len(resolvedMsg)))
+			Expect(n).Should(Equal(len(statusMsg)))
 			Expect(respHeader).Should(HaveKeyWithValue("Content-Type", []string{"application/json"}))
-			Expect(sw.IsResolved()).Should(BeTrue())
 
-			n, err = sw.Write(msg2)
+			n, err = sw.Write(progressMsg)
 			Expect(err).Should(BeNil())
-			Expect(n).Should(Equal(len(msg2)))
+			Expect(n).Should(Equal(len(progressMsg)))
 		})
-		It("should ignore all messages if not resolved and send an error message with status code", func() {
-			var err error
-			errResp, err = json.Marshal(NewError(errMsg))
-			Expect(err).Should(BeNil())
-			errResp = append(errResp, byte('\n'))
-
-			// pulljobwriter will ignore all the messages
-			sw := NewPullJobWriter(respWriter)
+		It("should return an error if the message is not a JSON message", func() {
+			sw := NewJSONMessageWriter(respWriter)
 			n, err := sw.Write(msg1)
-			Expect(err).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
 			Expect(n).Should(Equal(0))
 			Expect(respHeader).Should(BeEmpty())
-			Expect(sw.IsResolved()).Should(BeFalse())
-
-			n, err = sw.Write(msg2)
+		})
+		It("should send an error message with status code if nothing was written", func() {
+			var err error
+			errResp, err = json.Marshal(NewError(errMsg))
 			Expect(err).Should(BeNil())
-			Expect(n).Should(Equal(0))
-			Expect(respHeader).Should(BeEmpty())
-			Expect(sw.IsResolved()).Should(BeFalse())
+			errResp = append(errResp, byte('\n'))
 
-			// pulljobwriter will send the error message with status code
+			// jsonmessagewriter will send the error message with status code
 			respWriter.EXPECT().Header().Return(respHeader)
 			respWriter.EXPECT().WriteHeader(http.StatusInternalServerError)
 			respWriter.EXPECT().Write(errResp).Return(len(errResp), nil)
+			sw := NewJSONMessageWriter(respWriter)
 			err = sw.WriteError(http.StatusInternalServerError, errMsg)
 			Expect(err).Should(BeNil())
 			Expect(respHeader).Should(HaveKeyWithValue("Content-Type", []string{"application/json"}))
-			Expect(sw.IsResolved()).Should(BeFalse())
 		})
 	})
 })
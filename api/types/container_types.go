@@ -25,6 +25,8 @@ type AttachOptions struct {
 	Stream     bool
 	// TODO: DetachKeys string
 	MuxStreams bool
+	// Stdin is forwarded to the stdin of the container if UseStdin is set and the container has one.
+	Stdin io.Reader
 }
 
 // ContainerConfig is from https://github.com/moby/moby/blob/v24.0.2/api/types/container/config.go#L64-L96
@@ -103,15 +105,17 @@ type ContainerHostConfig struct {
 	// TODO: Isolation Isolation // Isolation technology of the container (e.g. default, hyperv)
 
 	// Contains container's resources (cgroups, ulimits)
-	CPUShares         int64  `json:"CpuShares"`  // CPU shares (relative weight vs. other containers)
-	CPUPeriod         int64  `json:"CpuPeriod"`  // CPU CFS (Completely Fair Scheduler) period
-	CPUQuota          int64  `json:"CpuQuota"`   // CPU CFS (Completely Fair Scheduler) quota
-	CPUSetCPUs        string `json:"CpusetCpus"` // CPUSetCPUs specifies the CPUs in which to allow execution (0-3, 0,1)
-	CPUSetMems        string `json:"CpusetMems"` // CPUSetMems specifies the memory nodes (MEMs) in which to allow execution (0-3, 0,1). Only effective on NUMA systems.
-	Memory            int64  // Memory limit (in bytes)
-	MemoryReservation int64  // MemoryReservation specifies the memory soft limit (in bytes)
-	MemorySwap        int64  // Total memory usage (memory + swap); set `-1` to enable unlimited swap
-	MemorySwappiness  int64  // MemorySwappiness64 specifies the tune container memory swappiness (0 to 100) (default -1)
+	CPUShares          int64  `json:"CpuShares"`          // CPU shares (relative weight vs. other containers)
+	CPUPeriod          int64  `json:"CpuPeriod"`          // CPU CFS (Completely Fair Scheduler) period
+	CPUQuota           int64  `json:"CpuQuota"`           // CPU CFS (Completely Fair Scheduler) quota
+	CPUSetCPUs         string `json:"CpusetCpus"`         // CPUSetCPUs specifies the CPUs in which to allow execution (0-3, 0,1)
+	CPUSetMems         string `json:"CpusetMems"`         // CPUSetMems specifies the memory nodes (MEMs) in which to allow execution (0-3, 0,1). Only effective on NUMA systems.
+	CPURealtimePeriod  int64  `json:"CpuRealtimePeriod"`  // CPU real-time period in microseconds
+	CPURealtimeRuntime int64  `json:"CpuRealtimeRuntime"` // CPU real-time runtime in microseconds
+	Memory             int64  // Memory limit (in bytes)
+	MemoryReservation  int64  // MemoryReservation specifies the memory soft limit (in bytes)
+	MemorySwap         int64  // Total memory usage (memory + swap); set `-1` to enable unlimited swap
+	MemorySwappiness   int64  // MemorySwappiness64 specifies the tune container memory swappiness (0 to 100) (default -1)
 	// TODO: Resources
 
 	Ulimits              []*Ulimit // List of ulimits to be set in the container
@@ -163,7 +167,7 @@ type Container struct {
 	Created        string
 	Path           string
 	Args           []string
-	State          *dockercompat.ContainerState
+	State          *ContainerState
 	Image          string
 	ResolvConfPath string
 	HostnamePath   string
@@ -177,15 +181,71 @@ type Container struct {
 	// TODO: MountLabel      string
 	// TODO: ProcessLabel    string
 	AppArmorProfile string
-	// TODO: ExecIDs         []string
-	HostConfig *ContainerHostConfig
+	ExecIDs         []string
+	HostConfig      *ContainerHostConfig
 	// TODO: GraphDriver     GraphDriverData
 	SizeRw     *int64 `json:",omitempty"`
 	SizeRootFs *int64 `json:",omitempty"`
 
 	Mounts          []dockercompat.MountPoint
 	Config          *ContainerConfig
-	NetworkSettings *dockercompat.NetworkSettings
+	NetworkSettings *NetworkSettings
+}
+
+// ContainerState completes the state reported by nerdctl with the fields it does not track.
+// From https://github.com/moby/moby/blob/v24.0.2/api/types/types.go#L313-L326
+type ContainerState struct {
+	dockercompat.ContainerState
+	OOMKilled bool
+}
+
+// NetworkSettings exposes the network settings of a container in the api.
+// From https://github.com/moby/moby/blob/v24.0.2/api/types/types.go#L386-L396
+type NetworkSettings struct {
+	// TODO: Bridge                 string
+	// TODO: SandboxID              string
+	// TODO: HairpinMode            bool
+	// TODO: LinkLocalIPv6Address   string
+	// TODO: LinkLocalIPv6PrefixLen int
+	Ports      *nat.PortMap // Ports is a collection of PortBinding indexed by Port
+	SandboxKey string       // SandboxKey identifies the sandbox
+	// TODO: SecondaryIPAddresses   []network.Address
+	// TODO: SecondaryIPv6Addresses []network.Address
+	DefaultNetworkSettings
+	Networks map[string]*NetworkEndpointSettings
+}
+
+// DefaultNetworkSettings holds network information of the container's primary network.
+// From https://github.com/moby/moby/blob/v24.0.2/api/types/types.go#L404-L415
+type DefaultNetworkSettings struct {
+	EndpointID          string // EndpointID uniquely represents a service endpoint in a Sandbox
+	Gateway             string // Gateway holds the gateway address for the network
+	GlobalIPv6Address   string // GlobalIPv6Address holds network's global IPv6 address
+	GlobalIPv6PrefixLen int    // GlobalIPv6PrefixLen represents mask length of network's global IPv6 address
+	IPAddress           string // IPAddress holds the IPv4 address for the network
+	IPPrefixLen         int    // IPPrefixLen represents mask length of network's IPv4 address
+	IPv6Gateway         string // IPv6Gateway holds gateway address specific for IPv6
+	MacAddress          string // MacAddress holds the MAC address for the network
+}
+
+// NetworkEndpointSettings stores the network endpoint details of a container on one network.
+// From https://github.com/moby/moby/blob/v24.0.2/api/types/network/network.go#L51-L68
+type NetworkEndpointSettings struct {
+	// Configurations
+	// TODO: IPAMConfig *EndpointIPAMConfig
+	// TODO: Links      []string
+	Aliases []string
+	// Operational data
+	NetworkID           string
+	EndpointID          string
+	Gateway             string
+	IPAddress           string
+	IPPrefixLen         int
+	IPv6Gateway         string
+	GlobalIPv6Address   string
+	GlobalIPv6PrefixLen int
+	MacAddress          string
+	// TODO: DriverOpts map[string]string
 }
 
 type ContainerListItem struct {
@@ -210,6 +270,7 @@ type LogsOptions struct {
 	Until      int64
 	Timestamps bool
 	Tail       string
+	Details    bool
 	MuxStreams bool
 }
 
@@ -37,7 +37,6 @@ type ExecStartCheck struct {
 
 type ExecStartOptions struct {
 	*ExecStartCheck
-	ConID           string
 	ExecID          string
 	Stdin           io.ReadCloser
 	Stdout          io.Writer
@@ -46,7 +45,6 @@ type ExecStartOptions struct {
 }
 
 type ExecResizeOptions struct {
-	ConID  string
 	ExecID string
 	Height int
 	Width  int
@@ -3,6 +3,12 @@
 
 package types
 
+import (
+	"io"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+)
+
 /*
 ImageSummary models a single item in the list response to /images/json in the
 Docker API.
@@ -10,10 +16,88 @@ https://docs.docker.com/engine/api/v1.40/#operation/ImageList
 */
 type ImageSummary struct {
 	ID          string `json:"Id"`
+	ParentID    string `json:"ParentId"`
 	RepoTags    []string
 	RepoDigests []string
 	Created     int64
 	Size        int64
+	// SharedSize is the size of the layers the image shares with other images, or -1 if it was not requested.
+	SharedSize  int64
+	VirtualSize int64
+	Labels      map[string]string
+	// Containers is the number of containers using the image.
+	Containers int64
+	// Snapshotters are the snapshotters the image is unpacked into, which is a finch extension.
+	Snapshotters []string
+}
+
+// ImageInspect models the response to /images/{name}/json in the Docker API, which completes the
+// nerdctl inspect object with the references of the image and the snapshotter it is stored in.
+// From https://github.com/moby/moby/blob/v24.0.2/api/types/types.go#L69-L156
+type ImageInspect struct {
+	ID            string `json:"Id"`
+	RepoTags      []string
+	RepoDigests   []string
+	Parent        string
+	Comment       string
+	Created       string
+	DockerVersion string
+	Author        string
+	Config        *dockercompat.Config
+	Architecture  string
+	Variant       string `json:",omitempty"`
+	Os            string
+	Size          int64
+	VirtualSize   int64 `json:"VirtualSize,omitempty"`
+	GraphDriver   GraphDriverData
+	RootFS        dockercompat.RootFS
+	Metadata      dockercompat.ImageMetadata
+	// Snapshotters are the snapshotters the image is unpacked into, which is a finch extension.
+	Snapshotters []string
+}
+
+// GraphDriverData is the storage driver of an image, which is the snapshotter for containerd.
+// From https://github.com/moby/moby/blob/v24.0.2/api/types/graph_driver_data.go
+type GraphDriverData struct {
+	Name string
+	// Data is the low-level storage metadata of the driver. It is only set for the overlayfs snapshotter, with the
+	// LowerDir, UpperDir and WorkDir directories of the overlay2 driver of docker.
+	Data map[string]string
+}
+
+// ImageListOptions holds the query parameters of /images/json.
+type ImageListOptions struct {
+	// All includes the images which are not unpacked in the snapshotter.
+	All bool
+	// Digests is accepted for compatibility; RepoDigests are always returned, as docker does.
+	Digests    bool
+	SharedSize bool
+	// Filters are in the "key=value" format of nerdctl.
+	Filters []string
+}
+
+// ImageImportOptions holds the parameters of /images/create when it imports an image from a rootfs tarball.
+type ImageImportOptions struct {
+	// Source is "-" to read the tarball from Stdin, or the file URL of the tarball.
+	Source string
+	Stdin  io.Reader
+	// Repo and Tag name the imported image. The image is only named after its digest if Repo is empty.
+	Repo     string
+	Tag      string
+	Message  string
+	Changes  []string
+	Platform string
+}
+
+// ImageHistoryItem models a single item in the response to /images/{name}/history in the Docker API.
+// From https://github.com/moby/moby/blob/v24.0.2/api/types/image/image_history.go
+type ImageHistoryItem struct {
+	ID        string `json:"Id"`
+	Created   int64
+	CreatedBy string
+	Tags      []string
+	Size      int64
+	Comment   string
 }
 
 // PushResult contains the tag, manifest digest, and manifest size from the
@@ -31,6 +31,8 @@ import (
 	credentialRouter "github.com/runfinch/finch-daemon/api/credential-router"
 	"github.com/runfinch/finch-daemon/api/router"
 	"github.com/runfinch/finch-daemon/internal/fs/passwd"
+	"github.com/runfinch/finch-daemon/internal/logging"
+	"github.com/runfinch/finch-daemon/internal/verification"
 	"github.com/runfinch/finch-daemon/pkg/credential"
 	"github.com/runfinch/finch-daemon/pkg/flog"
 	"github.com/runfinch/finch-daemon/version"
@@ -50,6 +52,8 @@ type DaemonOptions struct {
 	configPath         string
 	pidFile            string
 	regoFilePath       string
+	verificationPolicyPath string
+	decryptionKeys     []string
 	enableExperimental bool
 	skipRegoPermCheck  bool
 }
@@ -57,6 +61,14 @@ type DaemonOptions struct {
 var options = new(DaemonOptions)
 
 func main() {
+	// containerd runs the daemon binary as the logging binary of the containers created through the daemon.
+	if len(os.Args) > 2 && os.Args[1] == logging.MagicArgv1 {
+		if err := logging.Main(os.Args[2]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	rootCmd := &cobra.Command{
 		Use:          "finch-daemon",
 		Short:        "Finch daemon with a Docker-compatible API",
@@ -73,6 +85,8 @@ func main() {
 	rootCmd.Flags().StringVar(&options.configPath, "config-file", config.DefaultConfigPath, "Daemon Config Path")
 	rootCmd.Flags().StringVar(&options.pidFile, "pidfile", config.DefaultPidFile, "pid file location")
 	rootCmd.Flags().StringVar(&options.regoFilePath, "rego-file", "", "Rego Policy Path (requires --experimental flag)")
+	rootCmd.Flags().StringVar(&options.verificationPolicyPath, "image-verification-policy", "", "image signature verification policy path")
+	rootCmd.Flags().StringArrayVar(&options.decryptionKeys, "image-decryption-key", nil, "private key (and optional password separated by a colon) to decrypt encrypted image layers with; can be repeated")
 	rootCmd.Flags().BoolVar(&options.skipRegoPermCheck, "skip-rego-perm-check", false, "skip the rego file permission check (allows permissions more permissive than 0600)")
 	rootCmd.Flags().BoolVar(&options.enableExperimental, "experimental", false, "enable experimental features")
 
@@ -284,6 +298,20 @@ func newRouter(options *DaemonOptions, logger *flog.Logrus, credService *credent
 	if err != nil {
 		return nil, err
 	}
+	if options.verificationPolicyPath != "" {
+		policy, err := verification.Load(options.verificationPolicyPath)
+		if err != nil {
+			return nil, err
+		}
+		ncWrapper.SetVerificationPolicy(policy)
+		logger.Infof("image signature verification policy loaded from %s", options.verificationPolicyPath)
+	}
+	if len(options.decryptionKeys) > 0 {
+		if err := ncWrapper.SetDecryptionKeys(options.decryptionKeys); err != nil {
+			return nil, err
+		}
+		logger.Infof("%d image decryption keys loaded", len(options.decryptionKeys))
+	}
 
 	var regoFilePath string
 
@@ -301,6 +329,7 @@ func newRouter(options *DaemonOptions, logger *flog.Logrus, credService *credent
 	}
 
 	opts := createRouterOptions(conf, clientWrapper, ncWrapper, logger, regoFilePath, credService)
+	startMonitors(conf, clientWrapper, logger)
 	newRouter, err := router.New(opts)
 	if err != nil {
 		return nil, err
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/config"
 	toml "github.com/pelletier/go-toml/v2"
+	"google.golang.org/grpc"
 
 	finchconfig "github.com/runfinch/finch-daemon/pkg/config"
 	"github.com/runfinch/finch-daemon/api/router"
@@ -88,7 +90,10 @@ func createNerdctlWrapper(clientWrapper *backend.ContainerdClientWrapper, conf *
 
 // createContainerdClient creates and wraps the containerd client.
 func createContainerdClient(conf *config.Config) (*backend.ContainerdClientWrapper, error) {
-	client, err := containerd.New(conf.Address, containerd.WithDefaultNamespace(conf.Namespace))
+	client, err := containerd.New(conf.Address,
+		containerd.WithDefaultNamespace(conf.Namespace),
+		containerd.WithExtraDialOpts([]grpc.DialOption{grpc.WithChainUnaryInterceptor(backend.ContainerExtensionsInterceptor)}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create containerd client: %w", err)
 	}
@@ -105,7 +110,7 @@ func createRouterOptions(
 	credService *credential.CredentialService,
 ) *router.Options {
 	fs := afero.NewOsFs()
-	tarCreator := archive.NewTarCreator(ecc.NewExecCmdCreator(), logger)
+	tarCreator := archive.NewTarCreator(logger)
 	tarExtractor := archive.NewTarExtractor(ecc.NewExecCmdCreator(), logger)
 
 	return &router.Options{
@@ -124,6 +129,12 @@ func createRouterOptions(
 	}
 }
 
+// startMonitors starts the routines watching containerd events for the lifetime of the daemon.
+func startMonitors(conf *config.Config, clientWrapper *backend.ContainerdClientWrapper, logger *flog.Logrus) {
+	ctx := namespaces.WithNamespace(context.Background(), conf.Namespace)
+	go container.MonitorOOM(ctx, clientWrapper, logger)
+}
+
 // checkRegoFileValidity validates and prepares the Rego policy file for use.
 // It verifies that the file exists, has the right extension (.rego), and has appropriate permissions.
 func checkRegoFileValidity(options *DaemonOptions, logger *flog.Logrus) (string, error) {
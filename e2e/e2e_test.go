@@ -168,6 +168,7 @@ func runImageTests(opt *option.Option) {
 	tests.ImageRemove(opt)
 	tests.ImagePush(opt)
 	tests.ImagePull(opt)
+	tests.ImageVerification(opt)
 	tests.ImageExport(opt)
 }
 
@@ -24,7 +24,6 @@ import (
 
 	"github.com/runfinch/finch-daemon/e2e/client"
 	"github.com/runfinch/finch-daemon/pkg/archive"
-	"github.com/runfinch/finch-daemon/pkg/ecc"
 	"github.com/runfinch/finch-daemon/pkg/flog"
 )
 
@@ -357,23 +356,11 @@ func CredentialHelper(opt *option.Option, pOpt func([]string, ...option.Modifier
 // createTarFromBuildContext creates a tar archive from the build context directory.
 func createTarFromBuildContext(buildContextPath string) (io.Reader, error) {
 	logger := flog.NewLogrus()
-	eccCreator := ecc.NewExecCmdCreator()
-	tarCreator := archive.NewTarCreator(eccCreator, logger)
-
-	cmd, err := tarCreator.CreateTarCommand(buildContextPath, true)
-	if err != nil {
-		return nil, err
-	}
+	tarCreator := archive.NewTarCreator(logger)
 
 	pr, pw := io.Pipe()
-	cmd.SetStdout(pw)
-
 	go func() {
-		err := cmd.Run()
-		if err != nil {
-			logger.Errorf("Error running tar command: %v", err)
-		}
-		pw.Close()
+		pw.CloseWithError(tarCreator.CreateTar(buildContextPath, pw, true))
 	}()
 
 	return pr, nil
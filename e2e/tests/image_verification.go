@@ -0,0 +1,117 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/runfinch/common-tests/command"
+	"github.com/runfinch/common-tests/option"
+
+	"github.com/runfinch/finch-daemon/e2e/client"
+)
+
+const verificationSocket = "/run/test-verification.sock"
+
+// ImageVerification tests the image signature verification policy on the `POST images/create` API, against
+// a daemon started with a policy requiring the images of the local registry to be signed with a cosign key
+// generated by the test.
+func ImageVerification(opt *option.Option) {
+	Describe("pull an image with a verification policy", func() {
+		var (
+			uClient       *http.Client
+			version       string
+			signedImage   string
+			unsignedImage string
+		)
+		BeforeEach(func() {
+			cosign, err := exec.LookPath("cosign")
+			if err != nil {
+				Skip("cosign is not installed")
+			}
+			if _, err := os.Stat(GetFinchDaemonExe()); err != nil {
+				Skip("the finch-daemon binary is not available")
+			}
+			command.RemoveImages(opt)
+			dir, err := os.MkdirTemp("", "verification")
+			Expect(err).ShouldNot(HaveOccurred())
+			DeferCleanup(os.RemoveAll, dir)
+
+			// push a signed and an unsigned image to the local registry.
+			registry, _, _ := strings.Cut(defaultImage, "/")
+			signedImage = registry + "/verification-signed:latest"
+			unsignedImage = registry + "/verification-unsigned:latest"
+			command.Run(opt, "pull", defaultImage)
+			for _, image := range []string{signedImage, unsignedImage} {
+				command.Run(opt, "tag", defaultImage, image)
+				command.Run(opt, "push", image)
+			}
+			command.RemoveImages(opt)
+
+			// the image is signed offline, so the transparency log is ignored by the policy.
+			env := append(os.Environ(), "COSIGN_PASSWORD=")
+			runCosign := func(args ...string) {
+				cmd := exec.Command(cosign, args...) //nolint:gosec // G204: This is a test file with controlled inputs
+				cmd.Dir, cmd.Env = dir, env
+				out, err := cmd.CombinedOutput()
+				Expect(err).ShouldNot(HaveOccurred(), string(out))
+			}
+			runCosign("generate-key-pair")
+			runCosign("sign", "--yes", "--tlog-upload=false", "--key", filepath.Join(dir, "cosign.key"), signedImage)
+
+			policyPath := filepath.Join(dir, "policy.toml")
+			policy := fmt.Sprintf("[default]\nprovider = \"none\"\n\n[[rules]]\nscope = %q\nprovider = \"cosign\"\n"+
+				"cosign_key = %q\ncosign_ignore_tlog = true\n", registry, filepath.Join(dir, "cosign.pub"))
+			Expect(os.WriteFile(policyPath, []byte(policy), 0o600)).Should(Succeed())
+
+			daemon := exec.Command(GetFinchDaemonExe(), //nolint:gosec // G204: This is a test file with controlled inputs
+				"--socket-addr", verificationSocket,
+				"--credential-socket-addr", "/run/test-verification-credential.sock",
+				"--pidfile", "/run/test-verification.pid",
+				"--image-verification-policy", policyPath)
+			Expect(daemon.Start()).Should(Succeed())
+			DeferCleanup(func() {
+				daemon.Process.Kill()
+				daemon.Wait()
+			})
+
+			uClient = client.NewClient(verificationSocket)
+			version = GetDockerApiVersion()
+			Eventually(func() error {
+				_, err := uClient.Get(client.ConvertToFinchUrl("", "/version"))
+				return err
+			}).WithTimeout(10 * time.Second).Should(Succeed())
+		})
+		AfterEach(func() {
+			command.RemoveAll(opt)
+		})
+
+		It("should pull an image signed with the key of the policy", func() {
+			url := client.ConvertToFinchUrl(version, fmt.Sprintf("/images/create?fromImage=%s", signedImage))
+			resp, err := uClient.Post(url, "application/json", nil)
+
+			Expect(err).Should(BeNil())
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			waitForResponse(resp)
+			imageShouldExist(opt, signedImage)
+		})
+		It("should not pull an unsigned image", func() {
+			url := client.ConvertToFinchUrl(version, fmt.Sprintf("/images/create?fromImage=%s", unsignedImage))
+			resp, err := uClient.Post(url, "application/json", nil)
+
+			Expect(err).Should(BeNil())
+			Expect(resp.StatusCode).Should(Equal(http.StatusForbidden))
+			waitForResponse(resp)
+			imageShouldNotExist(opt, unsignedImage)
+		})
+	})
+}
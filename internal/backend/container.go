@@ -6,6 +6,7 @@ package backend
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,9 +18,20 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/containerinspector"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/namestore"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/store"
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 //go:generate mockgen --destination=../../mocks/mocks_backend/nerdctlcontainersvc.go -package=mocks_backend github.com/runfinch/finch-daemon/internal/backend NerdctlContainerSvc
@@ -41,11 +53,17 @@ type NerdctlContainerSvc interface {
 
 	// Mocked functions for container attach
 	GetDataStore() (string, error)
+	Snapshotter() string
 	LoggingInitContainerLogViewer(containerLabels map[string]string, lvopts logging.LogViewOptions, stopChannel chan os.Signal, experimental bool) (contlv *logging.ContainerLogViewer, err error)
 	LoggingPrintLogsTo(stdout, stderr io.Writer, clv *logging.ContainerLogViewer) error
 
 	// GetNerdctlExe returns a path to the nerdctl binary, which is required for setting up OCI hooks and logging
 	GetNerdctlExe() (string, error)
+
+	// VerifyImage verifies the signature of the image which a container is created from against the verification policy
+	VerifyImage(ctx context.Context, rawRef string) (digest.Digest, error)
+	// UnpackImage unpacks the image which a container is created from with the decryption keys of the daemon
+	UnpackImage(ctx context.Context, rawRef string, options types.ContainerCreateOptions) error
 }
 
 func (w *NerdctlWrapper) RemoveContainer(ctx context.Context, c containerd.Container, force bool, removeVolumes bool) error {
@@ -66,6 +84,81 @@ func (w *NerdctlWrapper) CreateContainer(ctx context.Context, args []string, net
 	return container.Create(ctx, w.clientWrapper.client, args, netManager, options)
 }
 
+// UnpackImage unpacks the image a container is created from for its platform, decrypting its encrypted layers with
+// the decryption keys of the daemon, so that the creation of the container does not unpack them without the keys.
+// The image is pulled first if the pull policy of the container would pull it. It does nothing if the daemon has
+// no decryption keys, in which case the layers can only be decrypted by the stream processors of containerd.
+func (w *NerdctlWrapper) UnpackImage(ctx context.Context, rawRef string, options types.ContainerCreateOptions) error {
+	if w.decryptConfig == nil || options.Rootfs {
+		return nil
+	}
+	var platformSS []string
+	if options.Platform != "" {
+		platformSS = append(platformSS, options.Platform)
+	}
+	ocispecPlatforms, err := platformutil.NewOCISpecPlatformSlice(false, platformSS)
+	if err != nil {
+		return err
+	}
+	platform := ocispecPlatforms[0]
+
+	client := w.clientWrapper.client
+	var img containerd.Image
+	walker := &imagewalker.ImageWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			if img != nil {
+				return nil
+			}
+			candidate := containerd.NewImageWithPlatform(client, found.Image, platforms.OnlyStrict(platform))
+			// skip the images which do not provide the platform.
+			if _, err := candidate.Config(ctx); err == nil {
+				img = candidate
+			}
+			return nil
+		},
+	}
+	if _, err := walker.Walk(ctx, rawRef); err != nil {
+		return err
+	}
+
+	if options.Pull == "always" || (img == nil && options.Pull != "never") {
+		pullOpt := options.ImagePullOpt
+		pullOpt.Mode = "always"
+		pullOpt.OCISpecPlatform = ocispecPlatforms
+		// an image with encrypted layers is unpacked after the pull to decrypt them with the keys.
+		if w.isEncryptedRemoteImage(ctx, rawRef, platform) {
+			unpack := false
+			pullOpt.Unpack = &unpack
+		}
+		ensured, err := imgutil.EnsureImage(ctx, client, rawRef, pullOpt)
+		if err != nil {
+			return err
+		}
+		img = ensured.Image
+	}
+	if img == nil {
+		// the creation of the container reports the missing image.
+		return nil
+	}
+	return w.unpackImage(ctx, img, w.snapshotterOrDefault(options.GOptions.Snapshotter))
+}
+
+// isEncryptedRemoteImage returns whether the image with the reference has encrypted layers for the platform in
+// its registry, which is resolved with the hosts configuration of the daemon. The image is assumed to be
+// encrypted if it cannot be resolved.
+func (w *NerdctlWrapper) isEncryptedRemoteImage(ctx context.Context, rawRef string, platform ocispec.Platform) bool {
+	named, err := reference.ParseDockerRef(rawRef)
+	if err != nil {
+		return true
+	}
+	resolver, _, err := w.GetDockerResolver(ctx, reference.Domain(named), nil)
+	if err != nil {
+		return true
+	}
+	return isEncryptedImage(ctx, resolver, named.String(), platform)
+}
+
 func (w *NerdctlWrapper) InspectContainer(ctx context.Context, c containerd.Container, sizeFlag bool) (*dockercompat.Container, error) {
 	var buf bytes.Buffer
 	options := types.ContainerInspectOptions{
@@ -104,8 +197,50 @@ func (w *NerdctlWrapper) ListContainers(ctx context.Context, options types.Conta
 	return container.List(ctx, w.clientWrapper.client, options)
 }
 
-func (w *NerdctlWrapper) RenameContainer(ctx context.Context, con containerd.Container, newName string, options types.ContainerRenameOptions) error {
-	return container.Rename(ctx, w.clientWrapper.client, con.ID(), newName, options)
+// RenameContainer renames a container in the name store, in the hosts files of every container sharing a network
+// with it and in its labels. Unlike nerdctl, a failure to update the hosts files fails the rename, and every change
+// already made is rolled back when one of the steps fails.
+func (w *NerdctlWrapper) RenameContainer(ctx context.Context, con containerd.Container, newName string, options types.ContainerRenameOptions) (err error) {
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	namest, err := namestore.New(dataStore, options.GOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	hostst, err := hostsstore.New(dataStore, options.GOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	lbls, err := con.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	id, oldName := con.ID(), lbls[labels.Name]
+
+	if err = namest.Rename(oldName, id, newName); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			namest.Rename(newName, id, oldName)
+		}
+	}()
+
+	// containers without hosts store entry, e.g. on network none, are not resolvable by any peer
+	if err = hostst.Update(id, newName); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	} else if err == nil {
+		defer func() {
+			if err != nil {
+				hostst.Update(id, oldName)
+			}
+		}()
+	}
+
+	_, err = con.SetLabels(ctx, map[string]string{labels.Name: newName})
+	return err
 }
 
 func (w *NerdctlWrapper) GetDataStore() (string, error) {
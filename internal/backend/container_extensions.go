@@ -0,0 +1,50 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	containersapi "github.com/containerd/containerd/api/services/containers/v1"
+	"github.com/containerd/typeurl/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type containerExtensionsKey struct{}
+
+// WithContainerExtensions returns a context with which the containers created, e.g. by nerdctl which does not
+// take extra container options, get the extensions in addition to their own. The extensions are marshalled
+// with typeurl, so their types must be registered.
+func WithContainerExtensions(ctx context.Context, extensions map[string]any) context.Context {
+	return context.WithValue(ctx, containerExtensionsKey{}, extensions)
+}
+
+// ContainerExtensionsInterceptor is a unary client interceptor of the containerd connection, which adds the
+// extensions of the context to the containers created with it, so that they are written atomically with
+// the containers instead of by later updates.
+func ContainerExtensionsInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+) error {
+	createReq, ok := req.(*containersapi.CreateContainerRequest)
+	if !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	extensions, ok := ctx.Value(containerExtensionsKey{}).(map[string]any)
+	if !ok || len(extensions) == 0 {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	if createReq.Container.Extensions == nil {
+		createReq.Container.Extensions = make(map[string]*anypb.Any, len(extensions))
+	}
+	for name, ext := range extensions {
+		value, err := typeurl.MarshalAnyToProto(ext)
+		if err != nil {
+			return fmt.Errorf("failed to marshal container extension %s: %w", name, err)
+		}
+		createReq.Container.Extensions[name] = value
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
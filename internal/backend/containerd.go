@@ -10,19 +10,23 @@ import (
 	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
 	"github.com/containerd/containerd/v2/core/events"
 	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/containerd/v2/core/images/converter"
+	"github.com/containerd/containerd/v2/core/leases"
 	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	"github.com/containerd/containerd/v2/pkg/cap"
 	"github.com/containerd/containerd/v2/pkg/cio"
 	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/containerd/v2/plugins"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/platforms"
+	"github.com/containerd/typeurl/v2"
 	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -34,6 +38,7 @@ type ContainerdClient interface {
 	GetContainerStatus(ctx context.Context, c containerd.Container) containerd.ProcessStatus
 	SearchContainer(ctx context.Context, searchText string) (containers []containerd.Container, err error)
 	GetContainers(ctx context.Context, filters ...string) (containers []containerd.Container, err error)
+	UpdateContainerExtension(ctx context.Context, id, name string, extension any) error
 	GetImage(ctx context.Context, ref string) (containerd.Image, error)
 	SearchImage(ctx context.Context, searchText string) ([]images.Image, error)
 	ParsePlatform(platform string) (ocispec.Platform, error)
@@ -44,12 +49,15 @@ type ContainerdClient interface {
 	GetContainerTaskWait(ctx context.Context, attach cio.Attach, c containerd.Container) (task containerd.Task, waitCh <-chan containerd.ExitStatus, err error)
 	GetContainerRemoveEvent(ctx context.Context, c containerd.Container) (<-chan *events.Envelope, <-chan error)
 	ListSnapshotMounts(ctx context.Context, cid string) ([]mount.Mount, error)
+	ViewSnapshotMounts(ctx context.Context, snapshotter, key string) ([]mount.Mount, error)
 	MountAll(mounts []mount.Mount, mPath string) error
 	Unmount(mPath string, flags int) error
 	ImageService() images.Store
 	ConvertImage(ctx context.Context, dstRef, srcRef string, opts ...converter.Opt) (*images.Image, error)
 	DeleteImage(ctx context.Context, img string) error
 	GetImageDigests(ctx context.Context, img *images.Image) (digests []digest.Digest, err error)
+	GetImageLayers(ctx context.Context, img *images.Image) ([]ocispec.Descriptor, error)
+	GetContentLabels(ctx context.Context, dgst digest.Digest) (map[string]string, error)
 	GetUsedImages(ctx context.Context) (stopped, running map[string]string, err error)
 	OCISpecWithUser(user string) oci.SpecOpts
 	OCISpecWithAdditionalGIDs(user string) oci.SpecOpts
@@ -58,6 +66,7 @@ type ContainerdClient interface {
 	NewDirectCIO(ctx context.Context, fifos *cio.FIFOSet) (*cio.DirectIO, error)
 	SubscribeToEvents(ctx context.Context, filters ...string) (<-chan *events.Envelope, <-chan error)
 	PublishEvent(ctx context.Context, topic string, event events.Event) error
+	HasSnapshotter(ctx context.Context, name string) (bool, error)
 }
 
 type ContainerdClientWrapper struct {
@@ -117,6 +126,20 @@ func (w *ContainerdClientWrapper) GetContainers(ctx context.Context, filters ...
 	return containers, err
 }
 
+// UpdateContainerExtension sets the extension of the container with the name. Only the extension is updated,
+// so that concurrent updates of the other fields and extensions of the container are not overwritten.
+func (w *ContainerdClientWrapper) UpdateContainerExtension(ctx context.Context, id, name string, extension any) error {
+	value, err := typeurl.MarshalAny(extension)
+	if err != nil {
+		return fmt.Errorf("failed to marshal container extension %s: %w", name, err)
+	}
+	_, err = w.client.ContainerService().Update(ctx, containers.Container{
+		ID:         id,
+		Extensions: map[string]typeurl.Any{name: value},
+	}, "extensions."+name)
+	return err
+}
+
 // GetImage returns an image with given reference.
 func (w *ContainerdClientWrapper) GetImage(ctx context.Context, ref string) (containerd.Image, error) {
 	return w.client.GetImage(ctx, ref)
@@ -152,6 +175,16 @@ func (w *ContainerdClientWrapper) DefaultPlatformStrict() platforms.MatchCompare
 	return platforms.DefaultStrict()
 }
 
+// HasSnapshotter returns whether containerd provides the snapshotter with the name,
+// including the proxy snapshotters such as stargz and soci.
+func (w *ContainerdClientWrapper) HasSnapshotter(ctx context.Context, name string) (bool, error) {
+	resp, err := w.client.IntrospectionService().Plugins(ctx, fmt.Sprintf("type==%s, id==%s", plugins.SnapshotPlugin, name))
+	if err != nil {
+		return false, err
+	}
+	return len(resp.Plugins) > 0, nil
+}
+
 // ParseDockerRef normalizes the image reference following the docker convention.
 func (w *ContainerdClientWrapper) ParseDockerRef(rawRef string) (ref, refDomain string, err error) {
 	named, err := reference.ParseDockerRef(rawRef)
@@ -190,6 +223,25 @@ func (w *ContainerdClientWrapper) ListSnapshotMounts(ctx context.Context, key st
 	return w.client.SnapshotService("").Mounts(ctx, key)
 }
 
+// ViewSnapshotMounts returns the mounts of a read-only view of the snapshot with the key in the snapshotter, which
+// may be committed, e.g. the snapshot of an image. The view is removed once its mounts are read.
+func (w *ContainerdClientWrapper) ViewSnapshotMounts(ctx context.Context, snapshotter, key string) ([]mount.Mount, error) {
+	ctx, done, err := w.client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	defer done(ctx)
+
+	sn := w.client.SnapshotService(snapshotter)
+	viewKey := fmt.Sprintf("%s-view-%d", key, time.Now().UnixNano())
+	mounts, err := sn.View(ctx, viewKey, key)
+	if err != nil {
+		return nil, err
+	}
+	defer sn.Remove(ctx, viewKey)
+	return mounts, nil
+}
+
 func (*ContainerdClientWrapper) MountAll(mounts []mount.Mount, mPath string) error {
 	return mount.All(mounts, mPath)
 }
@@ -217,6 +269,24 @@ func (w *ContainerdClientWrapper) GetImageDigests(ctx context.Context, img *imag
 	return img.RootFS(ctx, cntStore, platforms.DefaultStrict())
 }
 
+// GetImageLayers returns the layer descriptors of the image's manifest for the default platform.
+func (w *ContainerdClientWrapper) GetImageLayers(ctx context.Context, img *images.Image) ([]ocispec.Descriptor, error) {
+	manifest, err := images.Manifest(ctx, w.client.ContentStore(), img.Target, platforms.DefaultStrict())
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Layers, nil
+}
+
+// GetContentLabels returns the labels of the content with the digest.
+func (w *ContainerdClientWrapper) GetContentLabels(ctx context.Context, dgst digest.Digest) (map[string]string, error) {
+	info, err := w.client.ContentStore().Info(ctx, dgst)
+	if err != nil {
+		return nil, err
+	}
+	return info.Labels, nil
+}
+
 // GetUsedImages returns the list of images that are used by containers.
 // `stopped` contains the images used by stopped containers, `running` contains the images used by running containers.
 func (w *ContainerdClientWrapper) GetUsedImages(ctx context.Context) (stopped, running map[string]string, err error) {
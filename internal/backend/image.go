@@ -5,12 +5,22 @@ package backend
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"slices"
+	"sort"
+	"strings"
 
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
 	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
 	"github.com/containerd/containerd/v2/core/remotes"
 	"github.com/containerd/containerd/v2/core/remotes/docker"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/imgcrypt/v2"
+	"github.com/containerd/imgcrypt/v2/images/encryption"
 	dockerconfig "github.com/containerd/containerd/v2/core/remotes/docker/config"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/containerdutil"
@@ -21,32 +31,141 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/load"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/push"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/signutil"
 	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/internal/verification"
 )
 
+// snapshotRefLabelPrefix is the prefix of the labels referencing the snapshot of an image in a snapshotter.
+const snapshotRefLabelPrefix = "containerd.io/gc.ref.snapshot."
+
+// maxManifestSize is the maximum size of the indexes and manifests fetched to check if an image is encrypted.
+const maxManifestSize = 4 << 20
+
+// LayerProgress is the progress of pulling a layer of an image.
+type LayerProgress struct {
+	Descriptor ocispec.Descriptor
+	// Offset is the number of bytes of the layer downloaded so far, if it is being downloaded.
+	Offset      int64
+	Downloading bool
+	Downloaded  bool
+	Unpacked    bool
+}
+
 //go:generate mockgen --destination=../../mocks/mocks_backend/nerdctlimagesvc.go -package=mocks_backend github.com/runfinch/finch-daemon/internal/backend NerdctlImageSvc
 type NerdctlImageSvc interface {
-	InspectImage(ctx context.Context, image images.Image) (*dockercompat.Image, error)
+	InspectImage(ctx context.Context, image images.Image, platform *ocispec.Platform) (*dockercompat.Image, error)
 	GetDockerResolver(ctx context.Context, refDomain string, creds dockerconfigresolver.AuthCreds) (remotes.Resolver, docker.StatusTracker, error)
-	PullImage(ctx context.Context, stdout, stderr io.Writer, resolver remotes.Resolver, ref string, platforms []ocispec.Platform) (*imgutil.EnsuredImage, error)
+	PullImage(ctx context.Context, stdout, stderr io.Writer, resolver remotes.Resolver, ref string, platforms []ocispec.Platform, snapshotter string) (*imgutil.EnsuredImage, error)
+	GetLayerProgress(ctx context.Context, root ocispec.Descriptor, platform ocispec.Platform, snapshotter string) ([]LayerProgress, error)
 	PushImage(ctx context.Context, resolver remotes.Resolver, tracker docker.StatusTracker, stdout io.Writer, pushRef, ref string, platMC platforms.MatchComparer) error
 	SearchImage(ctx context.Context, name string) (int, int, []*images.Image, error)
-	LoadImage(ctx context.Context, img string, stdout io.Writer, quiet bool) error
+	LoadImage(ctx context.Context, img string) ([]images.Image, error)
 	ExportImage(ctx context.Context, imageNames []string, platform *ocispec.Platform, writer io.Writer) error
+	ListImages(ctx context.Context, filters *imgutil.Filters) ([]images.Image, error)
+	GetImageSnapshotters(ctx context.Context, image images.Image, platform *ocispec.Platform) ([]string, error)
+	GetImageLayerSizes(ctx context.Context, image images.Image) (map[string]int64, error)
+	GetImageConfig(ctx context.Context, image images.Image) (*ocispec.Image, error)
+	ImportImage(ctx context.Context, rootfs io.Reader, ref string, platform ocispec.Platform, config ocispec.ImageConfig, message string) (*images.Image, error)
 	GetDataStore() (string, error)
 	Namespace() string
+	Snapshotter() string
+	VerifyImage(ctx context.Context, rawRef string) (digest.Digest, error)
 }
 
-func (w *NerdctlWrapper) InspectImage(ctx context.Context, image images.Image) (*dockercompat.Image, error) {
+// InspectImage inspects the image for the platform, or for the default platform if none is given.
+// A multi-platform image which does not provide the platform locally returns a not found error.
+func (w *NerdctlWrapper) InspectImage(ctx context.Context, image images.Image, platform *ocispec.Platform) (*dockercompat.Image, error) {
 	snapshotter := containerdutil.SnapshotService(w.clientWrapper.client, w.globalOptions.Snapshotter)
-	n, err := imageinspector.Inspect(ctx, w.clientWrapper.client, image, snapshotter)
+	if platform == nil {
+		n, err := imageinspector.Inspect(ctx, w.clientWrapper.client, image, snapshotter)
+		if err != nil {
+			return nil, err
+		}
+		return dockercompat.ImageFromNative(n)
+	}
+
+	img := containerd.NewImageWithPlatform(w.clientWrapper.client, image, platforms.OnlyStrict(*platform))
+	config, configDesc, err := imgutil.ReadImageConfig(ctx, img)
 	if err != nil {
 		return nil, err
 	}
+	n := &native.Image{
+		Image:           image,
+		ImageConfigDesc: configDesc,
+		ImageConfig:     config,
+	}
+	// the image has no size if it is not unpacked for the platform.
+	n.Size, _ = imgutil.UnpackedImageSize(ctx, snapshotter, img)
 	return dockercompat.ImageFromNative(n)
 }
 
+// VerifyImage verifies the signature of the image against the verification policy of the daemon, and returns
+// the digest the image was verified at, or an empty digest if the policy does not require verifying the image.
+func (w *NerdctlWrapper) VerifyImage(ctx context.Context, rawRef string) (digest.Digest, error) {
+	if w.verificationPolicy == nil {
+		return "", nil
+	}
+	named, err := reference.ParseNormalizedNamed(rawRef)
+	if err != nil {
+		return "", err
+	}
+	rule := w.verificationPolicy.Rule(named)
+	options := rule.VerifyOptions()
+	if options.Provider == verification.ProviderNone {
+		return "", nil
+	}
+	if rule.CosignIgnoreTlog {
+		return w.verifyCosignIgnoringTlog(ctx, named, rule.CosignKey)
+	}
+	// nerdctl only provides the verifiers as experimental features, which the policy of the daemon enables.
+	ref, err := signutil.Verify(ctx, named.String(), w.globalOptions.HostsDir, true, options)
+	if err != nil {
+		return "", err
+	}
+	verified, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", err
+	}
+	digested, ok := verified.(reference.Digested)
+	if !ok {
+		return "", fmt.Errorf("image %s was verified without a digest", rawRef)
+	}
+	return digested.Digest(), nil
+}
+
+// verifyCosignIgnoringTlog verifies the image against the cosign key without checking the transparency log,
+// which nerdctl does not support, at the digest its reference resolves to.
+func (w *NerdctlWrapper) verifyCosignIgnoringTlog(ctx context.Context, named reference.Named, key string) (digest.Digest, error) {
+	resolved, err := imgutil.ResolveDigest(ctx, named.String(), false, w.globalOptions.HostsDir)
+	if err != nil {
+		return "", err
+	}
+	dgst, err := digest.Parse(resolved)
+	if err != nil {
+		return "", err
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), dgst)
+	if err != nil {
+		return "", err
+	}
+	if err := verification.VerifyCosignIgnoringTlog(ctx, pinned.String(), key); err != nil {
+		return "", err
+	}
+	return dgst, nil
+}
+
+// Snapshotter returns the name of the configured snapshotter.
+func (w *NerdctlWrapper) Snapshotter() string {
+	return w.globalOptions.Snapshotter
+}
+
 // GetDockerResolver returns a new Docker config resolver from the reference host and auth credentials.
 func (w *NerdctlWrapper) GetDockerResolver(ctx context.Context, refDomain string, creds dockerconfigresolver.AuthCreds) (remotes.Resolver, docker.StatusTracker, error) {
 	dOpts := []dockerconfigresolver.Opt{dockerconfigresolver.WithHostsDirs(w.globalOptions.HostsDir)}
@@ -68,30 +187,201 @@ func (w *NerdctlWrapper) GetDockerResolver(ctx context.Context, refDomain string
 	return docker.NewResolver(resolverOpts), tracker, nil
 }
 
-// PullImage pulls an image from nerdctl's imgutil library.
-func (w *NerdctlWrapper) PullImage(ctx context.Context, stdout, stderr io.Writer, resolver remotes.Resolver, ref string, platforms []ocispec.Platform) (*imgutil.EnsuredImage, error) {
+// PullImage pulls an image from nerdctl's imgutil library, unpacking it into the snapshotter,
+// or into the configured snapshotter if none is given.
+func (w *NerdctlWrapper) PullImage(ctx context.Context, stdout, stderr io.Writer, resolver remotes.Resolver, ref string, platforms []ocispec.Platform, snapshotter string) (*imgutil.EnsuredImage, error) {
+	gOptions := *w.globalOptions
+	gOptions.Snapshotter = w.snapshotterOrDefault(snapshotter)
 	opts := types.ImagePullOptions{
 		Stdout:          stdout,
 		Stderr:          stderr,
-		GOptions:        *w.globalOptions,
+		GOptions:        gOptions,
 		Unpack:          nil,
 		OCISpecPlatform: platforms,
 		Mode:            "always",
 		Quiet:           false,
 	}
 
-	return imgutil.PullImage(
+	// with decryption keys, an image with encrypted layers is unpacked after the pull to decrypt them with the keys,
+	// which is only done for the platform the image is pulled for.
+	decrypt := false
+	if w.decryptConfig != nil {
+		if len(platforms) != 1 {
+			return nil, fmt.Errorf("%w: an image can only be pulled for a single platform with decryption keys",
+				cerrdefs.ErrInvalidArgument)
+		}
+		decrypt = isEncryptedImage(ctx, resolver, ref, platforms[0])
+	}
+	if decrypt {
+		unpack := false
+		opts.Unpack = &unpack
+	}
+	ensured, err := imgutil.PullImage(
 		ctx,
 		w.clientWrapper.client,
 		resolver,
 		ref,
 		opts,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if decrypt {
+		if err := w.unpackImage(ctx, ensured.Image, gOptions.Snapshotter); err != nil {
+			return nil, err
+		}
+	}
+	return ensured, nil
+}
+
+// unpackImage unpacks the image for its platform in the snapshotter, decrypting its encrypted layers with the
+// decryption keys of the daemon. The keys are checked against the layers first, so that a missing key is
+// reported as a permission denied error before anything is unpacked.
+func (w *NerdctlWrapper) unpackImage(ctx context.Context, img containerd.Image, snapshotter string) error {
+	if unpacked, err := img.IsUnpacked(ctx, snapshotter); err == nil && unpacked {
+		return nil
+	}
+	manifest, desc, err := imgutil.ReadManifest(ctx, img)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		return fmt.Errorf("%w: image %s has no manifest for the platform", cerrdefs.ErrNotFound, img.Name())
+	}
+	if !encryption.HasEncryptedLayer(ctx, manifest.Layers) {
+		return img.Unpack(ctx, snapshotter)
+	}
+	if err := encryption.CheckAuthorization(ctx, w.clientWrapper.client.ContentStore(), *desc, w.decryptConfig); err != nil {
+		return fmt.Errorf("%w: no decryption key of the daemon can decrypt image %s: %w", cerrdefs.ErrPermissionDenied, img.Name(), err)
+	}
+	payload := &imgcrypt.Payload{DecryptConfig: *w.decryptConfig}
+	return img.Unpack(ctx, snapshotter, encryption.WithUnpackConfigApplyOpts(encryption.WithDecryptedUnpack(payload)))
+}
+
+// isEncryptedImage returns whether the manifest of the image with the reference for the platform has encrypted
+// layers, fetching it with the resolver. The image is assumed to be encrypted if its manifest cannot be fetched,
+// as an encrypted image can only be pulled by unpacking it after the pull.
+func isEncryptedImage(ctx context.Context, resolver remotes.Resolver, ref string, platform ocispec.Platform) bool {
+	manifest, err := fetchManifest(ctx, resolver, ref, platform)
+	if err != nil {
+		return true
+	}
+	return encryption.HasEncryptedLayer(ctx, manifest.Layers)
+}
+
+// fetchManifest fetches the manifest of the image with the reference for the platform from its registry.
+func fetchManifest(ctx context.Context, resolver remotes.Resolver, ref string, platform ocispec.Platform) (*ocispec.Manifest, error) {
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	matcher := platforms.Only(platform)
+	for images.IsIndexType(desc.MediaType) {
+		var index ocispec.Index
+		if err := fetchJSON(ctx, fetcher, desc, &index); err != nil {
+			return nil, err
+		}
+		var found *ocispec.Descriptor
+		for i, m := range index.Manifests {
+			if m.Platform != nil && !matcher.Match(*m.Platform) {
+				continue
+			}
+			if found == nil || (found.Platform != nil && m.Platform != nil && matcher.Less(*m.Platform, *found.Platform)) {
+				found = &index.Manifests[i]
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("%w: image %s has no manifest for the platform", cerrdefs.ErrNotFound, ref)
+		}
+		desc = *found
+	}
+	if !images.IsManifestType(desc.MediaType) {
+		return nil, fmt.Errorf("unexpected media type %s of the manifest of image %s", desc.MediaType, ref)
+	}
+	var manifest ocispec.Manifest
+	if err := fetchJSON(ctx, fetcher, desc, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
 }
 
-// PushImage pushes an image using nerdctl's imgutil library.
+// fetchJSON fetches the content of the descriptor and decodes it into v.
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor, v any) error {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, maxManifestSize))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// snapshotterOrDefault returns the snapshotter, or the configured snapshotter if none is given.
+func (w *NerdctlWrapper) snapshotterOrDefault(snapshotter string) string {
+	if snapshotter == "" {
+		return w.globalOptions.Snapshotter
+	}
+	return snapshotter
+}
+
+// GetLayerProgress returns the progress of pulling each layer of the image with the root descriptor for
+// the platform, from the state of the content store and the snapshotter, or the configured snapshotter if
+// none is given. It returns a NotFound error if the manifest of the image has not been fetched yet.
+func (w *NerdctlWrapper) GetLayerProgress(ctx context.Context, root ocispec.Descriptor, platform ocispec.Platform, snapshotter string) ([]LayerProgress, error) {
+	cs := w.clientWrapper.client.ContentStore()
+	manifest, err := images.Manifest(ctx, cs, root, platforms.Only(platform))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := cs.ListStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ingests := make(map[string]content.Status, len(statuses))
+	for _, status := range statuses {
+		ingests[status.Ref] = status
+	}
+
+	// the chain IDs of the layers are only known once the config is fetched.
+	var chainIDs []digest.Digest
+	var config ocispec.Image
+	if b, err := content.ReadBlob(ctx, cs, manifest.Config); err == nil && json.Unmarshal(b, &config) == nil &&
+		len(config.RootFS.DiffIDs) == len(manifest.Layers) {
+		chainIDs = identity.ChainIDs(slices.Clone(config.RootFS.DiffIDs))
+	}
+	snapshots := containerdutil.SnapshotService(w.clientWrapper.client, w.snapshotterOrDefault(snapshotter))
+
+	layers := make([]LayerProgress, len(manifest.Layers))
+	for i, desc := range manifest.Layers {
+		layers[i].Descriptor = desc
+		if _, err := cs.Info(ctx, desc.Digest); err == nil {
+			layers[i].Downloaded = true
+		} else if status, ok := ingests[remotes.MakeRefKey(ctx, desc)]; ok {
+			layers[i].Downloading = true
+			layers[i].Offset = status.Offset
+		}
+		if chainIDs != nil {
+			if _, err := snapshots.Stat(ctx, chainIDs[i].String()); err == nil {
+				layers[i].Unpacked = true
+			}
+		}
+	}
+	return layers, nil
+}
+
+// PushImage pushes an image using nerdctl's imgutil library, writing its progress to stdout unless it is nil.
+// The content of the image is then labeled with the repository it was pushed to, as pulls label the content
+// with the repository it was pulled from, so that the repository digest of the image is known.
 func (w *NerdctlWrapper) PushImage(ctx context.Context, resolver remotes.Resolver, tracker docker.StatusTracker, stdout io.Writer, pushRef, ref string, platMC platforms.MatchComparer) error {
-	return push.Push(
+	err := push.Push(
 		ctx,
 		w.clientWrapper.client,
 		resolver,
@@ -100,8 +390,21 @@ func (w *NerdctlWrapper) PushImage(ctx context.Context, resolver remotes.Resolve
 		pushRef, ref,
 		platMC,
 		false,
-		false,
+		stdout == nil,
 	)
+	if err != nil {
+		return err
+	}
+	img, err := w.clientWrapper.client.ImageService().Get(ctx, pushRef)
+	if err != nil {
+		return err
+	}
+	labelSource, err := docker.AppendDistributionSourceLabel(w.clientWrapper.client.ContentStore(), ref)
+	if err != nil {
+		return err
+	}
+	_, err = labelSource(ctx, img.Target)
+	return err
 }
 
 func (w *NerdctlWrapper) SearchImage(ctx context.Context, name string) (int, int, []*images.Image, error) {
@@ -119,18 +422,16 @@ func (w *NerdctlWrapper) SearchImage(ctx context.Context, name string) (int, int
 	return n, uniqueCount, imgs, err
 }
 
-func (w *NerdctlWrapper) LoadImage(ctx context.Context, img string, stdout io.Writer, q bool) error {
-	// TODO currently the "quiet" flag in nerdctl is hardcoded as "false".
-	// Ideally this flag should be part of the ImageLoadOptions, we can
-	// contribute this enhancement at upstream
-	_, err := load.FromArchive(ctx, w.clientWrapper.client, types.ImageLoadOptions{
-		Stdout:       stdout,
+// LoadImage imports the images of the docker-archive or OCI layout tarball at img, for all platforms, and unpacks
+// them. The plain text output of nerdctl is discarded, as the loaded images are reported by the caller.
+func (w *NerdctlWrapper) LoadImage(ctx context.Context, img string) ([]images.Image, error) {
+	return load.FromArchive(ctx, w.clientWrapper.client, types.ImageLoadOptions{
+		Stdout:       io.Discard,
 		GOptions:     *w.globalOptions,
 		Input:        img,
 		AllPlatforms: true,
-		Quiet:        q,
+		Quiet:        true,
 	})
-	return err
 }
 
 func (w *NerdctlWrapper) ExportImage(ctx context.Context, imageNames []string, platform *ocispec.Platform, writer io.Writer) error {
@@ -145,3 +446,94 @@ func (w *NerdctlWrapper) ExportImage(ctx context.Context, imageNames []string, p
 	}
 	return image.Save(ctx, w.clientWrapper.client, imageNames, opts)
 }
+
+// ListImages returns the image records matching the filters, using nerdctl's imgutil library to apply them.
+func (w *NerdctlWrapper) ListImages(ctx context.Context, filters *imgutil.Filters) ([]images.Image, error) {
+	client := w.clientWrapper.client
+	imageList, err := client.ImageService().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageFilters []imgutil.Filter
+	if len(filters.Before) > 0 || len(filters.Since) > 0 {
+		imageFilters = append(imageFilters, imgutil.FilterByCreatedAt(ctx, client, filters.Before, filters.Since))
+	}
+	if filters.Until != "" {
+		imageFilters = append(imageFilters, imgutil.FilterUntil(filters.Until))
+	}
+	if len(filters.Labels) > 0 {
+		imageFilters = append(imageFilters, imgutil.FilterByLabel(ctx, client, filters.Labels))
+	}
+	if len(filters.Reference) > 0 {
+		imageFilters = append(imageFilters, imgutil.FilterByReference(filters.Reference))
+	}
+	if filters.Dangling != nil {
+		if *filters.Dangling {
+			imageFilters = append(imageFilters, imgutil.FilterDanglingImages())
+		} else {
+			imageFilters = append(imageFilters, imgutil.FilterTaggedImages())
+		}
+	}
+	return imgutil.ApplyFilters(imageList, imageFilters...)
+}
+
+// GetImageSnapshotters returns the snapshotters the image is unpacked into for the platform, or for the default
+// platform if none is given, sorted by name. The unpacks label the config of the image for the platform with a
+// reference to its snapshot in each snapshotter.
+func (w *NerdctlWrapper) GetImageSnapshotters(ctx context.Context, image images.Image, platform *ocispec.Platform) ([]string, error) {
+	img := containerd.NewImage(w.clientWrapper.client, image)
+	if platform != nil {
+		img = containerd.NewImageWithPlatform(w.clientWrapper.client, image, platforms.OnlyStrict(*platform))
+	}
+	config, err := img.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := w.clientWrapper.client.ContentStore().Info(ctx, config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	var snapshotters []string
+	for label := range info.Labels {
+		snapshotter, ok := strings.CutPrefix(label, snapshotRefLabelPrefix)
+		if !ok {
+			continue
+		}
+		// the snapshot may have been removed since the image was unpacked.
+		if unpacked, err := img.IsUnpacked(ctx, snapshotter); err == nil && unpacked {
+			snapshotters = append(snapshotters, snapshotter)
+		}
+	}
+	sort.Strings(snapshotters)
+	return snapshotters, nil
+}
+
+// GetImageLayerSizes returns the size of each unpacked layer of the image in the configured snapshotter,
+// keyed by the chain ID of the layer's snapshot.
+func (w *NerdctlWrapper) GetImageLayerSizes(ctx context.Context, image images.Image) (map[string]int64, error) {
+	diffIDs, err := containerd.NewImage(w.clientWrapper.client, image).RootFS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshotter := containerdutil.SnapshotService(w.clientWrapper.client, w.globalOptions.Snapshotter)
+	sizes := make(map[string]int64, len(diffIDs))
+	for _, chainID := range identity.ChainIDs(diffIDs) {
+		usage, err := snapshotter.Usage(ctx, chainID.String())
+		if err != nil {
+			return nil, err
+		}
+		sizes[chainID.String()] = usage.Size
+	}
+	return sizes, nil
+}
+
+// GetImageConfig returns the config of the image for the default platform,
+// which is the config of the matching manifest for multi-platform images.
+func (w *NerdctlWrapper) GetImageConfig(ctx context.Context, image images.Image) (*ocispec.Image, error) {
+	config, _, err := imgutil.ReadImageConfig(ctx, containerd.NewImage(w.clientWrapper.client, image))
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
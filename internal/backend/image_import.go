@@ -0,0 +1,185 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ImportImage creates a single-layer image from a rootfs tarball, which may be compressed, and unpacks it
+// in the configured snapshotter. The image is named ref, or after its manifest digest if ref is empty.
+//
+// Adapted from https://github.com/containerd/nerdctl/blob/v2.2.2/pkg/cmd/image/import.go, which does not
+// allow setting the image config.
+func (w *NerdctlWrapper) ImportImage(ctx context.Context, rootfs io.Reader, ref string, platform ocispec.Platform, config ocispec.ImageConfig, message string) (*images.Image, error) {
+	client := w.clientWrapper.client
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	defer done(ctx)
+
+	layerDesc, diffID, err := writeImportLayer(ctx, client.ContentStore(), rootfs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the rootfs layer: %w", err)
+	}
+
+	created := time.Now().UTC()
+	imgConfig := ocispec.Image{
+		Created:  &created,
+		Platform: platform,
+		Config:   config,
+		RootFS: ocispec.RootFS{
+			Type:    "layers",
+			DiffIDs: []digest.Digest{diffID},
+		},
+		History: []ocispec.History{{
+			Created: &created,
+			Comment: message,
+		}},
+	}
+	manifestDesc, err := writeImportManifest(ctx, client.ContentStore(), w.globalOptions.Snapshotter, imgConfig, layerDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref == "" {
+		ref = manifestDesc.Digest.String()
+	}
+	img := images.Image{
+		Name:      ref,
+		Target:    manifestDesc,
+		CreatedAt: created,
+	}
+	if _, err := client.ImageService().Update(ctx, img); err != nil {
+		if !errdefs.IsNotFound(err) {
+			return nil, err
+		}
+		if _, err := client.ImageService().Create(ctx, img); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := containerd.NewImageWithPlatform(client, img, platforms.OnlyStrict(platform)).Unpack(ctx, w.globalOptions.Snapshotter); err != nil {
+		return nil, fmt.Errorf("failed to unpack the image: %w", err)
+	}
+	return &img, nil
+}
+
+// writeImportLayer writes the rootfs tarball as a gzip compressed layer to the content store,
+// and returns the descriptor and the diff ID of the layer.
+func writeImportLayer(ctx context.Context, cs content.Store, rootfs io.Reader) (ocispec.Descriptor, digest.Digest, error) {
+	decompressed, err := compression.DecompressStream(rootfs)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	defer decompressed.Close()
+
+	cw, err := content.OpenWriter(ctx, cs, content.WithRef(fmt.Sprintf("import-rootfs-%d", time.Now().UnixNano())))
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	defer cw.Close()
+	if err := cw.Truncate(0); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+
+	// the diff ID is the digest of the uncompressed layer, while the content store holds the compressed layer.
+	digester := digest.Canonical.Digester()
+	pr, pw := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(pw)
+		if _, err := io.Copy(gz, io.TeeReader(decompressed, digester.Hash())); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(gz.Close())
+	}()
+	size, err := io.Copy(cw, pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		return ocispec.Descriptor{}, "", err
+	}
+
+	diffID := digester.Digest()
+	labels := map[string]string{"containerd.io/uncompressed": diffID.String()}
+	if err := cw.Commit(ctx, size, "", content.WithLabels(labels)); err != nil && !errdefs.IsAlreadyExists(err) {
+		return ocispec.Descriptor{}, "", err
+	}
+	return ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2LayerGzip,
+		Digest:    cw.Digest(),
+		Size:      size,
+	}, diffID, nil
+}
+
+// writeImportManifest writes the config and the manifest of the imported image to the content store,
+// with the labels keeping its content and snapshot from being garbage collected.
+func writeImportManifest(ctx context.Context, cs content.Store, snapshotter string, config ocispec.Image, layer ocispec.Descriptor) (ocispec.Descriptor, error) {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	configDesc := ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2Config,
+		Digest:    digest.FromBytes(configJSON),
+		Size:      int64(len(configJSON)),
+	}
+	configLabels := map[string]string{}
+	if snapshotter != "" {
+		configLabels[fmt.Sprintf("containerd.io/gc.ref.snapshot.%s", snapshotter)] = identity.ChainID(config.RootFS.DiffIDs).String()
+	}
+	if err := content.WriteBlob(ctx, cs, configDesc.Digest.String(), bytes.NewReader(configJSON), configDesc,
+		content.WithLabels(configLabels)); err != nil && !errdefs.IsAlreadyExists(err) {
+		return ocispec.Descriptor{}, err
+	}
+
+	manifest := struct {
+		MediaType string `json:"mediaType,omitempty"`
+		ocispec.Manifest
+	}{
+		MediaType: images.MediaTypeDockerSchema2Manifest,
+		Manifest: ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			Config:    configDesc,
+			Layers:    []ocispec.Descriptor{layer},
+		},
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	manifestDesc := ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2Manifest,
+		Digest:    digest.FromBytes(manifestJSON),
+		Size:      int64(len(manifestJSON)),
+	}
+	manifestLabels := map[string]string{
+		"containerd.io/gc.ref.content.0": configDesc.Digest.String(),
+		"containerd.io/gc.ref.content.1": layer.Digest.String(),
+	}
+	if err := content.WriteBlob(ctx, cs, manifestDesc.Digest.String(), bytes.NewReader(manifestJSON), manifestDesc,
+		content.WithLabels(manifestLabels)); err != nil && !errdefs.IsAlreadyExists(err) {
+		return ocispec.Descriptor{}, err
+	}
+	return manifestDesc, nil
+}
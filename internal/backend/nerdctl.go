@@ -4,13 +4,18 @@
 package backend
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/containerd/imgcrypt/v2/images/encryption/parsehelpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containernetworking/cni/libcni"
 	"github.com/containernetworking/cni/pkg/invoke"
 	"github.com/containernetworking/cni/pkg/version"
+	encconfig "github.com/containers/ocicrypt/config"
+
+	"github.com/runfinch/finch-daemon/internal/verification"
 )
 
 type NerdctlWrapper struct {
@@ -19,6 +24,10 @@ type NerdctlWrapper struct {
 	nerdctlExe    string
 	netClient     *netutil.CNIEnv
 	CNI           *libcni.CNIConfig
+	// verificationPolicy is the image signature verification policy, which is nil if images are not verified.
+	verificationPolicy *verification.Policy
+	// decryptConfig holds the keys the encrypted layers of images are decrypted with, which is nil if none are configured.
+	decryptConfig *encconfig.DecryptConfig
 }
 
 func NewNerdctlWrapper(clientWrapper *ContainerdClientWrapper, options *types.GlobalCommandOptions) *NerdctlWrapper {
@@ -40,3 +49,19 @@ func NewNerdctlWrapper(clientWrapper *ContainerdClientWrapper, options *types.Gl
 			}),
 	}
 }
+
+// SetVerificationPolicy sets the signature verification policy of the images pulled by the daemon.
+func (w *NerdctlWrapper) SetVerificationPolicy(policy *verification.Policy) {
+	w.verificationPolicy = policy
+}
+
+// SetDecryptionKeys loads the private keys the encrypted layers of images are decrypted with when they are unpacked.
+// Each key is the path of a key file with an optional password separated by a colon, as `nerdctl image decrypt --key`.
+func (w *NerdctlWrapper) SetDecryptionKeys(keys []string) error {
+	cc, err := parsehelpers.CreateDecryptCryptoConfig(parsehelpers.EncArgs{Key: keys}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load the image decryption keys: %w", err)
+	}
+	w.decryptConfig = cc.DecryptConfig
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 type NerdctlNetworkSvc interface {
 	FilterNetworks(filterf func(networkConfig *netutil.NetworkConfig) bool) ([]*netutil.NetworkConfig, error)
 	AddNetworkList(ctx context.Context, netconflist *libcni.NetworkConfigList, conf *libcni.RuntimeConf) (cnitypes.Result, error)
+	GetNetworkListCachedResult(netconflist *libcni.NetworkConfigList, conf *libcni.RuntimeConf) (cnitypes.Result, error)
 	CreateNetwork(opts types.NetworkCreateOptions) (*netutil.NetworkConfig, error)
 	RemoveNetwork(networkConfig *netutil.NetworkConfig) error
 	InspectNetwork(ctx context.Context, networkConfig *netutil.NetworkConfig) (*dockercompat.Network, error)
@@ -49,6 +50,11 @@ func (w *NerdctlWrapper) AddNetworkList(ctx context.Context, netconflist *libcni
 	return w.CNI.AddNetworkList(ctx, netconflist, conf)
 }
 
+// GetNetworkListCachedResult returns the result cached by CNI when the container was attached to the network.
+func (w *NerdctlWrapper) GetNetworkListCachedResult(netconflist *libcni.NetworkConfigList, conf *libcni.RuntimeConf) (cnitypes.Result, error) {
+	return w.CNI.GetNetworkListCachedResult(netconflist, conf)
+}
+
 func (w *NerdctlWrapper) CreateNetwork(opts types.NetworkCreateOptions) (*netutil.NetworkConfig, error) {
 	return w.netClient.CreateNetwork(opts)
 }
@@ -0,0 +1,129 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package execstore records the exec instances of containers in a containerd extension of the container,
+// so that they stay inspectable after their process exits, until the container is removed.
+package execstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/backend"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+// Extension is the name of the containerd extension holding the exec instances of a container.
+const Extension = "finch-daemon/execs"
+
+// Exec is the record of an exec instance.
+type Exec struct {
+	ID          string
+	ContainerID string
+	Config      types.ExecConfig
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Running     bool
+	// ExitCode is nil until the process of the exec instance exits.
+	ExitCode *int
+	Pid      int
+}
+
+// Execs is the content of the Extension.
+type Execs struct {
+	Execs []*Exec
+}
+
+func init() {
+	typeurl.Register(&Execs{}, "github.com/runfinch/finch-daemon", "execs")
+}
+
+// mu serializes the updates of the Extension, which are read-modify-write operations.
+var mu sync.Mutex
+
+// Add records a new exec instance of the container.
+func Add(ctx context.Context, client backend.ContainerdClient, cont containerd.Container, exec *Exec) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	extensions, err := cont.Extensions(ctx)
+	if err != nil {
+		return err
+	}
+	execs, err := List(extensions)
+	if err != nil {
+		return err
+	}
+	return save(ctx, client, cont, append(execs, exec))
+}
+
+// Update applies fn to the exec instance with the id of the container and saves the result.
+func Update(ctx context.Context, client backend.ContainerdClient, cont containerd.Container, id string, fn func(*Exec)) (*Exec, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	extensions, err := cont.Extensions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	execs, err := List(extensions)
+	if err != nil {
+		return nil, err
+	}
+	for _, exec := range execs {
+		if exec.ID == id {
+			fn(exec)
+			return exec, save(ctx, client, cont, execs)
+		}
+	}
+	return nil, errdefs.NewNotFound(fmt.Errorf("no such exec instance: %s", id))
+}
+
+// List returns the exec instances recorded in the extensions of a container, in creation order.
+func List(extensions map[string]typeurl.Any) ([]*Exec, error) {
+	ext, ok := extensions[Extension]
+	if !ok || ext == nil {
+		return nil, nil
+	}
+	var execs Execs
+	if err := typeurl.UnmarshalTo(ext, &execs); err != nil {
+		return nil, err
+	}
+	return execs.Execs, nil
+}
+
+// Find returns the exec instance with the id and its container, searching the containers of the namespace of ctx.
+func Find(ctx context.Context, client backend.ContainerdClient, id string) (containerd.Container, *Exec, error) {
+	containers, err := client.GetContainers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, cont := range containers {
+		info, err := cont.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			continue
+		}
+		execs, err := List(info.Extensions)
+		if err != nil {
+			continue
+		}
+		for _, exec := range execs {
+			if exec.ID == id {
+				return cont, exec, nil
+			}
+		}
+	}
+	return nil, nil, errdefs.NewNotFound(fmt.Errorf("no such exec instance: %s", id))
+}
+
+// save saves the exec instances of the container.
+func save(ctx context.Context, client backend.ContainerdClient, cont containerd.Container, execs []*Exec) error {
+	return client.UpdateContainerExtension(ctx, cont.ID(), Extension, &Execs{Execs: execs})
+}
@@ -0,0 +1,148 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package execstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/typeurl/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_container"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+// TestExecStore is the entry point of the execstore package's unit tests using ginkgo.
+func TestExecStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UnitTests - Exec Store")
+}
+
+var _ = Describe("Exec store", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		cdClient *mocks_backend.MockContainerdClient
+		con      *mocks_container.MockContainer
+		con2     *mocks_container.MockContainer
+		saved    []*Exec
+	)
+	extensionsOf := func(execs ...*Exec) map[string]typeurl.Any {
+		ext, err := typeurl.MarshalAny(&Execs{Execs: execs})
+		Expect(err).ShouldNot(HaveOccurred())
+		return map[string]typeurl.Any{Extension: ext}
+	}
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockCtrl = gomock.NewController(GinkgoT())
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		con = mocks_container.NewMockContainer(mockCtrl)
+		con2 = mocks_container.NewMockContainer(mockCtrl)
+		con.EXPECT().ID().Return("123").AnyTimes()
+		saved = nil
+	})
+	expectSave := func() {
+		cdClient.EXPECT().UpdateContainerExtension(ctx, "123", Extension, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, ext any) error {
+				saved = ext.(*Execs).Execs
+				return nil
+			})
+	}
+	Context("Add", func() {
+		It("should append the exec instance to the exec instances of the container", func() {
+			con.EXPECT().Extensions(ctx).Return(extensionsOf(&Exec{ID: "exec1", ContainerID: "123"}), nil)
+			expectSave()
+
+			Expect(Add(ctx, cdClient, con, &Exec{ID: "exec2", ContainerID: "123"})).Should(Succeed())
+			Expect(saved).Should(Equal([]*Exec{
+				{ID: "exec1", ContainerID: "123"},
+				{ID: "exec2", ContainerID: "123"},
+			}))
+		})
+		It("should keep the exec instances whose process exited", func() {
+			exitCode := 0
+			con.EXPECT().Extensions(ctx).Return(extensionsOf(
+				&Exec{ID: "exec1", ContainerID: "123", ExitCode: &exitCode, FinishedAt: time.Now().Add(-time.Hour)},
+				&Exec{ID: "exec2", ContainerID: "123", ExitCode: &exitCode, FinishedAt: time.Now().Add(-time.Minute)},
+				&Exec{ID: "exec3", ContainerID: "123", Running: true},
+			), nil)
+			expectSave()
+
+			Expect(Add(ctx, cdClient, con, &Exec{ID: "exec4", ContainerID: "123"})).Should(Succeed())
+			Expect(saved).Should(HaveLen(4))
+			Expect(saved[0].ID).Should(Equal("exec1"))
+			Expect(saved[1].ID).Should(Equal("exec2"))
+			Expect(saved[2].ID).Should(Equal("exec3"))
+			Expect(saved[3].ID).Should(Equal("exec4"))
+		})
+		It("should pass through errors from getting the extensions", func() {
+			con.EXPECT().Extensions(ctx).Return(nil, errors.New("extensions error"))
+
+			Expect(Add(ctx, cdClient, con, &Exec{ID: "exec1"})).Should(MatchError("extensions error"))
+		})
+	})
+	Context("Update", func() {
+		It("should save the updated exec instance", func() {
+			con.EXPECT().Extensions(ctx).Return(extensionsOf(
+				&Exec{ID: "exec1", ContainerID: "123"},
+				&Exec{ID: "exec2", ContainerID: "123"},
+			), nil)
+			expectSave()
+
+			exec, err := Update(ctx, cdClient, con, "exec2", func(e *Exec) {
+				e.Running = true
+				e.Pid = 456
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(exec).Should(Equal(&Exec{ID: "exec2", ContainerID: "123", Running: true, Pid: 456}))
+			Expect(saved).Should(Equal([]*Exec{
+				{ID: "exec1", ContainerID: "123"},
+				{ID: "exec2", ContainerID: "123", Running: true, Pid: 456},
+			}))
+		})
+		It("should return a NotFound error if the exec instance is not found", func() {
+			con.EXPECT().Extensions(ctx).Return(nil, nil)
+
+			_, err := Update(ctx, cdClient, con, "exec1", func(*Exec) {})
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+		})
+	})
+	Context("List", func() {
+		It("should return no exec instances for containers without the extension", func() {
+			Expect(List(nil)).Should(BeEmpty())
+		})
+	})
+	Context("Find", func() {
+		It("should return the exec instance and its container", func() {
+			cdClient.EXPECT().GetContainers(ctx).Return([]containerd.Container{con, con2}, nil)
+			con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{}, nil)
+			con2.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{
+				Extensions: extensionsOf(&Exec{ID: "exec1", ContainerID: "456"}),
+			}, nil)
+
+			cont, exec, err := Find(ctx, cdClient, "exec1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(cont).Should(Equal(con2))
+			Expect(exec).Should(Equal(&Exec{ID: "exec1", ContainerID: "456"}))
+		})
+		It("should return a NotFound error if the exec instance is not found", func() {
+			cdClient.EXPECT().GetContainers(ctx).Return([]containerd.Container{con}, nil)
+			con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{
+				Extensions: extensionsOf(&Exec{ID: "exec1", ContainerID: "123"}),
+			}, nil)
+
+			_, _, err := Find(ctx, cdClient, "exec2")
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+			Expect(err.Error()).Should(Equal("no such exec instance: exec2"))
+		})
+	})
+})
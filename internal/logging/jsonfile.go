@@ -0,0 +1,237 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+	nclogging "github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/logging/jsonfile"
+	"github.com/docker/go-units"
+)
+
+const gzipExt = ".gz"
+
+// jsonFileOpts holds the rotation options of the json-file driver.
+type jsonFileOpts struct {
+	// maxSize is the size in bytes at which the log file is rotated, the log file is never rotated when it is 0.
+	maxSize int64
+	// maxFile is the maximum number of log files, including the current one.
+	maxFile  int
+	compress bool
+}
+
+// parseJSONFileOpts parses the rotation options of the json-file driver.
+// Adapted from https://github.com/moby/moby/blob/v24.0.2/daemon/logger/jsonfilelog/jsonfilelog.go#L42-L78
+func parseJSONFileOpts(opts map[string]string) (*jsonFileOpts, error) {
+	parsed := &jsonFileOpts{maxFile: 1}
+	if maxSize, ok := opts[nclogging.MaxSize]; ok {
+		size, err := units.FromHumanSize(maxSize)
+		if err != nil {
+			return nil, err
+		}
+		if size <= 0 {
+			return nil, fmt.Errorf("max-size must be a positive number")
+		}
+		parsed.maxSize = size
+	}
+	if maxFile, ok := opts[nclogging.MaxFile]; ok {
+		n, err := strconv.Atoi(maxFile)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("max-file cannot be less than 1")
+		}
+		parsed.maxFile = n
+	}
+	if compress, ok := opts[Compress]; ok {
+		c, err := strconv.ParseBool(compress)
+		if err != nil {
+			return nil, err
+		}
+		if c && (parsed.maxFile == 1 || parsed.maxSize == 0) {
+			return nil, fmt.Errorf("compress cannot be true when max-file is less than 2 or max-size is not set")
+		}
+		parsed.compress = c
+	}
+	return parsed, nil
+}
+
+func validateJSONFileOpts(opts map[string]string) error {
+	_, err := parseJSONFileOpts(opts)
+	return err
+}
+
+// jsonFileLogger is the json-file log driver, run by the logging binary of a container.
+type jsonFileLogger struct {
+	opts map[string]string
+	file *rotatingFile
+}
+
+func (l *jsonFileLogger) Init(dataStore, ns, id string) error {
+	path := jsonfile.Path(dataStore, ns, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (l *jsonFileLogger) PreProcess(_ context.Context, dataStore string, config *logging.Config) error {
+	opts, err := parseJSONFileOpts(l.opts)
+	if err != nil {
+		return err
+	}
+	l.file, err = newRotatingFile(jsonfile.Path(dataStore, config.Namespace, config.ID), opts)
+	return err
+}
+
+func (l *jsonFileLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	return jsonfile.Encode(stdout, stderr, l.file)
+}
+
+func (l *jsonFileLogger) PostProcess() error {
+	return l.file.Close()
+}
+
+// rotatingFile is a log file which is rotated like docker once it reaches the maximum size. The rotated files are
+// named after the log file with the suffixes .1, the most recent, to .<maxFile-1>, and are gzipped in the background
+// when compress is set. When maxFile is 1, the log file is truncated instead.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	opts *jsonFileOpts
+	f    *os.File
+	size int64
+	// compressing tracks the compression of the most recently rotated file.
+	compressing sync.WaitGroup
+}
+
+func newRotatingFile(path string, opts *jsonFileOpts) (*rotatingFile, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &rotatingFile{path: path, opts: opts, f: f, size: fi.Size()}, nil
+}
+
+// Write writes p to the log file, rotating it first if p does not fit in it. Log entries are never split across
+// log files, as jsonfile.Encode writes each of them with a single call.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opts.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.opts.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, fmt.Errorf("failed to rotate log file %s: %w", r.path, err)
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file, and waits for the compression of the rotated file to finish.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.f.Close()
+	r.compressing.Wait()
+	return err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.opts.maxFile > 1 {
+		ext := ""
+		if r.opts.compress {
+			ext = gzipExt
+			// the previously rotated file is shifted once it is compressed, which only blocks the writes
+			// if the log file fills up faster than it is compressed.
+			r.compressing.Wait()
+		}
+		// shift the rotated files, which overwrites the oldest one
+		for i := r.opts.maxFile - 1; i > 1; i-- {
+			err := os.Rename(rotatedPath(r.path, i-1)+ext, rotatedPath(r.path, i)+ext)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		if err := os.Rename(r.path, rotatedPath(r.path, 1)); err != nil {
+			return err
+		}
+		if r.opts.compress {
+			r.compressing.Add(1)
+			go func(path string) {
+				defer r.compressing.Done()
+				if err := compressFile(path); err != nil {
+					log.L.WithError(err).Errorf("failed to compress rotated log file %s", path)
+				}
+			}(rotatedPath(r.path, 1))
+		}
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	r.f, r.size = f, 0
+	return nil
+}
+
+func rotatedPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// compressFile replaces the file at path with its gzipped version, at path with the .gz extension.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := path + gzipExt + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+gzipExt); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
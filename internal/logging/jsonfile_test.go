@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("json-file rotation", func() {
+	var path string
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "id-json.log")
+	})
+	write := func(r *rotatingFile, lines ...string) {
+		for _, line := range lines {
+			_, err := r.Write([]byte(line + "\n"))
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+	}
+	readFile := func(name string) string {
+		b, err := os.ReadFile(name)
+		Expect(err).ShouldNot(HaveOccurred())
+		if strings.HasSuffix(name, gzipExt) {
+			zr, err := gzip.NewReader(bytes.NewReader(b))
+			Expect(err).ShouldNot(HaveOccurred())
+			b, err = io.ReadAll(zr)
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+		return string(b)
+	}
+	It("should not rotate the log file without max-size", func() {
+		r, err := newRotatingFile(path, &jsonFileOpts{maxFile: 3})
+		Expect(err).ShouldNot(HaveOccurred())
+		write(r, "a", "b", "c")
+		Expect(r.Close()).Should(Succeed())
+		Expect(readFile(path)).Should(Equal("a\nb\nc\n"))
+		Expect(rotatedPath(path, 1)).ShouldNot(BeAnExistingFile())
+	})
+	It("should keep max-file log files", func() {
+		r, err := newRotatingFile(path, &jsonFileOpts{maxSize: 4, maxFile: 3})
+		Expect(err).ShouldNot(HaveOccurred())
+		write(r, "a", "b", "c", "d", "e")
+		Expect(r.Close()).Should(Succeed())
+		Expect(readFile(path)).Should(Equal("e\n"))
+		Expect(readFile(rotatedPath(path, 1))).Should(Equal("c\nd\n"))
+		Expect(readFile(rotatedPath(path, 2))).Should(Equal("a\nb\n"))
+		Expect(rotatedPath(path, 3)).ShouldNot(BeAnExistingFile())
+
+		r, err = newRotatingFile(path, &jsonFileOpts{maxSize: 4, maxFile: 3})
+		Expect(err).ShouldNot(HaveOccurred())
+		write(r, "f", "g")
+		Expect(r.Close()).Should(Succeed())
+		Expect(readFile(path)).Should(Equal("g\n"))
+		Expect(readFile(rotatedPath(path, 1))).Should(Equal("e\nf\n"))
+		Expect(readFile(rotatedPath(path, 2))).Should(Equal("c\nd\n"))
+	})
+	It("should truncate the log file when max-file is 1", func() {
+		r, err := newRotatingFile(path, &jsonFileOpts{maxSize: 4, maxFile: 1})
+		Expect(err).ShouldNot(HaveOccurred())
+		write(r, "a", "b", "c")
+		Expect(r.Close()).Should(Succeed())
+		Expect(readFile(path)).Should(Equal("c\n"))
+		Expect(rotatedPath(path, 1)).ShouldNot(BeAnExistingFile())
+	})
+	It("should compress the rotated log files", func() {
+		r, err := newRotatingFile(path, &jsonFileOpts{maxSize: 4, maxFile: 3, compress: true})
+		Expect(err).ShouldNot(HaveOccurred())
+		write(r, "a", "b", "c", "d", "e")
+		Expect(r.Close()).Should(Succeed())
+		Expect(readFile(path)).Should(Equal("e\n"))
+		Expect(readFile(rotatedPath(path, 1) + gzipExt)).Should(Equal("c\nd\n"))
+		Expect(readFile(rotatedPath(path, 2) + gzipExt)).Should(Equal("a\nb\n"))
+		Expect(rotatedPath(path, 1)).ShouldNot(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,36 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logging implements the json-file log driver of the daemon. It replaces the json-file driver of nerdctl
+// to rotate and compress the log files like docker, and its log viewer to read the logs across the rotated files.
+package logging
+
+import (
+	nclogging "github.com/containerd/nerdctl/v2/pkg/logging"
+)
+
+const (
+	// MagicArgv1 is the first argument of the daemon binary when it is run by containerd as a logging binary.
+	MagicArgv1 = nclogging.MagicArgv1
+
+	jsonFileDriver = "json-file"
+
+	// Compress enables the compression of the rotated json-file log files.
+	Compress = "compress"
+	// LabelsRegex and EnvRegex select the labels and env of a container to show in its log details.
+	LabelsRegex = "labels-regex"
+	EnvRegex    = "env-regex"
+)
+
+func init() {
+	nclogging.RegisterDriver(jsonFileDriver, func(opts map[string]string, _ string) (nclogging.Driver, error) {
+		return &jsonFileLogger{opts: opts}, nil
+	}, validateJSONFileOpts)
+	nclogging.RegisterLogViewer(jsonFileDriver, viewLogsJSONFile)
+}
+
+// Main is the entrypoint of the daemon binary when it is run by containerd as the logging binary of a container,
+// i.e. when its first argument is MagicArgv1.
+func Main(argv2 string) error {
+	return nclogging.Main(argv2)
+}
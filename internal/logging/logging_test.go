@@ -0,0 +1,49 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// TestLogging is the entry point of the logging package's unit tests using ginkgo.
+func TestLogging(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UnitTests - Logging")
+}
+
+var _ = Describe("ValidateLogConfig", func() {
+	It("should accept the supported drivers and options", func() {
+		Expect(ValidateLogConfig("", nil)).Should(Succeed())
+		Expect(ValidateLogConfig("none", nil)).Should(Succeed())
+		Expect(ValidateLogConfig("json-file", map[string]string{
+			"max-size": "10m",
+			"max-file": "3",
+			"compress": "true",
+			"labels":   "com.example.team",
+		})).Should(Succeed())
+		Expect(ValidateLogConfig("journald", map[string]string{"tag": "app"})).Should(Succeed())
+	})
+	It("should pass through the drivers which are URIs", func() {
+		Expect(ValidateLogConfig("binary:///usr/local/bin/logger", map[string]string{"foo": "bar"})).Should(Succeed())
+		Expect(ValidateLogConfig("fifo:///tmp/log", nil)).Should(Succeed())
+	})
+	It("should reject unknown drivers", func() {
+		Expect(ValidateLogConfig("local", nil)).Should(MatchError(ContainSubstring("no log driver named 'local'")))
+	})
+	It("should reject unknown options", func() {
+		Expect(ValidateLogConfig("json-file", map[string]string{"log-path": "/tmp/log"})).Should(
+			MatchError("unknown log opt 'log-path' for json-file log driver"))
+		Expect(ValidateLogConfig("none", map[string]string{"max-size": "10m"})).ShouldNot(Succeed())
+	})
+	It("should reject invalid json-file options", func() {
+		Expect(ValidateLogConfig("json-file", map[string]string{"max-size": "-1"})).ShouldNot(Succeed())
+		Expect(ValidateLogConfig("json-file", map[string]string{"max-file": "0"})).ShouldNot(Succeed())
+		Expect(ValidateLogConfig("json-file", map[string]string{"max-size": "10m", "compress": "true"})).ShouldNot(Succeed())
+		Expect(ValidateLogConfig("json-file", map[string]string{"max-file": "2", "compress": "true"})).ShouldNot(Succeed())
+	})
+})
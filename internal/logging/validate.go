@@ -0,0 +1,62 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+
+	nclogging "github.com/containerd/nerdctl/v2/pkg/logging"
+)
+
+// driverLogOpts lists the log drivers supported by the daemon and their options.
+var driverLogOpts = map[string][]string{
+	"none": nil,
+	jsonFileDriver: {
+		nclogging.MaxSize,
+		nclogging.MaxFile,
+		Compress,
+		nclogging.Labels,
+		LabelsRegex,
+		nclogging.Env,
+		EnvRegex,
+		nclogging.Tag,
+	},
+	"journald": nclogging.JournalDriverLogOpts,
+	"fluentd":  nclogging.FluentdLogOpts,
+	"syslog": {
+		"syslog-address",
+		"syslog-facility",
+		"syslog-tls-ca-cert",
+		"syslog-tls-cert",
+		"syslog-tls-key",
+		"syslog-tls-skip-verify",
+		"syslog-format",
+		nclogging.Tag,
+	},
+}
+
+// ValidateLogConfig returns an error if driver is not a supported log driver, or if opts contains options which
+// are unknown to driver or have invalid values. An empty driver stands for the default json-file driver.
+// Drivers which are URIs, e.g. binary:///usr/local/bin/logger, are passed through as the log URI of the container
+// like nerdctl does, so their options are not validated.
+func ValidateLogConfig(driver string, opts map[string]string) error {
+	if driver == "" {
+		driver = jsonFileDriver
+	}
+	if u, err := url.Parse(driver); err == nil && u.Scheme != "" {
+		return nil
+	}
+	supported, ok := driverLogOpts[driver]
+	if !ok {
+		return fmt.Errorf("logger: no log driver named '%s' is registered", driver)
+	}
+	for key := range opts {
+		if !slices.Contains(supported, key) {
+			return fmt.Errorf("unknown log opt '%s' for %s log driver", key, driver)
+		}
+	}
+	return nclogging.ValidateLogOpts(driver, opts)
+}
@@ -0,0 +1,358 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	nclogging "github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/logging/jsonfile"
+	"github.com/fsnotify/fsnotify"
+)
+
+// rotatedSuffix matches the suffixes of the rotated log files, as written by the daemon and by nerdctl.
+var rotatedSuffix = regexp.MustCompile(`^\.(\d+)(\.gz)?$`)
+
+// decodeChunkSize is the amount of log entries decoded at once.
+const decodeChunkSize = 64 * 1024
+
+// viewLogsJSONFile writes the logs of the json-file driver to stdout and stderr, starting from the oldest rotated
+// log file. When following the logs, it keeps reading the log file through its rotations until stopChannel
+// receives a signal.
+func viewLogsJSONFile(lvopts nclogging.LogViewOptions, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	path := jsonfile.Path(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+	decode := func(r io.Reader) error {
+		_, err := jsonfile.Decode(stdout, stderr, r, lvopts.Timestamps, lvopts.Since, lvopts.Until)
+		return err
+	}
+
+	var watcher *fsnotify.Watcher
+	if lvopts.Follow {
+		// watch before reading, so that no write to the log file can be missed
+		var err error
+		if watcher, err = fsnotify.NewWatcher(); err != nil {
+			return err
+		}
+		defer watcher.Close()
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return err
+		}
+	}
+
+	rotated, err := rotatedLogFiles(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil && lvopts.Follow && errors.Is(err, os.ErrNotExist) {
+		// the log file is created when the container starts, or is being rotated.
+		if f, err = waitForLogFile(path, watcher, stopChannel); err == nil && f == nil {
+			return nil
+		}
+		if err == nil {
+			rotated, err = rotatedLogFiles(path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	files := append(rotated, path)
+	first, skip, err := tailStart(files, lvopts.Tail)
+	if err != nil {
+		return err
+	}
+	for i := first; i < len(rotated); i++ {
+		if err := readLogFile(rotated[i], skip, decode); err != nil {
+			return err
+		}
+		skip = 0
+	}
+
+	lr := newLineReader(f)
+	if err := lr.skip(skip); err != nil {
+		return err
+	}
+	if err := lr.decodeAvailable(decode); err != nil || !lvopts.Follow {
+		return err
+	}
+
+	for {
+		select {
+		case <-stopChannel:
+			// print the last logs of an exited container
+			return lr.decodeAvailable(decode)
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+		case err := <-watcher.Errors:
+			return err
+		}
+
+		// the log file may have been rotated or truncated since it was opened
+		fi, err := os.Stat(path)
+		if err != nil {
+			// the log file is being rotated
+			continue
+		}
+		current, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if !os.SameFile(fi, current) {
+			// finish reading the rotated file before reading the new one
+			if err := lr.decodeAvailable(decode); err != nil {
+				return err
+			}
+			newFile, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			f.Close()
+			f = newFile
+			lr = newLineReader(f)
+		} else if pos, err := f.Seek(0, io.SeekCurrent); err == nil && fi.Size() < pos {
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			lr = newLineReader(f)
+		}
+		if err := lr.decodeAvailable(decode); err != nil {
+			return err
+		}
+	}
+}
+
+// waitForLogFile waits for the log file at path to be created and opens it. It returns a nil file if stopChannel
+// receives a signal first.
+func waitForLogFile(path string, watcher *fsnotify.Watcher, stopChannel chan os.Signal) (*os.File, error) {
+	for {
+		select {
+		case <-stopChannel:
+			return nil, nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil, nil
+			}
+		case err := <-watcher.Errors:
+			return nil, err
+		}
+		f, err := os.Open(path)
+		if !errors.Is(err, os.ErrNotExist) {
+			return f, err
+		}
+	}
+}
+
+// rotatedLogFiles returns the rotated files of the log file at path, from the oldest to the most recent.
+func rotatedLogFiles(path string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	type rotatedFile struct {
+		path  string
+		index int
+		mtime int64
+	}
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	var files []rotatedFile
+	base := filepath.Base(path)
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), base)
+		if !ok {
+			continue
+		}
+		m := rotatedSuffix.FindStringSubmatch(suffix)
+		// a rotated file is briefly kept along with its compressed version.
+		if m == nil || (m[2] == "" && names[entry.Name()+gzipExt]) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		files = append(files, rotatedFile{
+			path:  filepath.Join(filepath.Dir(path), entry.Name()),
+			index: index,
+			mtime: info.ModTime().UnixNano(),
+		})
+	}
+	// the daemon numbers the rotated files from the most recent, while nerdctl numbers them from the oldest,
+	// so they are ordered by their modification time.
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].mtime != files[j].mtime {
+			return files[i].mtime < files[j].mtime
+		}
+		return files[i].index > files[j].index
+	})
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.path)
+	}
+	return paths, nil
+}
+
+// tailStart returns the index of the first of files to read to get their last n lines, and the number of lines
+// to skip in it. All the lines are read when n is 0.
+func tailStart(files []string, n uint) (int, int, error) {
+	if n == 0 {
+		return 0, 0, nil
+	}
+	remaining := int(n)
+	for i := len(files) - 1; i >= 0; i-- {
+		count, err := countLines(files[i])
+		if err != nil {
+			return 0, 0, err
+		}
+		if count >= remaining {
+			return i, count - remaining, nil
+		}
+		remaining -= count
+	}
+	return 0, 0, nil
+}
+
+func countLines(path string) (int, error) {
+	r, err := openLogFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	count := 0
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		count += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// readLogFile decodes the log entries of a rotated log file, after skipping its first lines.
+func readLogFile(path string, skip int, decode func(io.Reader) error) error {
+	r, err := openLogFile(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	lr := newLineReader(r)
+	if err := lr.skip(skip); err != nil {
+		return err
+	}
+	return lr.decodeAvailable(decode)
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.f.Close()
+}
+
+// openLogFile opens a log file, decompressing it if it is gzipped. A rotated file which has been compressed
+// since it was listed is opened in its compressed version.
+func openLogFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && !strings.HasSuffix(path, gzipExt) {
+		path += gzipExt
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, gzipExt) {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: zr, f: f}, nil
+}
+
+// lineReader reads the complete lines of a log file which may still be written to, keeping the last partial line
+// until the rest of it is written.
+type lineReader struct {
+	r       *bufio.Reader
+	partial []byte
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next complete line, or nil when no complete line is available yet.
+func (lr *lineReader) next() ([]byte, error) {
+	line, err := lr.r.ReadBytes('\n')
+	if err == io.EOF {
+		lr.partial = append(lr.partial, line...)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(lr.partial) > 0 {
+		line = append(lr.partial, line...)
+		lr.partial = nil
+	}
+	return line, nil
+}
+
+func (lr *lineReader) skip(n int) error {
+	for ; n > 0; n-- {
+		line, err := lr.next()
+		if err != nil || line == nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeAvailable decodes the complete lines available, in chunks.
+func (lr *lineReader) decodeAvailable(decode func(io.Reader) error) error {
+	var chunk bytes.Buffer
+	for {
+		line, err := lr.next()
+		if err != nil {
+			return err
+		}
+		if line != nil {
+			chunk.Write(line)
+		}
+		if chunk.Len() > 0 && (line == nil || chunk.Len() >= decodeChunkSize) {
+			if err := decode(&chunk); err != nil {
+				return err
+			}
+			chunk.Reset()
+		}
+		if line == nil {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	nclogging "github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/logging/jsonfile"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("json-file log viewer", func() {
+	var (
+		lvopts         nclogging.LogViewOptions
+		path           string
+		stdout, stderr *bytes.Buffer
+		stopChannel    chan os.Signal
+	)
+	BeforeEach(func() {
+		lvopts = nclogging.LogViewOptions{
+			ContainerID:       "id",
+			Namespace:         "finch",
+			DatastoreRootPath: GinkgoT().TempDir(),
+		}
+		path = jsonfile.Path(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o700)).Should(Succeed())
+		stdout, stderr = new(bytes.Buffer), new(bytes.Buffer)
+		stopChannel = make(chan os.Signal, 1)
+	})
+	entries := func(stream string, logs ...string) string {
+		var buf bytes.Buffer
+		for _, log := range logs {
+			b, err := json.Marshal(&jsonfile.Entry{Stream: stream, Log: log + "\n", Time: time.Now()})
+			Expect(err).ShouldNot(HaveOccurred())
+			buf.Write(append(b, '\n'))
+		}
+		return buf.String()
+	}
+	// rotate writes the logs to a log file of at most 2 entries, keeping up to 3 compressed log files.
+	rotate := func(logs ...string) {
+		r, err := newRotatingFile(path, &jsonFileOpts{maxSize: int64(2 * len(entries("stdout", "x"))), maxFile: 3, compress: true})
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, log := range logs {
+			_, err := r.Write([]byte(entries("stdout", log)))
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+		Expect(r.Close()).Should(Succeed())
+	}
+	It("should read the rotated log files in order", func() {
+		rotate("a", "b", "c", "d", "e")
+		Expect(rotatedPath(path, 2) + gzipExt).Should(BeAnExistingFile())
+
+		Expect(viewLogsJSONFile(lvopts, stdout, stderr, stopChannel)).Should(Succeed())
+		Expect(stdout.String()).Should(Equal("a\nb\nc\nd\ne\n"))
+		Expect(stderr.String()).Should(BeEmpty())
+	})
+	It("should tail the logs across the rotated log files", func() {
+		rotate("a", "b", "c", "d", "e")
+		lvopts.Tail = 4
+
+		Expect(viewLogsJSONFile(lvopts, stdout, stderr, stopChannel)).Should(Succeed())
+		Expect(stdout.String()).Should(Equal("b\nc\nd\ne\n"))
+	})
+	It("should follow the logs through the rotations of the log file", func() {
+		rotate("a")
+		lvopts.Follow = true
+		done := make(chan error)
+		var out safeBuffer
+		go func() {
+			done <- viewLogsJSONFile(lvopts, &out, stderr, stopChannel)
+		}()
+		Eventually(out.String).Should(Equal("a\n"))
+
+		rotate("b", "c", "d")
+		Eventually(out.String).Should(Equal("a\nb\nc\nd\n"))
+
+		stopChannel <- os.Interrupt
+		Eventually(done).Should(Receive(BeNil()))
+	})
+	It("should wait for the log file to be created when following the logs", func() {
+		lvopts.Follow = true
+		done := make(chan error)
+		var out safeBuffer
+		go func() {
+			done <- viewLogsJSONFile(lvopts, &out, stderr, stopChannel)
+		}()
+		Consistently(done, "100ms").ShouldNot(Receive())
+
+		rotate("a")
+		Eventually(out.String).Should(Equal("a\n"))
+
+		stopChannel <- os.Interrupt
+		Eventually(done).Should(Receive(BeNil()))
+	})
+	It("should return an error if the log file does not exist without following the logs", func() {
+		Expect(viewLogsJSONFile(lvopts, stdout, stderr, stopChannel)).Should(MatchError(os.ErrNotExist))
+	})
+	It("should not read a rotated log file twice while it is compressed", func() {
+		rotate("a", "b", "c")
+		Expect(os.WriteFile(rotatedPath(path, 1), []byte(entries("stdout", "a", "b")), 0o600)).Should(Succeed())
+
+		Expect(viewLogsJSONFile(lvopts, stdout, stderr, stopChannel)).Should(Succeed())
+		Expect(stdout.String()).Should(Equal("a\nb\nc\n"))
+	})
+	It("should only return complete log entries", func() {
+		content := entries("stderr", "a") + `{"log":"b`
+		Expect(os.WriteFile(path, []byte(content), 0o600)).Should(Succeed())
+
+		Expect(viewLogsJSONFile(lvopts, stdout, stderr, stopChannel)).Should(Succeed())
+		Expect(stderr.String()).Should(Equal("a\n"))
+	})
+})
+
+// safeBuffer is a bytes.Buffer that can be written and read concurrently.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	cerrdefs "github.com/containerd/errdefs"
 	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/api/types/cri"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
@@ -49,14 +51,14 @@ func (s *service) Attach(ctx context.Context, cid string, opts *types.AttachOpti
 		errStream = stdcopy.NewStdWriter(errStream, stdcopy.Stderr)
 		outStream = stdcopy.NewStdWriter(outStream, stdcopy.Stdout)
 	}
-	// TODO: implement stdin for a full attach implementation
-	var (
-		// stdin io.Reader
-		stdout, stderr io.Writer
-	)
-	// if opts.UseStdin {
-	// stdin = inStream
-	// }
+	var stdout, stderr io.Writer
+	if opts.UseStdin && opts.Stdin != nil {
+		detach, err := s.attachStdin(ctx, con, opts.Stdin)
+		if err != nil {
+			return err
+		}
+		defer detach()
+	}
 	if opts.UseStdout {
 		stdout = outStream
 	}
@@ -82,7 +84,7 @@ func (s *service) Attach(ctx context.Context, cid string, opts *types.AttachOpti
 		Since:      since,
 		Until:      "",
 	}
-	err = s.attachLogs(ctx, con, logOpts, stopChannel, printSuccessResp)
+	err = s.attachLogs(ctx, con, logOpts, stopChannel, printSuccessResp, false)
 	if err != nil {
 		s.logger.Debugf("failed to attach to the container: %s", cid)
 		return err
@@ -90,15 +92,39 @@ func (s *service) Attach(ctx context.Context, cid string, opts *types.AttachOpti
 	return nil
 }
 
+// attachStdin forwards stdin to the stdin of the task of the container like nerdctl attach, which requires the
+// task to have been created with a stdin. Otherwise, stdin is discarded. It returns a function detaching stdin.
+func (s *service) attachStdin(ctx context.Context, con containerd.Container, stdin io.Reader) (func(), error) {
+	task, err := con.Task(ctx, cio.NewAttach(cio.WithStreams(stdin, nil, nil)))
+	if err != nil && !cerrdefs.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to attach to the stdin of the container: %w", err)
+	}
+	var taskIO cio.IO
+	if err == nil {
+		taskIO = task.IO()
+	}
+	if taskIO == nil || taskIO.Config().Stdin == "" {
+		go io.Copy(io.Discard, stdin)
+	}
+	return func() {
+		if taskIO != nil {
+			taskIO.Cancel()
+			taskIO.Close()
+		}
+	}, nil
+}
+
 // attachLogs sets up the logs and channels to be attached. Adapted from
 // github.com/containerd/nerdctl/pkg/cmd/container.Logs to pass a stop channel
-// and a success response message.
+// and a success response message. When following the logs, followRestarts keeps
+// them streaming through the restarts of the container by its restart policy.
 func (s *service) attachLogs(
 	ctx context.Context,
 	con containerd.Container,
 	options ncTypes.ContainerLogsOptions,
 	stopChannel chan os.Signal,
 	printSuccessResp func(),
+	followRestarts bool,
 ) error {
 	dataStore, err := s.nctlContainerSvc.GetDataStore()
 	if err != nil {
@@ -137,22 +163,26 @@ func (s *service) attachLogs(
 			return fmt.Errorf("failed to get wait channel for task %#v: %s", task, err)
 		}
 
-		// setup goroutine to send stop event if container task finishes:
+		// cancel the goroutine once the logs are returned, as it may be waiting for a restart of the container
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		// setup goroutine to send stop event once the container task finishes for good:
 		go func() {
-			<-waitCh
-			s.logger.Debugf("container task has finished, sending kill signal to log viewer")
-
-			// NOTE: This is a temporary workaround to fix the logger issue where strings without newline are not logged:
-			// https://github.com/containerd/nerdctl/issues/2313
-			// TODO: Remove this logic when the issue is fixed in nerdctl
-			// delete finished task to shutdown the logger and print buffered data
-			task.Delete(ctx)
-			time.Sleep(100 * time.Millisecond)
-
-			stopChannel <- os.Interrupt
+			s.waitForLogsEnd(ctx, con, task, waitCh, followRestarts)
+			select {
+			case stopChannel <- os.Interrupt:
+			case <-ctx.Done():
+			}
 		}()
 	}
 
+	var detailPrefix string
+	if options.Details {
+		if detailPrefix, err = getLogDetails(ctx, con, l); err != nil {
+			return err
+		}
+	}
+
 	logViewOpts := logging.LogViewOptions{
 		ContainerID:       con.ID(),
 		Namespace:         l[labels.Namespace],
@@ -163,6 +193,8 @@ func (s *service) attachLogs(
 		Tail:              options.Tail,
 		Since:             options.Since,
 		Until:             options.Until,
+		Details:           options.Details,
+		DetailPrefix:      &detailPrefix,
 	}
 	logViewer, err := s.nctlContainerSvc.LoggingInitContainerLogViewer(l, logViewOpts, stopChannel, options.GOptions.Experimental)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"syscall"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/cio"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/labels/k8slabels"
 	"github.com/containerd/typeurl/v2"
@@ -25,6 +26,7 @@ import (
 	"github.com/runfinch/finch-daemon/api/handlers/container"
 	attachTypes "github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_cio"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
@@ -133,6 +135,33 @@ var _ = Describe("Container Attach API ", func() {
 			err := service.Attach(ctx, cid, &opts)
 			Expect(err.Error()).Should(ContainSubstring(expErr))
 		})
+		It("should forward stdin to the stdin of the container", func() {
+			expErr := "error data store not found"
+			con := mocks_container.NewMockContainer(mockCtrl)
+			task := mocks_container.NewMockTask(mockCtrl)
+			taskIO := mocks_cio.NewMockIO(mockCtrl)
+			cdClient.EXPECT().SearchContainer(gomock.Any(), gomock.Any()).Return([]containerd.Container{con}, nil)
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).Return().AnyTimes()
+			con.EXPECT().ID().Return(cid)
+			con.EXPECT().Task(ctx, gomock.Any()).Return(task, nil)
+			task.EXPECT().IO().Return(taskIO)
+			taskIO.EXPECT().Config().Return(cio.Config{Stdin: "stdin"})
+			ncClient.EXPECT().GetDataStore().Return("", fmt.Errorf("%s", expErr))
+			// stdin is detached once the attach ends.
+			taskIO.EXPECT().Cancel()
+			taskIO.EXPECT().Close().Return(nil)
+
+			opts := attachTypes.AttachOptions{
+				GetStreams: setupStreams,
+				UseStdin:   true,
+				UseStdout:  true,
+				Logs:       true,
+				Stream:     true,
+				Stdin:      bytes.NewBufferString("input"),
+			}
+			err := service.Attach(ctx, cid, &opts)
+			Expect(err.Error()).Should(ContainSubstring(expErr))
+		})
 		It("should return a not found error if a container can't be found", func() {
 			// set up mocks
 			cdClient.EXPECT().SearchContainer(gomock.Any(), gomock.Any()).Return([]containerd.Container{}, nil)
@@ -7,25 +7,66 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	containerd "github.com/containerd/containerd/v2/client"
-	"github.com/containerd/containerd/v2/pkg/cio"
 	cerrdefs "github.com/containerd/errdefs"
-	"github.com/containerd/nerdctl/v2/pkg/api/types"
-	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
-	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/containerd/typeurl/v2"
+	"github.com/distribution/reference"
+	"github.com/docker/go-units"
 	"github.com/sirupsen/logrus"
 
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/backend"
+	"github.com/runfinch/finch-daemon/internal/logging"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
+	"github.com/runfinch/finch-daemon/pkg/utility/imageutility"
 )
 
-func (s *service) Create(ctx context.Context, image string, cmd []string, createOpt types.ContainerCreateOptions, netOpt types.NetworkOptions) (cid string, err error) {
+// minMemoryLimit is the minimum memory limit docker allows for a container.
+const minMemoryLimit = 6 * 1024 * 1024
+
+// createConfigExtension is the name of the containerd extension holding the Config and HostConfig of the
+// request a container was created with, so that inspect can return them as they were requested.
+const createConfigExtension = "finch-daemon/create-config"
+
+// createConfig is the content of the createConfigExtension.
+type createConfig struct {
+	Config     types.ContainerConfig
+	HostConfig types.ContainerHostConfig
+}
+
+func init() {
+	typeurl.Register(&createConfig{}, "github.com/runfinch/finch-daemon", "createConfig")
+}
+
+func (s *service) Create(ctx context.Context, image string, cmd []string, createOpt ncTypes.ContainerCreateOptions, netOpt ncTypes.NetworkOptions, req *types.ContainerCreateRequest) (cid string, warnings []string, err error) {
+	warnings, err = verifyCreateOptions(probeSysInfo(s.fs), &createOpt, &netOpt)
+	if err != nil {
+		return "", nil, errdefs.NewInvalidFormat(err)
+	}
+	if err = logging.ValidateLogConfig(createOpt.LogDriver, strutil.ConvertKVStringsToMap(createOpt.LogOpt)); err != nil {
+		return "", nil, errdefs.NewInvalidFormat(err)
+	}
+	// the configured snapshotter is always available, so only the snapshotter requested instead is checked.
+	if snapshotter := createOpt.GOptions.Snapshotter; snapshotter != "" && snapshotter != s.nctlContainerSvc.Snapshotter() {
+		ok, err := s.client.HasSnapshotter(ctx, snapshotter)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to check snapshotter %s: %w", snapshotter, err)
+		}
+		if !ok {
+			return "", nil, errdefs.NewInvalidFormat(fmt.Errorf("snapshotter %s is not available", snapshotter))
+		}
+	}
+
 	// Set path to nerdctl binary required for OCI hooks and logging
 	if createOpt.NerdctlCmd == "" {
 		ncExe, err := s.nctlContainerSvc.GetNerdctlExe()
 		if err != nil {
-			return "", fmt.Errorf("failed to find nerdctl binary: %s", err)
+			return "", nil, fmt.Errorf("failed to find nerdctl binary: %s", err)
 		}
 		createOpt.NerdctlCmd = ncExe
 		createOpt.NerdctlArgs = []string{}
@@ -34,12 +75,37 @@ func (s *service) Create(ctx context.Context, image string, cmd []string, create
 	netManager, err := s.nctlContainerSvc.NewNetworkingOptionsManager(netOpt)
 	if err != nil {
 		logrus.Debugf("error creating network manager for the given network options: %s", err)
-		return "", err
+		return "", nil, err
 	}
 
-	args := []string{image}
+	pinned, err := s.verifyImage(ctx, image)
+	if err != nil {
+		return "", nil, err
+	}
+	if err = s.nctlContainerSvc.UnpackImage(ctx, pinned, createOpt); err != nil {
+		switch {
+		case cerrdefs.IsPermissionDenied(err):
+			return "", nil, errdefs.NewForbidden(err)
+		case cerrdefs.IsNotFound(err):
+			return "", nil, errdefs.NewNotFound(err)
+		default:
+			return "", nil, fmt.Errorf("failed to unpack image %s: %w", pinned, err)
+		}
+	}
+
+	args := []string{pinned}
 	args = append(args, cmd...)
-	cont, gc, err := s.nctlContainerSvc.CreateContainer(ctx, args, netManager, createOpt)
+	createCtx := ctx
+	if req != nil {
+		// the create config is written with the container, so that every container created through the daemon has it.
+		createCtx = backend.WithContainerExtensions(ctx, map[string]any{
+			createConfigExtension: &createConfig{
+				Config:     req.ContainerConfig,
+				HostConfig: appliedHostConfig(req.HostConfig, createOpt, netOpt),
+			},
+		})
+	}
+	cont, gc, err := s.nctlContainerSvc.CreateContainer(createCtx, args, netManager, createOpt)
 	if err != nil {
 		if gc != nil {
 			gc()
@@ -49,57 +115,304 @@ func (s *service) Create(ctx context.Context, image string, cmd []string, create
 		// translate error definitions from containerd
 		switch {
 		case cerrdefs.IsNotFound(err):
-			return "", errdefs.NewNotFound(err)
+			return "", nil, errdefs.NewNotFound(err)
 		case cerrdefs.IsInvalidArgument(err):
-			return "", errdefs.NewInvalidFormat(err)
+			return "", nil, errdefs.NewInvalidFormat(err)
 		case cerrdefs.IsAlreadyExists(err):
-			return "", errdefs.NewConflict(err)
+			return "", nil, errdefs.NewConflict(err)
 		default:
-			return "", err
+			return "", nil, err
+		}
+	}
+
+	if pinned != image {
+		if err := s.tagPinnedImage(ctx, pinned, image); err != nil {
+			s.logger.Warnf("failed to tag the image %s pulled for container %s: %s", image, cont.ID(), err)
 		}
 	}
 
 	updateContainerMetadata(ctx, createOpt, netOpt, cont)
 
-	return cont.ID(), nil
+	return cont.ID(), warnings, nil
 }
 
-func updateContainerMetadata(ctx context.Context, createOpt types.ContainerCreateOptions, netOpt types.NetworkOptions, cont containerd.Container) error {
-	// get container labels
-	opts, err := cont.Labels(ctx)
+// verifyImage verifies the image of a container against the verification policy of the daemon if the image is
+// missing and so pulled by the creation of the container. It returns the reference to create the container from,
+// which is pinned to the verified digest so that the pulled image is the verified one.
+func (s *service) verifyImage(ctx context.Context, image string) (string, error) {
+	imgs, err := s.client.SearchImage(ctx, image)
 	if err != nil {
-		logrus.Errorf("failed to get container labels: %s", err)
-		return err
+		return "", err
 	}
-	// get oci spec
-	spec, err := cont.Spec(ctx)
+	if len(imgs) > 0 {
+		return image, nil
+	}
+	named, err := reference.ParseNormalizedNamed(image)
 	if err != nil {
-		logrus.Errorf("failed to get container OCI spec: %s", err)
-		return err
+		// the creation of the container reports the invalid reference.
+		return image, nil
 	}
+	verified, err := s.nctlContainerSvc.VerifyImage(ctx, reference.TagNameOnly(named).String())
+	if err != nil {
+		return "", errdefs.NewForbidden(fmt.Errorf("image verification failed for %s: %w", image, err))
+	}
+	if verified == "" {
+		return image, nil
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), verified)
+	if err != nil {
+		return "", err
+	}
+	return pinned.String(), nil
+}
 
-	// Handle log URI reset
-	// NOTE: this is a temporary workaround to fix logging issue described in https://github.com/containerd/nerdctl/issues/2264.
-	// The refactored create method in nerdctl uses self exe (finch-daemon) binary for logging instead of nerdctl binary path.
-	// The following workaround resets this logging binary in the OCI spec and handles port labels for backward compatibility.
-	// TODO: remove this workaround when the issue is resolved upstream.
-	dataStore, err := clientutil.DataStore(createOpt.GOptions.DataRoot, createOpt.GOptions.Address)
+// tagPinnedImage names the image pulled with the pinned reference by the creation of a container with the reference
+// it was requested with, as a pull of the image would. The record of the pinned reference is kept, as the container
+// refers to its image by it.
+func (s *service) tagPinnedImage(ctx context.Context, pinned, image string) error {
+	named, err := reference.ParseNormalizedNamed(image)
 	if err != nil {
-		logrus.Errorf("failed to get nerdctl data store: %s", err)
 		return err
 	}
+	return imageutility.TagPinnedImage(ctx, s.client.ImageService(), pinned, reference.TagNameOnly(named).String(), true)
+}
+
+// verifyCreateOptions checks the resources requested for a container against the features of the host.
+// Like docker, the resources which are not supported are discarded with a warning instead of failing the
+// creation of the container. Inconsistent resources are reported as errors.
+// Adapted from https://github.com/moby/moby/blob/v24.0.2/daemon/daemon_unix.go#L366-L580
+func verifyCreateOptions(info *sysInfo, createOpt *ncTypes.ContainerCreateOptions, netOpt *ncTypes.NetworkOptions) ([]string, error) {
+	var warnings []string
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+
+	memory, err := parseBytes(createOpt.Memory)
+	if err != nil {
+		return nil, err
+	}
+	memorySwap, err := parseBytes(createOpt.MemorySwap)
+	if err != nil {
+		return nil, err
+	}
+	memoryReservation, err := parseBytes(createOpt.MemoryReservation)
+	if err != nil {
+		return nil, err
+	}
+
+	// memory subsystem checks and adjustments
+	if memory != 0 && memory < minMemoryLimit {
+		return nil, fmt.Errorf("minimum memory limit allowed is 6MB")
+	}
+	if memory > 0 && !info.MemoryLimit {
+		warn("Your kernel does not support memory limit capabilities or the cgroup is not mounted. Limitation discarded.")
+		createOpt.Memory = ""
+		createOpt.MemorySwap = ""
+		memory, memorySwap = 0, 0
+	}
+	if memory > 0 && memorySwap != 0 && memorySwap != -1 && !info.SwapLimit {
+		warn("Your kernel does not support swap limit capabilities or the cgroup is not mounted. Memory limited without swap.")
+		createOpt.MemorySwap = ""
+		memorySwap = 0
+	}
+	if memory > 0 && memorySwap > 0 && memorySwap < memory {
+		return nil, fmt.Errorf("minimum memoryswap limit should be larger than memory limit, see usage")
+	}
+	if memory == 0 && memorySwap > 0 {
+		return nil, fmt.Errorf("you should always set the memory limit when using memoryswap limit, see usage")
+	}
+	if createOpt.MemorySwappiness64 > 0 {
+		if !info.MemorySwappiness {
+			warn("Your kernel does not support memory swappiness capabilities or the cgroup is not mounted. Memory swappiness discarded.")
+			createOpt.MemorySwappiness64 = -1
+		} else if createOpt.MemorySwappiness64 > 100 {
+			return nil, fmt.Errorf("invalid value: %d, valid memory swappiness range is 0-100", createOpt.MemorySwappiness64)
+		}
+	}
+	if memoryReservation > 0 && !info.MemoryReservation {
+		warn("Your kernel does not support memory soft limit capabilities or the cgroup is not mounted. Limitation discarded.")
+		createOpt.MemoryReservation = ""
+		memoryReservation = 0
+	}
+	if memory > 0 && memoryReservation > 0 && memory < memoryReservation {
+		return nil, fmt.Errorf("minimum memory limit can not be less than memory reservation limit, see usage")
+	}
+	if createOpt.OomKillDisable && !info.OomKillDisable {
+		warn("Your kernel does not support OomKillDisable. OomKillDisable discarded.")
+		createOpt.OomKillDisable = false
+	}
+	if createOpt.OomKillDisable && memory == 0 {
+		warn("OOM killer is disabled for the container, but no memory limit is set, this can result in the system running out of resources.")
+	}
+
+	// pids subsystem checks and adjustments
+	if createOpt.PidsLimit > 0 && !info.PidsLimit {
+		warn("Your kernel does not support PIDs limit capabilities or the cgroup is not mounted. PIDs limit discarded.")
+		createOpt.PidsLimit = -1
+	}
+
+	// cpu subsystem checks and adjustments
+	if createOpt.CPUShares > 0 && !info.CPUShares {
+		warn("Your kernel does not support CPU shares or the cgroup is not mounted. Shares discarded.")
+		createOpt.CPUShares = 0
+	}
+	if createOpt.CPUPeriod > 0 && !info.CPUCfs {
+		warn("Your kernel does not support CPU CFS period or the cgroup is not mounted. Period discarded.")
+		createOpt.CPUPeriod = 0
+	}
+	if createOpt.CPUQuota > 0 && !info.CPUCfs {
+		warn("Your kernel does not support CPU CFS quota or the cgroup is not mounted. Quota discarded.")
+		createOpt.CPUQuota = -1
+	}
+	if (createOpt.CPURealtimePeriod > 0 || createOpt.CPURealtimeRuntime > 0) && !info.CPURealtime {
+		warn("Your kernel does not support CPU real-time scheduler or the cgroup is not mounted. Real-time period and runtime discarded.")
+		createOpt.CPURealtimePeriod = 0
+		createOpt.CPURealtimeRuntime = 0
+	}
+	if (createOpt.CPUSetCPUs != "" || createOpt.CPUSetMems != "") && !info.Cpuset {
+		warn("Your kernel does not support cpuset or the cgroup is not mounted. Cpuset discarded.")
+		createOpt.CPUSetCPUs = ""
+		createOpt.CPUSetMems = ""
+	}
+
+	// blkio subsystem checks and adjustments
+	if createOpt.BlkioWeight > 0 {
+		if !info.BlkioWeight {
+			warn("Your kernel does not support Block I/O weight or the cgroup is not mounted. Weight discarded.")
+			createOpt.BlkioWeight = 0
+		} else if createOpt.BlkioWeight < 10 || createOpt.BlkioWeight > 1000 {
+			return nil, fmt.Errorf("range of blkio weight is from 10 to 1000")
+		}
+	}
+	if len(createOpt.BlkioWeightDevice) > 0 && !info.BlkioWeightDevice {
+		warn("Your kernel does not support Block I/O weight_device or the cgroup is not mounted. Weight-device discarded.")
+		createOpt.BlkioWeightDevice = nil
+	}
+	if len(createOpt.BlkioDeviceReadBps) > 0 && !info.BlkioReadBpsDevice {
+		warn("Your kernel does not support BPS Block I/O read limit or the cgroup is not mounted. Block I/O BPS read limit discarded.")
+		createOpt.BlkioDeviceReadBps = nil
+	}
+	if len(createOpt.BlkioDeviceWriteBps) > 0 && !info.BlkioWriteBpsDevice {
+		warn("Your kernel does not support BPS Block I/O write limit or the cgroup is not mounted. Block I/O BPS write limit discarded.")
+		createOpt.BlkioDeviceWriteBps = nil
+	}
+	if len(createOpt.BlkioDeviceReadIOps) > 0 && !info.BlkioReadIOpsDevice {
+		warn("Your kernel does not support IOPS Block read limit or the cgroup is not mounted. Block I/O IOPS read limit discarded.")
+		createOpt.BlkioDeviceReadIOps = nil
+	}
+	if len(createOpt.BlkioDeviceWriteIOps) > 0 && !info.BlkioWriteIOpsDevice {
+		warn("Your kernel does not support IOPS Block write limit or the cgroup is not mounted. Block I/O IOPS write limit discarded.")
+		createOpt.BlkioDeviceWriteIOps = nil
+	}
+
+	// ports are not published on the host network
+	if len(netOpt.PortMappings) > 0 && slices.Contains(netOpt.NetworkSlice, "host") {
+		warn("Published ports are discarded when using host network mode")
+		netOpt.PortMappings = nil
+	}
+
+	return warnings, nil
+}
+
+// parseBytes parses a size in bytes as passed to nerdctl, e.g. "1024" or "1g". An empty size is 0.
+func parseBytes(size string) (int64, error) {
+	if size == "" {
+		return 0, nil
+	}
+	if size == "-1" {
+		return -1, nil
+	}
+	return units.RAMInBytes(size)
+}
 
-	args := map[string]string{
-		logging.MagicArgv1: dataStore,
+// appliedHostConfig returns the HostConfig of a create request without the resources discarded by
+// verifyCreateOptions, so that inspect returns the HostConfig the container was actually created with.
+func appliedHostConfig(hostConfig types.ContainerHostConfig, createOpt ncTypes.ContainerCreateOptions, netOpt ncTypes.NetworkOptions) types.ContainerHostConfig {
+	if createOpt.Memory == "" {
+		hostConfig.Memory = 0
 	}
-	logURI, err := cio.LogURIGenerator("binary", createOpt.NerdctlCmd, args)
+	if createOpt.MemorySwap == "" {
+		hostConfig.MemorySwap = 0
+	}
+	if hostConfig.MemorySwappiness > 0 && createOpt.MemorySwappiness64 == -1 {
+		hostConfig.MemorySwappiness = -1
+	}
+	if createOpt.MemoryReservation == "" {
+		hostConfig.MemoryReservation = 0
+	}
+	hostConfig.OomKillDisable = createOpt.OomKillDisable
+	if hostConfig.PidsLimit > 0 && createOpt.PidsLimit == -1 {
+		hostConfig.PidsLimit = 0
+	}
+	if createOpt.CPUShares == 0 {
+		hostConfig.CPUShares = 0
+	}
+	if createOpt.CPUPeriod == 0 {
+		hostConfig.CPUPeriod = 0
+	}
+	if hostConfig.CPUQuota > 0 && createOpt.CPUQuota == -1 {
+		hostConfig.CPUQuota = 0
+	}
+	if createOpt.CPURealtimePeriod == 0 {
+		hostConfig.CPURealtimePeriod = 0
+	}
+	if createOpt.CPURealtimeRuntime == 0 {
+		hostConfig.CPURealtimeRuntime = 0
+	}
+	hostConfig.CPUSetCPUs = createOpt.CPUSetCPUs
+	hostConfig.CPUSetMems = createOpt.CPUSetMems
+	hostConfig.BlkioWeight = createOpt.BlkioWeight
+	if len(createOpt.BlkioWeightDevice) == 0 {
+		hostConfig.BlkioWeightDevice = nil
+	}
+	if len(createOpt.BlkioDeviceReadBps) == 0 {
+		hostConfig.BlkioDeviceReadBps = nil
+	}
+	if len(createOpt.BlkioDeviceWriteBps) == 0 {
+		hostConfig.BlkioDeviceWriteBps = nil
+	}
+	if len(createOpt.BlkioDeviceReadIOps) == 0 {
+		hostConfig.BlkioDeviceReadIOps = nil
+	}
+	if len(createOpt.BlkioDeviceWriteIOps) == 0 {
+		hostConfig.BlkioDeviceWriteIOps = nil
+	}
+	if len(netOpt.PortMappings) == 0 {
+		hostConfig.PortBindings = nil
+	}
+	return hostConfig
+}
+
+// getCreateConfig returns the Config and HostConfig stored in the extensions of a container at creation,
+// or nil for containers which were not created through the daemon.
+func getCreateConfig(extensions map[string]typeurl.Any) (*createConfig, error) {
+	ext, ok := extensions[createConfigExtension]
+	if !ok || ext == nil {
+		return nil, nil
+	}
+	var config createConfig
+	if err := typeurl.UnmarshalTo(ext, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func updateContainerMetadata(ctx context.Context, createOpt ncTypes.ContainerCreateOptions, netOpt ncTypes.NetworkOptions, cont containerd.Container) error {
+	// get container labels
+	opts, err := cont.Labels(ctx)
 	if err != nil {
-		logrus.Errorf("failed to generate a log URI: %s", err)
+		logrus.Errorf("failed to get container labels: %s", err)
+		return err
+	}
+	// get oci spec
+	spec, err := cont.Spec(ctx)
+	if err != nil {
+		logrus.Errorf("failed to get container OCI spec: %s", err)
 		return err
 	}
 
-	opts[labels.LogURI] = logURI.String()
-	spec.Annotations[labels.LogURI] = logURI.String()
+	// NOTE: nerdctl uses its own executable, i.e. the daemon binary, as the logging binary of the containers it creates
+	// (https://github.com/containerd/nerdctl/issues/2264), which runs the log drivers of internal/logging.
 
 	// Handle port labels for backward compatibility with nerdctl 2.1.2.
 	// nerdctl 2.1.3 changed the port publishing logic to use a dedicated portstore instead of container labels.
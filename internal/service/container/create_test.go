@@ -6,18 +6,32 @@ package container
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	containersapi "github.com/containerd/containerd/api/services/containers/v1"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/containerd/go-cni"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/typeurl/v2"
+	"github.com/docker/go-connections/nat"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/spf13/afero"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
 
+	finchTypes "github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_archive"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
@@ -42,6 +56,8 @@ var _ = Describe("Container Create API ", func() {
 		cid            string
 		svc            *service
 		tarExtractor   *mocks_archive.MockTarExtractor
+		localImages    []images.Image
+		unpackErr      error
 	)
 	BeforeEach(func() {
 		ctx = context.Background()
@@ -62,11 +78,23 @@ var _ = Describe("Container Create API ", func() {
 		con = mocks_container.NewMockContainer(mockCtrl)
 		con.EXPECT().ID().Return(cid).AnyTimes()
 		tarExtractor = mocks_archive.NewMockTarExtractor(mockCtrl)
+		// the image is present locally by default, so it is not pulled and verified
+		localImages = []images.Image{{Name: "docker.io/library/test-image:latest"}}
+		cdClient.EXPECT().SearchImage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, string) ([]images.Image, error) {
+				return localImages, nil
+			}).AnyTimes()
+		unpackErr = nil
+		ncContainerSvc.EXPECT().UnpackImage(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, string, types.ContainerCreateOptions) error {
+				return unpackErr
+			}).AnyTimes()
 
 		svc = &service{
 			client:           cdClient,
 			nctlContainerSvc: mockNerdctlService{ncContainerSvc, ncNetworkSvc},
 			logger:           logger,
+			fs:               afero.NewMemMapFs(),
 			tarExtractor:     tarExtractor,
 		}
 	})
@@ -85,17 +113,196 @@ var _ = Describe("Container Create API ", func() {
 			con.EXPECT().Labels(ctx).Return(nil, errors.New("mock error"))
 
 			// service should not return any error and the returned cid should match expected
-			cidResult, err := svc.Create(ctx, image, cmd, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
 			Expect(cidResult).Should(Equal(cid))
 			Expect(err).Should(BeNil())
 		})
+		It("should save the config of the create request with the container", func() {
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+			args := []string{image}
+			args = append(args, cmd...)
+			var createReq containersapi.CreateContainerRequest
+			ncContainerSvc.EXPECT().CreateContainer(gomock.Any(), args, netManager, createOptExp).DoAndReturn(
+				func(ctx context.Context, _ []string, _ containerutil.NetworkOptionsManager, _ types.ContainerCreateOptions) (containerd.Container, func(), error) {
+					// the extensions of the context are added to the request creating the container.
+					createReq.Container = &containersapi.Container{}
+					err := backend.ContainerExtensionsInterceptor(ctx, "Create", &createReq, nil, nil,
+						func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+							return nil
+						})
+					Expect(err).Should(BeNil())
+					return con, nil, nil
+				})
+			con.EXPECT().Labels(ctx).Return(nil, errors.New("mock error"))
+
+			req := &finchTypes.ContainerCreateRequest{}
+			req.Image = image
+			req.HostConfig.Memory = 1024 * 1024 * 1024
+			req.HostConfig.PortBindings = nat.PortMap{"80/tcp": {{HostPort: "8080"}}}
+			createOpt.Memory = "1073741824"
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, req)
+			Expect(cidResult).Should(Equal(cid))
+			Expect(err).Should(BeNil())
+
+			config, err := getCreateConfig(map[string]typeurl.Any{
+				createConfigExtension: createReq.Container.Extensions[createConfigExtension],
+			})
+			Expect(err).Should(BeNil())
+			Expect(config.Config.Image).Should(Equal(image))
+			// the memory limit is discarded as the host has no memory cgroup, and the port bindings are not applied
+			// without port mappings.
+			Expect(config.HostConfig.Memory).Should(BeZero())
+			Expect(config.HostConfig.PortBindings).Should(BeNil())
+		})
+		It("should create the container from the verified digest of a missing image", func() {
+			localImages = nil
+			verified := digest.FromString("test-image")
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+			ncContainerSvc.EXPECT().VerifyImage(ctx, "docker.io/library/test-image:latest").Return(verified, nil)
+			pinned := "docker.io/library/test-image@" + verified.String()
+			args := []string{pinned}
+			args = append(args, cmd...)
+			ncContainerSvc.EXPECT().CreateContainer(ctx, args, netManager, createOptExp).Return(
+				con, nil, nil)
+			con.EXPECT().Labels(ctx).Return(nil, errors.New("mock error"))
+
+			// the pulled image is tagged with the requested reference, and keeps the pinned reference of the container.
+			store := mocks_image.NewMockStore(mockCtrl)
+			records := map[string]images.Image{
+				pinned: {Name: pinned, Target: ocispec.Descriptor{Digest: verified}},
+			}
+			cdClient.EXPECT().ImageService().Return(store).AnyTimes()
+			store.EXPECT().Get(gomock.Any(), pinned).Return(records[pinned], nil)
+			store.EXPECT().Update(gomock.Any(), gomock.Any()).Return(images.Image{}, cerrdefs.ErrNotFound)
+			store.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, img images.Image) (images.Image, error) {
+					records[img.Name] = img
+					return img, nil
+				})
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(Equal(cid))
+			Expect(err).Should(BeNil())
+			Expect(records).Should(HaveKeyWithValue("docker.io/library/test-image:latest", images.Image{
+				Name:   "docker.io/library/test-image:latest",
+				Target: ocispec.Descriptor{Digest: verified},
+			}))
+			Expect(records).Should(HaveKey(pinned))
+		})
+		It("should create the container from a missing image the policy does not verify", func() {
+			localImages = nil
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+			ncContainerSvc.EXPECT().VerifyImage(ctx, "docker.io/library/test-image:latest").Return(digest.Digest(""), nil)
+			args := []string{image}
+			args = append(args, cmd...)
+			ncContainerSvc.EXPECT().CreateContainer(ctx, args, netManager, createOptExp).Return(
+				con, nil, nil)
+			con.EXPECT().Labels(ctx).Return(nil, errors.New("mock error"))
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(Equal(cid))
+			Expect(err).Should(BeNil())
+		})
+		It("should return a forbidden error if a missing image fails verification", func() {
+			localImages = nil
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+			ncContainerSvc.EXPECT().VerifyImage(ctx, "docker.io/library/test-image:latest").Return(
+				digest.Digest(""), errors.New("no matching signatures"))
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(BeEmpty())
+			Expect(errdefs.IsForbiddenError(err)).Should(BeTrue())
+		})
+		It("should return an invalid format error if the snapshotter is not available", func() {
+			createOpt.GOptions.Snapshotter = "unknown"
+			ncContainerSvc.EXPECT().Snapshotter().Return("overlayfs")
+			cdClient.EXPECT().HasSnapshotter(ctx, "unknown").Return(false, nil)
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(BeEmpty())
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+		})
+		It("should not check the configured snapshotter", func() {
+			createOpt.GOptions.Snapshotter = "overlayfs"
+			createOptExp.GOptions.Snapshotter = "overlayfs"
+			ncContainerSvc.EXPECT().Snapshotter().Return("overlayfs")
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+			args := []string{image}
+			args = append(args, cmd...)
+			ncContainerSvc.EXPECT().CreateContainer(ctx, args, netManager, createOptExp).Return(
+				con, nil, nil)
+			con.EXPECT().Labels(ctx).Return(nil, errors.New("mock error"))
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(Equal(cid))
+			Expect(err).Should(BeNil())
+		})
+		It("should return a forbidden error if the daemon cannot decrypt the image", func() {
+			unpackErr = fmt.Errorf("%w: no decryption key of the daemon can decrypt image test-image", cerrdefs.ErrPermissionDenied)
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(BeEmpty())
+			Expect(errdefs.IsForbiddenError(err)).Should(BeTrue())
+		})
+		It("should return an error if the image cannot be unpacked", func() {
+			unpackErr = errors.New("mock error")
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(BeEmpty())
+			Expect(err).Should(MatchError("failed to unpack image test-image: mock error"))
+		})
+		It("should discard the resources the host does not support with a warning", func() {
+			createOpt.Memory = "104857600"
+			createOptExp.Memory = ""
+			createOptExp.MemorySwap = ""
+			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
+			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(netOpt).Return(netManager, nil)
+			args := []string{image}
+			args = append(args, cmd...)
+			ncContainerSvc.EXPECT().CreateContainer(ctx, args, netManager, createOptExp).Return(
+				con, nil, nil)
+			con.EXPECT().Labels(ctx).Return(nil, errors.New("mock error"))
+
+			cidResult, warnings, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(err).Should(BeNil())
+			Expect(cidResult).Should(Equal(cid))
+			Expect(warnings).Should(ConsistOf(
+				"Your kernel does not support memory limit capabilities or the cgroup is not mounted. Limitation discarded."))
+		})
+		It("should return invalid-format error for inconsistent resources", func() {
+			createOpt.Memory = "1024"
+
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(cidResult).Should(BeEmpty())
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+		})
+		It("should return invalid-format error for unknown log drivers and options", func() {
+			createOpt.LogDriver = "json-file"
+			createOpt.LogOpt = []string{"max-size=10m", "unknown=1"}
+			_, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+
+			createOpt.LogDriver = "unknown"
+			createOpt.LogOpt = nil
+			_, _, err = svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+		})
 		It("should return internal error for network options create failure", func() {
 			mockErr := errors.New("error while creating networking options")
 			ncContainerSvc.EXPECT().GetNerdctlExe().Return(ncExe, nil)
 			ncContainerSvc.EXPECT().NewNetworkingOptionsManager(gomock.Any()).Return(nil, mockErr)
 
 			// service should return with an error
-			cidResult, err := svc.Create(ctx, image, nil, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, nil, createOpt, netOpt, nil)
 			Expect(cidResult).Should(BeEmpty())
 			Expect(err.Error()).Should(Equal(mockErr.Error()))
 		})
@@ -112,7 +319,7 @@ var _ = Describe("Container Create API ", func() {
 				nil, nil, mockErr)
 
 			// service should return with an error
-			cidResult, err := svc.Create(ctx, image, cmd, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
 			Expect(cidResult).Should(BeEmpty())
 			Expect(err.Error()).Should(Equal(mockErr.Error()))
 		})
@@ -135,7 +342,7 @@ var _ = Describe("Container Create API ", func() {
 				nil, mockGc, mockErr)
 
 			// service should call garbage collector and return with an error
-			cidResult, err := svc.Create(ctx, image, cmd, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
 			Expect(cidResult).Should(BeEmpty())
 			Expect(gcFlag).Should(BeTrue())
 			Expect(err.Error()).Should(Equal(mockErr.Error()))
@@ -152,7 +359,7 @@ var _ = Describe("Container Create API ", func() {
 				nil, nil, cerrdefs.ErrNotFound)
 
 			// service should return with an error
-			cidResult, err := svc.Create(ctx, image, cmd, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
 			Expect(cidResult).Should(BeEmpty())
 			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 		})
@@ -168,7 +375,7 @@ var _ = Describe("Container Create API ", func() {
 				nil, nil, cerrdefs.ErrInvalidArgument)
 
 			// service should return with an error
-			cidResult, err := svc.Create(ctx, image, cmd, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
 			Expect(cidResult).Should(BeEmpty())
 			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
 		})
@@ -184,7 +391,7 @@ var _ = Describe("Container Create API ", func() {
 				nil, nil, cerrdefs.ErrAlreadyExists)
 
 			// service should return with an error
-			cidResult, err := svc.Create(ctx, image, cmd, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, cmd, createOpt, netOpt, nil)
 			Expect(cidResult).Should(BeEmpty())
 			Expect(errdefs.IsConflict(err)).Should(BeTrue())
 		})
@@ -193,7 +400,7 @@ var _ = Describe("Container Create API ", func() {
 			ncContainerSvc.EXPECT().GetNerdctlExe().Return("", mockErr)
 
 			// service should return with an error
-			cidResult, err := svc.Create(ctx, image, nil, createOpt, netOpt)
+			cidResult, _, err := svc.Create(ctx, image, nil, createOpt, netOpt, nil)
 			Expect(cidResult).Should(BeEmpty())
 			Expect(err.Error()).Should(ContainSubstring(mockErr.Error()))
 		})
@@ -6,6 +6,8 @@ package container
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
@@ -15,12 +17,17 @@ import (
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/containerd/nerdctl/v2/pkg/flagutil"
 	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/opencontainers/runtime-spec/specs-go"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
+const execCreateEventAction = "exec_create"
+
 func (s *service) ExecCreate(ctx context.Context, cid string, config types.ExecConfig) (string, error) {
 	con, err := s.getContainer(ctx, cid)
 	if err != nil {
@@ -54,7 +61,7 @@ func (s *service) ExecCreate(ctx context.Context, cid string, config types.ExecC
 		return "", errdefs.NewConflict(fmt.Errorf("container %s is not running", cid))
 	}
 
-	execID := "exec-" + idgen.GenerateID()
+	execID := idgen.GenerateID()
 
 	ioCreator := func(id string) (cio.IO, error) {
 		fifos, err := s.client.NewFIFOSetInDir(defaults.DefaultFIFODir, id, config.Tty)
@@ -80,14 +87,58 @@ func (s *service) ExecCreate(ctx context.Context, cid string, config types.ExecC
 		return directIO, nil
 	}
 
-	// ignore the returned process because we will load it later in exec_start
-	_, err = task.Exec(ctx, execID, pspec, ioCreator)
+	// the process is loaded again by its ID in exec_start, where the exec instance is found from the exec store.
+	proc, err := task.Exec(ctx, execID, pspec, ioCreator)
 	if err != nil {
 		return "", err
 	}
 
-	// the task and process keep track of most of the state we care about, but we first need the container to access its task & process by execID.
-	return fmt.Sprintf("%s/%s", cid, execID), nil
+	exec := &execstore.Exec{
+		ID:          execID,
+		ContainerID: con.ID(),
+		Config:      config,
+		CreatedAt:   time.Now(),
+	}
+	if err = execstore.Add(ctx, s.client, con, exec); err != nil {
+		if _, delErr := proc.Delete(ctx); delErr != nil {
+			s.logger.Warnf("failed to delete exec process %s: %v", execID, delErr)
+		}
+		return "", err
+	}
+
+	info, err := con.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err == nil {
+		err = s.client.PublishEvent(ctx, execCreateTopic(), getExecCreateEvent(exec, info.Labels[labels.Name], info.Image))
+	}
+	if err != nil {
+		s.logger.Errorf("failed to publish exec_create event of exec instance %s: %s", execID, err)
+	}
+
+	return execID, nil
+}
+
+func execCreateTopic() string {
+	return fmt.Sprintf("/%s/%s/%s", eventtype.CompatibleTopicPrefix, "container", execCreateEventAction)
+}
+
+// getExecCreateEvent returns the exec_create event of an exec instance. Like docker, the action is followed by
+// the command of the exec instance.
+func getExecCreateEvent(exec *execstore.Exec, name, image string) *eventtype.Event {
+	action := fmt.Sprintf("%s: %s", execCreateEventAction, strings.Join(exec.Config.Cmd, " "))
+	return &eventtype.Event{
+		ID:     exec.ContainerID,
+		Status: action,
+		Type:   "container",
+		Action: action,
+		Actor: eventtype.EventActor{
+			Id: exec.ContainerID,
+			Attributes: map[string]string{
+				"name":   name,
+				"image":  image,
+				"execID": exec.ID,
+			},
+		},
+	}
 }
 
 func (s *service) generateExecProcessSpec(ctx context.Context, container containerd.Container, config types.ExecConfig) (*specs.Process, error) {
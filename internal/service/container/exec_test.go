@@ -6,7 +6,6 @@ package container
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
@@ -14,14 +13,17 @@ import (
 	"github.com/containerd/containerd/v2/pkg/cio"
 	"github.com/containerd/containerd/v2/pkg/oci"
 	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/spf13/afero"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/api/handlers/container"
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/mocks/mocks_archive"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
@@ -141,9 +143,49 @@ var _ = Describe("Container Exec API ", func() {
 					return proc, nil
 				})
 
+			con.EXPECT().ID().Return("123").Times(2)
+			con.EXPECT().Extensions(ctx).Return(nil, nil)
+			var execs []*execstore.Exec
+			cdClient.EXPECT().UpdateContainerExtension(ctx, "123", execstore.Extension, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _ string, ext any) error {
+					execs = ext.(*execstore.Execs).Execs
+					return nil
+				})
+			con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{
+				Labels: map[string]string{labels.Name: "test-con"},
+				Image:  "test-image",
+			}, nil)
+			var event *eventtype.Event
+			cdClient.EXPECT().PublishEvent(ctx, "/dockercompat/container/exec_create", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, e *eventtype.Event) error {
+					event = e
+					return nil
+				})
+
 			execId, err := service.ExecCreate(ctx, "123", execConfig)
 			Expect(err).Should(BeNil())
-			Expect(execId).Should(Equal(fmt.Sprintf("123/%s", eid)))
+			Expect(execId).Should(Equal(eid))
+			Expect(execId).Should(MatchRegexp("^[0-9a-f]{64}$"))
+			Expect(execs).Should(HaveLen(1))
+			Expect(execs[0].ID).Should(Equal(eid))
+			Expect(execs[0].ContainerID).Should(Equal("123"))
+			Expect(execs[0].Config).Should(Equal(execConfig))
+			Expect(execs[0].Running).Should(BeFalse())
+			Expect(execs[0].ExitCode).Should(BeNil())
+			Expect(event).Should(Equal(&eventtype.Event{
+				ID:     "123",
+				Status: "exec_create: foo bar",
+				Type:   "container",
+				Action: "exec_create: foo bar",
+				Actor: eventtype.EventActor{
+					Id: "123",
+					Attributes: map[string]string{
+						"name":   "test-con",
+						"image":  "test-image",
+						"execID": eid,
+					},
+				},
+			}))
 		})
 		It("should not create fifos for stdio when they aren't supposed to be attached", func() {
 			execConfig.AttachStdin = false
@@ -197,9 +239,27 @@ var _ = Describe("Container Exec API ", func() {
 					return proc, nil
 				})
 
+			con.EXPECT().ID().Return("123").Times(2)
+			con.EXPECT().Extensions(ctx).Return(nil, nil)
+			var execs []*execstore.Exec
+			cdClient.EXPECT().UpdateContainerExtension(ctx, "123", execstore.Extension, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _ string, ext any) error {
+					execs = ext.(*execstore.Execs).Execs
+					return nil
+				})
+			con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{}, nil)
+			cdClient.EXPECT().PublishEvent(ctx, "/dockercompat/container/exec_create", gomock.Any()).Return(nil)
+
 			execId, err := service.ExecCreate(ctx, "123", execConfig)
 			Expect(err).Should(BeNil())
-			Expect(execId).Should(Equal(fmt.Sprintf("123/%s", eid)))
+			Expect(execId).Should(Equal(eid))
+			Expect(execId).Should(MatchRegexp("^[0-9a-f]{64}$"))
+			Expect(execs).Should(HaveLen(1))
+			Expect(execs[0].ID).Should(Equal(eid))
+			Expect(execs[0].ContainerID).Should(Equal("123"))
+			Expect(execs[0].Config).Should(Equal(execConfig))
+			Expect(execs[0].Running).Should(BeFalse())
+			Expect(execs[0].ExitCode).Should(BeNil())
 		})
 		It("should return a NotFound error if the container is not found", func() {
 			cdClient.EXPECT().SearchContainer(ctx, "123").Return(nil, cerrdefs.ErrNotFound)
@@ -445,6 +505,41 @@ var _ = Describe("Container Exec API ", func() {
 			Expect(err.Error()).Should(Equal("exec error"))
 			Expect(execId).Should(BeEmpty())
 		})
+		It("should only log errors from publishing the exec_create event", func() {
+			cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+			con.EXPECT().Spec(ctx).Return(&oci.Spec{Process: &specs.Process{}}, nil)
+			execConfig.User = ""
+			execConfig.Privileged = false
+			con.EXPECT().Task(ctx, nil).Return(task, nil)
+			task.EXPECT().Status(ctx).Return(containerd.Status{Status: containerd.Running}, nil)
+			task.EXPECT().Exec(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(proc, nil)
+			con.EXPECT().ID().Return("123").Times(2)
+			con.EXPECT().Extensions(ctx).Return(nil, nil)
+			cdClient.EXPECT().UpdateContainerExtension(ctx, "123", execstore.Extension, gomock.Any()).Return(nil)
+			con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{}, nil)
+			cdClient.EXPECT().PublishEvent(ctx, gomock.Any(), gomock.Any()).Return(errors.New("publish error"))
+			logger.EXPECT().Errorf("failed to publish exec_create event of exec instance %s: %s", gomock.Any(), gomock.Any())
+
+			execId, err := service.ExecCreate(ctx, "123", execConfig)
+			Expect(err).Should(BeNil())
+			Expect(execId).ShouldNot(BeEmpty())
+		})
+		It("should delete the process if the exec instance cannot be recorded", func() {
+			cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+			con.EXPECT().Spec(ctx).Return(&oci.Spec{Process: &specs.Process{}}, nil)
+			execConfig.User = ""
+			execConfig.Privileged = false
+			con.EXPECT().Task(ctx, nil).Return(task, nil)
+			task.EXPECT().Status(ctx).Return(containerd.Status{Status: containerd.Running}, nil)
+			task.EXPECT().Exec(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(proc, nil)
+			con.EXPECT().ID().Return("123")
+			con.EXPECT().Extensions(ctx).Return(nil, errors.New("extensions error"))
+			proc.EXPECT().Delete(ctx).Return(nil, nil)
+
+			execId, err := service.ExecCreate(ctx, "123", execConfig)
+			Expect(err).Should(MatchError("extensions error"))
+			Expect(execId).Should(BeEmpty())
+		})
 		It("should pass through errors from NewFIFOSetInDir", func() {
 			cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
 			con.EXPECT().Spec(ctx).Return(&oci.Spec{Process: &specs.Process{}}, nil)
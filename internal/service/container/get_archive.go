@@ -9,9 +9,11 @@ import (
 	"io"
 	"os"
 	"path"
+	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	cerrdefs "github.com/containerd/errdefs"
+	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/spf13/afero"
 
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
@@ -63,11 +65,16 @@ func (s *service) GetPathToFilesInContainer(ctx context.Context, cid string, src
 		}
 	}
 
-	// TODO: ideally this should use securejoin.SecureJoin() to sanitize inputs but securejoin can't work with afero.MemMapFs yet
-	// so it makes testing difficult
-	filePath = path.Join(root, srcPath)
+	// like docker, a trailing symlink is archived as is unless the path ends with "/" or "/."
+	filePath, err = s.resolvePathInRoot(root, srcPath, hasTrailingSeparatorOrDot(srcPath))
+	if err != nil {
+		s.logger.Errorf("Error resolving %s in container: %s", srcPath, err)
+		return
+	}
 
-	_, err = s.fs.Stat(filePath)
+	// the trailing symlink, if not resolved above, must not be followed, as an absolute link target would be
+	// resolved against the host root rather than the container root.
+	_, err = aferoVFS{fs: s.fs}.Lstat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			err = errdefs.NewNotFound(err)
@@ -81,12 +88,47 @@ func (s *service) GetPathToFilesInContainer(ctx context.Context, cid string, src
 }
 
 func (s *service) WriteFilesAsTarArchive(filePath string, writer io.Writer, slashDot bool) error {
-	cmd, err := s.tarCreator.CreateTarCommand(filePath, slashDot)
+	return s.tarCreator.CreateTar(filePath, writer, slashDot)
+}
+
+// resolvePathInRoot joins unsafePath to root, evaluating every symlink as if root were the filesystem root so that
+// the result can never escape it. If followLink is false, the last path component is not resolved.
+func (s *service) resolvePathInRoot(root string, unsafePath string, followLink bool) (string, error) {
+	vfs := aferoVFS{fs: s.fs}
+	cleanPath := path.Clean("/" + unsafePath)
+	if followLink || cleanPath == "/" {
+		return securejoin.SecureJoinVFS(root, cleanPath, vfs)
+	}
+	dir, err := securejoin.SecureJoinVFS(root, path.Dir(cleanPath), vfs)
 	if err != nil {
-		return err
+		return "", err
+	}
+	return path.Join(dir, path.Base(cleanPath)), nil
+}
+
+func hasTrailingSeparatorOrDot(p string) bool {
+	return strings.HasSuffix(p, "/") || strings.HasSuffix(p, "/.")
+}
+
+// aferoVFS adapts an afero.Fs to the securejoin.VFS interface so that paths can be resolved against the service's
+// filesystem abstraction.
+type aferoVFS struct {
+	fs afero.Fs
+}
+
+func (v aferoVFS) Lstat(name string) (os.FileInfo, error) {
+	if lstater, ok := v.fs.(afero.Lstater); ok {
+		fi, _, err := lstater.LstatIfPossible(name)
+		return fi, err
+	}
+	return v.fs.Stat(name)
+}
+
+func (v aferoVFS) Readlink(name string) (string, error) {
+	if reader, ok := v.fs.(afero.LinkReader); ok {
+		return reader.ReadlinkIfPossible(name)
 	}
-	cmd.SetStdout(writer)
-	return cmd.Run()
+	return "", &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}
 }
 
 func (s *service) mountSnapshotForContainer(ctx context.Context, con containerd.Container) (string, func(), error) {
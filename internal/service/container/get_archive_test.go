@@ -22,7 +22,6 @@ import (
 	"github.com/runfinch/finch-daemon/mocks/mocks_archive"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
-	"github.com/runfinch/finch-daemon/mocks/mocks_ecc"
 	"github.com/runfinch/finch-daemon/mocks/mocks_http"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
@@ -43,7 +42,6 @@ var _ = Describe("Container Get Archive API", func() {
 		mockPid       uint32
 		mockPath      string
 		mockWriter    *mocks_http.MockResponseWriter
-		mockCmd       *mocks_ecc.MockExecCmd
 		s             *service
 		containerPath string
 	)
@@ -63,7 +61,6 @@ var _ = Describe("Container Get Archive API", func() {
 		con = mocks_container.NewMockContainer(mockCtrl)
 		task = mocks_container.NewMockTask(mockCtrl)
 		mockWriter = mocks_http.NewMockResponseWriter(mockCtrl)
-		mockCmd = mocks_ecc.NewMockExecCmd(mockCtrl)
 		con.EXPECT().ID().Return(cid).AnyTimes()
 		s = &service{
 			client:           cdClient,
@@ -227,28 +224,51 @@ var _ = Describe("Container Get Archive API", func() {
 	})
 	Context("WriteFilesAsTarArchive", func() {
 		It("should return no error on success", func() {
-			tarCreator.EXPECT().CreateTarCommand(mockPath, false).Return(mockCmd, nil)
-			mockCmd.EXPECT().SetStdout(mockWriter)
-			mockCmd.EXPECT().Run().Return(nil)
+			tarCreator.EXPECT().CreateTar(mockPath, mockWriter, false).Return(nil)
 
 			err := s.WriteFilesAsTarArchive(mockPath, mockWriter, false)
 			Expect(err).Should(BeNil())
 		})
-		It("should pass through errors from CreateTarCommand", func() {
-			tarCreator.EXPECT().CreateTarCommand(mockPath, false).Return(nil, fmt.Errorf("CreateTarCommand error"))
+		It("should pass through errors from CreateTar", func() {
+			tarCreator.EXPECT().CreateTar(mockPath, mockWriter, true).Return(fmt.Errorf("CreateTar error"))
 
-			err := s.WriteFilesAsTarArchive(mockPath, mockWriter, false)
+			err := s.WriteFilesAsTarArchive(mockPath, mockWriter, true)
 			Expect(err).ShouldNot(BeNil())
-			Expect(err.Error()).Should(Equal("CreateTarCommand error"))
+			Expect(err.Error()).Should(Equal("CreateTar error"))
+		})
+	})
+	Context("resolvePathInRoot", func() {
+		var root string
+		BeforeEach(func() {
+			s.fs = afero.NewOsFs()
+			root = GinkgoT().TempDir()
+			Expect(os.MkdirAll(pathutil.Join(root, "etc"), 0o755)).Should(Succeed())
+			Expect(os.Symlink("/etc", pathutil.Join(root, "abs-link"))).Should(Succeed())
+			Expect(os.Symlink("../../../..", pathutil.Join(root, "etc", "rel-link"))).Should(Succeed())
+		})
+		It("should resolve absolute symlinks inside the root", func() {
+			path, err := s.resolvePathInRoot(root, "/abs-link/passwd", false)
+			Expect(err).Should(BeNil())
+			Expect(path).Should(Equal(pathutil.Join(root, "etc", "passwd")))
+		})
+		It("should not escape the root through relative symlinks", func() {
+			path, err := s.resolvePathInRoot(root, "/etc/rel-link/etc/shadow", false)
+			Expect(err).Should(BeNil())
+			Expect(path).Should(Equal(pathutil.Join(root, "etc", "shadow")))
 		})
-		It("should pass through errors from cmd.Run", func() {
-			tarCreator.EXPECT().CreateTarCommand(mockPath, false).Return(mockCmd, nil)
-			mockCmd.EXPECT().SetStdout(mockWriter)
-			mockCmd.EXPECT().Run().Return(fmt.Errorf("cmd.Run error"))
+		It("should not escape the root through dot-dot components", func() {
+			path, err := s.resolvePathInRoot(root, "../../etc", true)
+			Expect(err).Should(BeNil())
+			Expect(path).Should(Equal(pathutil.Join(root, "etc")))
+		})
+		It("should only follow a trailing symlink when asked to", func() {
+			path, err := s.resolvePathInRoot(root, "/abs-link", false)
+			Expect(err).Should(BeNil())
+			Expect(path).Should(Equal(pathutil.Join(root, "abs-link")))
 
-			err := s.WriteFilesAsTarArchive(mockPath, mockWriter, false)
-			Expect(err).ShouldNot(BeNil())
-			Expect(err.Error()).Should(Equal("cmd.Run error"))
+			path, err = s.resolvePathInRoot(root, "/abs-link", true)
+			Expect(err).Should(BeNil())
+			Expect(path).Should(Equal(pathutil.Join(root, "etc")))
 		})
 	})
 })
@@ -7,15 +7,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/typeurl/v2"
+	"github.com/containernetworking/cni/libcni"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/moby/moby/api/types/blkiodev"
+	"github.com/opencontainers/go-digest"
+
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 )
 
-const networkPrefix = "unknown-eth"
+const (
+	networkPrefix = "unknown-eth"
+	// defaultBridgeNetwork is the network containers are attached to when no network is specified.
+	defaultBridgeNetwork = "bridge"
+)
 
 func (s *service) Inspect(ctx context.Context, cid string, sizeFlag bool) (*types.Container, error) {
 	c, err := s.getContainer(ctx, cid)
@@ -28,13 +44,18 @@ func (s *service) Inspect(ctx context.Context, cid string, sizeFlag bool) (*type
 		return nil, err
 	}
 
+	extensions, err := c.Extensions(ctx)
+	if err != nil {
+		s.logger.Warnf("failed to get the extensions of container %s: %s", cid, err)
+	}
+
 	// translate to a finch-daemon container inspect type
 	cont := types.Container{
 		ID:              inspect.ID,
 		Created:         inspect.Created,
 		Path:            inspect.Path,
 		Args:            inspect.Args,
-		State:           inspect.State,
+		State:           getState(inspect.State, isOOMKilled(extensions)),
 		Image:           inspect.Image,
 		ResolvConfPath:  inspect.ResolvConfPath,
 		HostnamePath:    inspect.HostnamePath,
@@ -44,8 +65,8 @@ func (s *service) Inspect(ctx context.Context, cid string, sizeFlag bool) (*type
 		Driver:          inspect.Driver,
 		Platform:        inspect.Platform,
 		AppArmorProfile: inspect.AppArmorProfile,
+		ExecIDs:         getExecIDs(extensions),
 		Mounts:          inspect.Mounts,
-		NetworkSettings: inspect.NetworkSettings,
 		SizeRw:          inspect.SizeRw,
 		SizeRootFs:      inspect.SizeRootFs,
 	}
@@ -67,24 +88,61 @@ func (s *service) Inspect(ctx context.Context, cid string, sizeFlag bool) (*type
 
 	cont.HostConfig = getHostConfigFromDockerCompat(inspect.HostConfig)
 
+	// return the configuration the container was created with, completed with the state of the container.
+	config, err := getCreateConfig(extensions)
+	if err != nil {
+		s.logger.Warnf("failed to get the create config of container %s: %s", cid, err)
+	} else if config != nil {
+		cont.Config = mergeConfig(&config.Config, cont.Config)
+		cont.HostConfig = mergeHostConfig(&config.HostConfig, cont.HostConfig)
+	}
+
 	l, err := c.Labels(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get container labels: %s", err)
 	}
-	updateNetworkSettings(ctx, cont.NetworkSettings, l)
+	cont.NetworkSettings = s.getNetworkSettings(ctx, inspect, l)
 
 	// make sure it passes the default time value for time fields otherwise the goclient fails.
 	if inspect.Created == "" {
 		cont.Created = "0001-01-01T00:00:00Z"
 	}
 
-	if inspect.State != nil && inspect.State.FinishedAt == "" {
+	if cont.State != nil && cont.State.FinishedAt == "" {
 		cont.State.FinishedAt = "0001-01-01T00:00:00Z"
 	}
 
 	return &cont, nil
 }
 
+// getExecIDs returns the IDs of the exec instances of a container whose process has not exited,
+// or nil if there are none, like docker.
+func getExecIDs(extensions map[string]typeurl.Any) []string {
+	execs, err := execstore.List(extensions)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, exec := range execs {
+		if exec.ExitCode == nil {
+			ids = append(ids, exec.ID)
+		}
+	}
+	return ids
+}
+
+// getState completes the state reported by nerdctl with whether the container was OOM killed. The exit code
+// is the one of the task, which is not necessarily the one of a SIGKILL when the kernel killed a process.
+func getState(state *dockercompat.ContainerState, oomKilled bool) *types.ContainerState {
+	if state == nil {
+		return nil
+	}
+	return &types.ContainerState{
+		ContainerState: *state,
+		OOMKilled:      oomKilled,
+	}
+}
+
 func getHostConfigFromDockerCompat(c *dockercompat.HostConfig) *types.ContainerHostConfig {
 	if c == nil {
 		return nil
@@ -105,27 +163,343 @@ func getHostConfigFromDockerCompat(c *dockercompat.HostConfig) *types.ContainerH
 			Type:   c.LogConfig.Driver,
 			Config: c.LogConfig.Opts,
 		},
-		PortBindings:   c.PortBindings,
-		DNS:            c.DNS,
-		DNSOptions:     c.DNSOptions,
-		DNSSearch:      c.DNSSearch,
-		IpcMode:        c.IpcMode,
-		PidMode:        c.PidMode,
-		ReadonlyRootfs: c.ReadonlyRootfs,
-		ShmSize:        c.ShmSize,
-		Sysctls:        c.Sysctls,
-		CPUSetMems:     c.CPUSetMems,
-		CPUSetCPUs:     c.CPUSetCPUs,
-		CPUShares:      int64(c.CPUShares),
-		CPUPeriod:      int64(c.CPUPeriod),
-		Memory:         c.Memory,
-		MemorySwap:     c.MemorySwap,
-		OomKillDisable: c.OomKillDisable,
-		Devices:        hostConfigDevices,
-		// TODO: Uncomment these when https://github.com/runfinch/finch-daemon/pull/267 gets merged
-		// CPURealtimePeriod: inspect.HostConfig.CPURealtimePeriod,
-		// CPURealtimeRuntime: inspect.HostConfig.CPURealtimeRuntime,
-		// TODO: add blkio devices which requires a change in the dockercompat response from Nerdctl
+		PortBindings:         c.PortBindings,
+		CgroupnsMode:         types.CgroupnsMode(c.CgroupnsMode),
+		DNS:                  c.DNS,
+		DNSOptions:           c.DNSOptions,
+		DNSSearch:            c.DNSSearch,
+		ExtraHosts:           c.ExtraHosts,
+		GroupAdd:             c.GroupAdd,
+		IpcMode:              c.IpcMode,
+		PidMode:              c.PidMode,
+		ReadonlyRootfs:       c.ReadonlyRootfs,
+		Tmpfs:                c.Tmpfs,
+		UTSMode:              c.UTSMode,
+		ShmSize:              c.ShmSize,
+		Sysctls:              c.Sysctls,
+		Runtime:              c.Runtime,
+		CPUSetMems:           c.CPUSetMems,
+		CPUSetCPUs:           c.CPUSetCPUs,
+		CPUShares:            int64(c.CPUShares),
+		CPUPeriod:            int64(c.CPUPeriod),
+		CPUQuota:             c.CPUQuota,
+		CPURealtimePeriod:    int64(c.CPURealtimePeriod),
+		CPURealtimeRuntime:   c.CPURealtimeRuntime,
+		Memory:               c.Memory,
+		MemorySwap:           c.MemorySwap,
+		OomKillDisable:       c.OomKillDisable,
+		Devices:              hostConfigDevices,
+		BlkioWeight:          c.BlkioWeight,
+		BlkioWeightDevice:    getWeightDevices(c.BlkioWeightDevice),
+		BlkioDeviceReadBps:   getThrottleDevices(c.BlkioDeviceReadBps),
+		BlkioDeviceWriteBps:  getThrottleDevices(c.BlkioDeviceWriteBps),
+		BlkioDeviceReadIOps:  getThrottleDevices(c.BlkioDeviceReadIOps),
+		BlkioDeviceWriteIOps: getThrottleDevices(c.BlkioDeviceWriteIOps),
+	}
+}
+
+func getWeightDevices(devices []*dockercompat.WeightDevice) []*blkiodev.WeightDevice {
+	var result []*blkiodev.WeightDevice
+	for _, d := range devices {
+		result = append(result, &blkiodev.WeightDevice{Path: d.Path, Weight: d.Weight})
+	}
+	return result
+}
+
+func getThrottleDevices(devices []*dockercompat.ThrottleDevice) []*blkiodev.ThrottleDevice {
+	var result []*blkiodev.ThrottleDevice
+	for _, d := range devices {
+		result = append(result, &blkiodev.ThrottleDevice{Path: d.Path, Rate: d.Rate})
+	}
+	return result
+}
+
+// mergeConfig returns the config a container was created with, where the fields that were not
+// requested are set from the config reported by the runtime, e.g. the command of the image.
+func mergeConfig(requested, actual *types.ContainerConfig) *types.ContainerConfig {
+	merged := *requested
+	if actual == nil {
+		return &merged
+	}
+	merged.Hostname = valueOr(merged.Hostname, actual.Hostname)
+	merged.User = valueOr(merged.User, actual.User)
+	merged.WorkingDir = valueOr(merged.WorkingDir, actual.WorkingDir)
+	merged.Image = valueOr(merged.Image, actual.Image)
+	merged.Cmd = sliceOr(merged.Cmd, actual.Cmd)
+	merged.Entrypoint = sliceOr(merged.Entrypoint, actual.Entrypoint)
+	merged.Labels = mapOr(merged.Labels, actual.Labels)
+	merged.ExposedPorts = mapOr(merged.ExposedPorts, actual.ExposedPorts)
+	merged.Volumes = mapOr(merged.Volumes, actual.Volumes)
+	// the environment of the container also includes the environment of its image.
+	merged.Env = sliceOr(actual.Env, merged.Env)
+	merged.Tty = actual.Tty
+	return &merged
+}
+
+// mergeHostConfig returns the host config a container was created with, where the fields that were not
+// requested are set to the defaults applied by the runtime.
+func mergeHostConfig(requested, actual *types.ContainerHostConfig) *types.ContainerHostConfig {
+	merged := *requested
+	if merged.NetworkMode == "" || merged.NetworkMode == "default" {
+		merged.NetworkMode = defaultBridgeNetwork
+	}
+	if merged.RestartPolicy.Name == "" {
+		merged.RestartPolicy.Name = "no"
+	}
+	if actual == nil {
+		return &merged
+	}
+	if merged.LogConfig.Type == "" {
+		merged.LogConfig = actual.LogConfig
+	}
+	merged.CgroupnsMode = valueOr(merged.CgroupnsMode, actual.CgroupnsMode)
+	merged.IpcMode = valueOr(merged.IpcMode, actual.IpcMode)
+	merged.PidMode = valueOr(merged.PidMode, actual.PidMode)
+	merged.UTSMode = valueOr(merged.UTSMode, actual.UTSMode)
+	merged.Runtime = valueOr(merged.Runtime, actual.Runtime)
+	merged.ShmSize = valueOr(merged.ShmSize, actual.ShmSize)
+	merged.DNS = sliceOr(merged.DNS, actual.DNS)
+	merged.DNSOptions = sliceOr(merged.DNSOptions, actual.DNSOptions)
+	merged.DNSSearch = sliceOr(merged.DNSSearch, actual.DNSSearch)
+	merged.Sysctls = mapOr(merged.Sysctls, actual.Sysctls)
+	merged.CPUShares = valueOr(merged.CPUShares, actual.CPUShares)
+	merged.CPUPeriod = valueOr(merged.CPUPeriod, actual.CPUPeriod)
+	merged.CPUQuota = valueOr(merged.CPUQuota, actual.CPUQuota)
+	merged.CPURealtimePeriod = valueOr(merged.CPURealtimePeriod, actual.CPURealtimePeriod)
+	merged.CPURealtimeRuntime = valueOr(merged.CPURealtimeRuntime, actual.CPURealtimeRuntime)
+	merged.CPUSetCPUs = valueOr(merged.CPUSetCPUs, actual.CPUSetCPUs)
+	merged.CPUSetMems = valueOr(merged.CPUSetMems, actual.CPUSetMems)
+	merged.MemorySwap = valueOr(merged.MemorySwap, actual.MemorySwap)
+	merged.BlkioWeight = valueOr(merged.BlkioWeight, actual.BlkioWeight)
+	return &merged
+}
+
+// valueOr returns v, or fallback if v is the zero value of its type.
+func valueOr[T comparable](v, fallback T) T {
+	var zero T
+	if v == zero {
+		return fallback
+	}
+	return v
+}
+
+func sliceOr[S ~[]E, E any](v, fallback S) S {
+	if len(v) == 0 {
+		return fallback
+	}
+	return v
+}
+
+func mapOr[M ~map[K]V, K comparable, V any](v, fallback M) M {
+	if len(v) == 0 {
+		return fallback
+	}
+	return v
+}
+
+// getNetworkSettings translates the network settings reported by nerdctl to docker's format and completes
+// them with the details of every network the container is attached to. Docker identifies networks by their
+// name in "NetworkSettings", but nerdctl uses a sequential ordering "unknown-eth0", "unknown-eth1",...
+// we use container labels to find the corresponding name for each network. Gateways are read from the
+// results cached by CNI when the container was attached to each network.
+func (s *service) getNetworkSettings(ctx context.Context, inspect *dockercompat.Container, lab map[string]string) *types.NetworkSettings {
+	ns := inspect.NetworkSettings
+	if ns == nil {
+		return nil
+	}
+	settings := &types.NetworkSettings{
+		Ports: ns.Ports,
+		DefaultNetworkSettings: types.DefaultNetworkSettings{
+			IPAddress:           ns.IPAddress,
+			IPPrefixLen:         ns.IPPrefixLen,
+			GlobalIPv6Address:   ns.GlobalIPv6Address,
+			GlobalIPv6PrefixLen: ns.GlobalIPv6PrefixLen,
+			MacAddress:          ns.MacAddress,
+		},
+		Networks: map[string]*types.NetworkEndpointSettings{},
+	}
+
+	// nerdctl creates the interfaces eth0, eth1, ... in the order of the networks stored in the labels.
+	names := getNetworkNames(lab)
+	for _, name := range names {
+		if strings.HasPrefix(name, "container:") {
+			continue
+		}
+		settings.Networks[name] = &types.NetworkEndpointSettings{}
+	}
+	for network, es := range ns.Networks {
+		name := getNetworkName(lab, network)
+		ep, ok := settings.Networks[name]
+		if !ok {
+			ep = &types.NetworkEndpointSettings{}
+			settings.Networks[name] = ep
+		}
+		ep.IPAddress = es.IPAddress
+		ep.IPPrefixLen = es.IPPrefixLen
+		ep.GlobalIPv6Address = es.GlobalIPv6Address
+		ep.GlobalIPv6PrefixLen = es.GlobalIPv6PrefixLen
+		ep.MacAddress = es.MacAddress
+	}
+
+	netConfs := map[string]*netutil.NetworkConfig{}
+	if len(names) > 0 {
+		confs, err := s.nctlContainerSvc.FilterNetworks(func(conf *netutil.NetworkConfig) bool {
+			return slices.Contains(names, conf.Name)
+		})
+		if err != nil {
+			s.logger.Warnf("failed to list networks of container %s: %s", inspect.ID, err)
+		}
+		for _, conf := range confs {
+			netConfs[conf.Name] = conf
+		}
+	}
+
+	running := inspect.State != nil && inspect.State.Running && inspect.State.Pid > 0
+	if running {
+		settings.SandboxKey = fmt.Sprintf("/proc/%d/ns/net", inspect.State.Pid)
+		netNS, err := s.nctlContainerSvc.InspectNetNS(ctx, inspect.State.Pid)
+		if err != nil {
+			s.logger.Warnf("failed to inspect network namespace of container %s: %s", inspect.ID, err)
+		} else {
+			updateEndpointsFromNetNS(settings.Networks, netNS, names, netConfs)
+		}
+	}
+
+	for i, name := range names {
+		ep, ok := settings.Networks[name]
+		if !ok {
+			continue
+		}
+		conf, ok := netConfs[name]
+		if !ok {
+			continue
+		}
+		if conf.NerdctlID != nil {
+			ep.NetworkID = *conf.NerdctlID
+		}
+		if name != defaultBridgeNetwork {
+			ep.Aliases = networkAliases(inspect)
+		}
+		// like docker, only the endpoints of a running container are identified.
+		if !running {
+			continue
+		}
+		ep.EndpointID = endpointID(inspect.ID, name, ep.NetworkID)
+		result, err := s.nctlContainerSvc.GetNetworkListCachedResult(conf.NetworkConfigList, &libcni.RuntimeConf{
+			ContainerID: inspect.ID,
+			IfName:      fmt.Sprintf("eth%d", i),
+		})
+		if err != nil || result == nil {
+			s.logger.Debugf("no cached CNI result for container %s on network %s: %v", inspect.ID, name, err)
+			continue
+		}
+		updateEndpointFromCNIResult(ep, result)
+	}
+
+	if len(names) > 0 {
+		if primary, ok := settings.Networks[names[0]]; ok {
+			settings.EndpointID = primary.EndpointID
+			settings.Gateway = primary.Gateway
+			settings.IPv6Gateway = primary.IPv6Gateway
+			if primary.IPAddress != "" || primary.GlobalIPv6Address != "" {
+				settings.IPAddress = primary.IPAddress
+				settings.IPPrefixLen = primary.IPPrefixLen
+				settings.GlobalIPv6Address = primary.GlobalIPv6Address
+				settings.GlobalIPv6PrefixLen = primary.GlobalIPv6PrefixLen
+				settings.MacAddress = primary.MacAddress
+			}
+		}
+	}
+	return settings
+}
+
+// endpointID returns the ID of the endpoint of a container on a network. nerdctl and CNI do not identify endpoints,
+// so the ID is derived from the IDs of the container and of the network, which makes it stable across inspects.
+func endpointID(containerID, networkName, networkID string) string {
+	if networkID == "" {
+		networkID = networkName
+	}
+	return digest.FromString(containerID + networkID).Encoded()
+}
+
+// networkAliases returns the names the container is resolvable by on a user defined network, which are the names
+// nerdctl writes to the hosts files of the containers sharing the network: its hostname and its name.
+func networkAliases(inspect *dockercompat.Container) []string {
+	var aliases []string
+	if inspect.Config != nil && inspect.Config.Hostname != "" {
+		aliases = append(aliases, inspect.Config.Hostname)
+	}
+	if name := strings.TrimPrefix(inspect.Name, "/"); name != "" && !slices.Contains(aliases, name) {
+		aliases = append(aliases, name)
+	}
+	return aliases
+}
+
+// updateEndpointsFromNetNS sets the addresses of every CNI network the container is attached to from
+// the interfaces found in its network namespace.
+func updateEndpointsFromNetNS(
+	endpoints map[string]*types.NetworkEndpointSettings,
+	netNS *native.NetNS,
+	names []string,
+	netConfs map[string]*netutil.NetworkConfig,
+) {
+	for _, iface := range netNS.Interfaces {
+		index, err := strconv.Atoi(strings.TrimPrefix(iface.Name, "eth"))
+		if !strings.HasPrefix(iface.Name, "eth") || err != nil || index >= len(names) {
+			continue
+		}
+		// interfaces of host-like networks do not belong to the container
+		if _, ok := netConfs[names[index]]; !ok {
+			continue
+		}
+		ep, ok := endpoints[names[index]]
+		if !ok {
+			continue
+		}
+		ep.MacAddress = iface.HardwareAddr
+		for _, addr := range iface.Addrs {
+			ip, ipNet, err := net.ParseCIDR(addr)
+			if err != nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			ones, _ := ipNet.Mask.Size()
+			if ip.To4() != nil {
+				ep.IPAddress = ip.String()
+				ep.IPPrefixLen = ones
+			} else {
+				ep.GlobalIPv6Address = ip.String()
+				ep.GlobalIPv6PrefixLen = ones
+			}
+		}
+	}
+}
+
+// updateEndpointFromCNIResult sets the gateways, and the addresses if they are still unknown, of an
+// endpoint from the result returned by CNI when the container was attached to the network.
+func updateEndpointFromCNIResult(ep *types.NetworkEndpointSettings, result cnitypes.Result) {
+	res, err := current.NewResultFromResult(result)
+	if err != nil {
+		return
+	}
+	for _, ipConfig := range res.IPs {
+		ones, _ := ipConfig.Address.Mask.Size()
+		if ipConfig.Address.IP.To4() != nil {
+			if ipConfig.Gateway != nil {
+				ep.Gateway = ipConfig.Gateway.String()
+			}
+			if ep.IPAddress == "" {
+				ep.IPAddress = ipConfig.Address.IP.String()
+				ep.IPPrefixLen = ones
+			}
+		} else {
+			if ipConfig.Gateway != nil {
+				ep.IPv6Gateway = ipConfig.Gateway.String()
+			}
+			if ep.GlobalIPv6Address == "" {
+				ep.GlobalIPv6Address = ipConfig.Address.IP.String()
+				ep.GlobalIPv6PrefixLen = ones
+			}
+		}
 	}
 }
 
@@ -146,15 +520,24 @@ func updateNetworkSettings(ctx context.Context, ns *dockercompat.NetworkSettings
 	return nil
 }
 
-// getNetworkName gets network name from container labels using the index specified by the network prefix.
-// returns the default prefix if network name was not found.
-func getNetworkName(lab map[string]string, network string) string {
+// getNetworkNames returns the names of the networks a container was created with, in order.
+func getNetworkNames(lab map[string]string) []string {
 	namesJSON, ok := lab[labels.Networks]
 	if !ok {
-		return network
+		return nil
 	}
 	var names []string
 	if err := json.Unmarshal([]byte(namesJSON), &names); err != nil {
+		return nil
+	}
+	return names
+}
+
+// getNetworkName gets network name from container labels using the index specified by the network prefix.
+// returns the default prefix if network name was not found.
+func getNetworkName(lab map[string]string, network string) string {
+	names := getNetworkNames(lab)
+	if names == nil {
 		return network
 	}
 
@@ -6,16 +6,27 @@ package container
 import (
 	"context"
 	"errors"
+	"net"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/typeurl/v2"
+	"github.com/containernetworking/cni/libcni"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
 	"github.com/docker/go-connections/nat"
+	"github.com/moby/moby/api/types/blkiodev"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
 	"go.uber.org/mock/gomock"
 
 	"github.com/runfinch/finch-daemon/api/handlers/container"
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
@@ -30,6 +41,7 @@ var _ = Describe("Container Inspect API ", func() {
 		logger   *mocks_logger.Logger
 		cdClient *mocks_backend.MockContainerdClient
 		ncClient *mocks_backend.MockNerdctlContainerSvc
+		ncNet    *mocks_backend.MockNerdctlNetworkSvc
 		con      *mocks_container.MockContainer
 		cid      string
 		img      string
@@ -44,6 +56,7 @@ var _ = Describe("Container Inspect API ", func() {
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
 		ncClient = mocks_backend.NewMockNerdctlContainerSvc(mockCtrl)
+		ncNet = mocks_backend.NewMockNerdctlNetworkSvc(mockCtrl)
 		con = mocks_container.NewMockContainer(mockCtrl)
 		cid = "123"
 		img = "test-image"
@@ -76,7 +89,7 @@ var _ = Describe("Container Inspect API ", func() {
 			},
 		}
 
-		service = NewService(cdClient, mockNerdctlService{ncClient, nil}, logger, nil, nil, nil)
+		service = NewService(cdClient, mockNerdctlService{ncClient, ncNet}, logger, nil, nil, nil)
 	})
 	Context("service", func() {
 		It("should return the inspect object upon success", func() {
@@ -87,6 +100,7 @@ var _ = Describe("Container Inspect API ", func() {
 
 			ncClient.EXPECT().InspectContainer(gomock.Any(), con, sizeFlag).Return(
 				&inspect, nil)
+			con.EXPECT().Extensions(gomock.Any()).Return(nil, nil)
 			con.EXPECT().Labels(gomock.Any()).Return(nil, nil)
 			result, err := service.Inspect(ctx, cid, sizeFlag)
 
@@ -170,12 +184,88 @@ var _ = Describe("Container Inspect API ", func() {
 			ncClient.EXPECT().InspectContainer(gomock.Any(), con, false).Return(
 				&inspectWithHostConfig, nil)
 
+			con.EXPECT().Extensions(gomock.Any()).Return(nil, nil)
 			con.EXPECT().Labels(gomock.Any()).Return(nil, nil)
 			result, err := service.Inspect(ctx, cid, false)
 
 			Expect(*result).Should(Equal(retWithHostConfig))
 			Expect(err).Should(BeNil())
 		})
+		It("should return the config the container was created with merged with its state", func() {
+			inspectWithHostConfig := inspect
+			inspectWithHostConfig.Config = &dockercompat.Config{
+				Hostname: "test-hostname",
+				Env:      []string{"PATH=/usr/bin", "FOO=bar"},
+				Cmd:      []string{"/bin/sh"},
+			}
+			inspectWithHostConfig.HostConfig = &dockercompat.HostConfig{
+				IpcMode: "private",
+				Runtime: "io.containerd.runc.v2",
+				BlkioSettings: dockercompat.BlkioSettings{
+					BlkioDeviceReadBps: []*dockercompat.ThrottleDevice{{Path: "/dev/sda", Rate: 1024}},
+				},
+			}
+			stopTimeout := 5
+			ext, err := typeurl.MarshalAny(&createConfig{
+				Config: types.ContainerConfig{
+					Env:         []string{"FOO=bar"},
+					Image:       img,
+					Labels:      map[string]string{"foo": "bar"},
+					StopTimeout: &stopTimeout,
+				},
+				HostConfig: types.ContainerHostConfig{
+					Memory:             1024,
+					CPURealtimeRuntime: 950,
+					BlkioDeviceReadBps: []*blkiodev.ThrottleDevice{{Path: "/dev/sda", Rate: 1024}},
+				},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
+				[]containerd.Container{con}, nil)
+			ncClient.EXPECT().InspectContainer(gomock.Any(), con, false).Return(
+				&inspectWithHostConfig, nil)
+			con.EXPECT().Extensions(gomock.Any()).Return(map[string]typeurl.Any{createConfigExtension: ext}, nil)
+			con.EXPECT().Labels(gomock.Any()).Return(nil, nil)
+
+			result, err := service.Inspect(ctx, cid, false)
+			Expect(err).Should(BeNil())
+			Expect(*result.Config).Should(Equal(types.ContainerConfig{
+				Hostname:    "test-hostname",
+				Env:         []string{"PATH=/usr/bin", "FOO=bar"},
+				Cmd:         []string{"/bin/sh"},
+				Image:       img,
+				Labels:      map[string]string{"foo": "bar"},
+				StopTimeout: &stopTimeout,
+			}))
+			Expect(result.HostConfig.Memory).Should(Equal(int64(1024)))
+			Expect(result.HostConfig.CPURealtimeRuntime).Should(Equal(int64(950)))
+			Expect(result.HostConfig.BlkioDeviceReadBps).Should(Equal([]*blkiodev.ThrottleDevice{{Path: "/dev/sda", Rate: 1024}}))
+			Expect(result.HostConfig.IpcMode).Should(Equal("private"))
+			Expect(result.HostConfig.Runtime).Should(Equal("io.containerd.runc.v2"))
+			Expect(result.HostConfig.NetworkMode).Should(Equal("bridge"))
+			Expect(result.HostConfig.RestartPolicy.Name).Should(Equal("no"))
+		})
+		It("should return the IDs of the exec instances which have not exited", func() {
+			exitCode := 0
+			ext, err := typeurl.MarshalAny(&execstore.Execs{Execs: []*execstore.Exec{
+				{ID: "exec1", ContainerID: cid, Running: true},
+				{ID: "exec2", ContainerID: cid, ExitCode: &exitCode},
+				{ID: "exec3", ContainerID: cid},
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
+				[]containerd.Container{con}, nil)
+			ncClient.EXPECT().InspectContainer(gomock.Any(), con, false).Return(
+				&inspect, nil)
+			con.EXPECT().Extensions(gomock.Any()).Return(map[string]typeurl.Any{execstore.Extension: ext}, nil)
+			con.EXPECT().Labels(gomock.Any()).Return(nil, nil)
+
+			result, err := service.Inspect(ctx, cid, false)
+			Expect(err).Should(BeNil())
+			Expect(result.ExecIDs).Should(Equal([]string{"exec1", "exec3"}))
+		})
 		It("should return NotFound error if container was not found", func() {
 			// search container method returns no container
 			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
@@ -223,6 +313,144 @@ var _ = Describe("Container Inspect API ", func() {
 			Expect(err).ShouldNot(BeNil())
 		})
 	})
+	Context("network settings", func() {
+		var (
+			bridgeID string
+			customID string
+			netLabel map[string]string
+		)
+		BeforeEach(func() {
+			cid = "0123456789abcdef0123456789abcdef"
+			inspect.ID = cid
+			bridgeID = "bridge-id"
+			customID = "custom-id"
+			netLabel = map[string]string{labels.Networks: `["bridge","custom"]`}
+			ports := nat.PortMap{
+				"80/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "8080"}},
+			}
+			inspect.NetworkSettings = &dockercompat.NetworkSettings{
+				Ports: &ports,
+				DefaultNetworkSettings: dockercompat.DefaultNetworkSettings{
+					IPAddress:   "10.4.0.2",
+					IPPrefixLen: 24,
+					MacAddress:  "aa:aa:aa:aa:aa:aa",
+				},
+				Networks: map[string]*dockercompat.NetworkEndpointSettings{
+					"unknown-eth0": {IPAddress: "10.4.0.2", IPPrefixLen: 24, MacAddress: "aa:aa:aa:aa:aa:aa"},
+					"unknown-eth1": {IPAddress: "10.5.0.2", IPPrefixLen: 24, MacAddress: "bb:bb:bb:bb:bb:bb"},
+				},
+			}
+			ncNet.EXPECT().FilterNetworks(gomock.Any()).DoAndReturn(
+				func(filterf func(*netutil.NetworkConfig) bool) ([]*netutil.NetworkConfig, error) {
+					var res []*netutil.NetworkConfig
+					for _, conf := range []*netutil.NetworkConfig{
+						{NetworkConfigList: &libcni.NetworkConfigList{Name: "bridge"}, NerdctlID: &bridgeID},
+						{NetworkConfigList: &libcni.NetworkConfigList{Name: "custom"}, NerdctlID: &customID},
+						{NetworkConfigList: &libcni.NetworkConfigList{Name: "other"}},
+					} {
+						if filterf(conf) {
+							res = append(res, conf)
+						}
+					}
+					return res, nil
+				})
+		})
+		It("should report every attached network of a running container", func() {
+			inspect.State = &dockercompat.ContainerState{Running: true, Pid: 1234}
+			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return([]containerd.Container{con}, nil)
+			ncClient.EXPECT().InspectContainer(gomock.Any(), con, false).Return(&inspect, nil)
+			con.EXPECT().Extensions(gomock.Any()).Return(nil, nil)
+			con.EXPECT().Labels(gomock.Any()).Return(netLabel, nil)
+			ncClient.EXPECT().InspectNetNS(gomock.Any(), 1234).Return(&native.NetNS{
+				Interfaces: []native.NetInterface{
+					{Interface: net.Interface{Name: "lo"}, Addrs: []string{"127.0.0.1/8"}},
+					{
+						Interface:    net.Interface{Name: "eth0"},
+						HardwareAddr: "aa:aa:aa:aa:aa:aa",
+						Addrs:        []string{"10.4.0.2/24", "fe80::1/64"},
+					},
+					{
+						Interface:    net.Interface{Name: "eth1"},
+						HardwareAddr: "bb:bb:bb:bb:bb:bb",
+						Addrs:        []string{"10.5.0.2/24", "fd00::2/64"},
+					},
+				},
+			}, nil)
+			ncNet.EXPECT().GetNetworkListCachedResult(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(list *libcni.NetworkConfigList, rt *libcni.RuntimeConf) (cnitypes.Result, error) {
+					Expect(rt.ContainerID).Should(Equal(cid))
+					result := &current.Result{CNIVersion: "1.0.0"}
+					switch list.Name {
+					case "bridge":
+						Expect(rt.IfName).Should(Equal("eth0"))
+						_, ipNet, _ := net.ParseCIDR("10.4.0.2/24")
+						ipNet.IP = net.ParseIP("10.4.0.2")
+						result.IPs = []*current.IPConfig{{Address: *ipNet, Gateway: net.ParseIP("10.4.0.1")}}
+					case "custom":
+						Expect(rt.IfName).Should(Equal("eth1"))
+						_, ipNet, _ := net.ParseCIDR("10.5.0.2/24")
+						ipNet.IP = net.ParseIP("10.5.0.2")
+						_, ip6Net, _ := net.ParseCIDR("fd00::2/64")
+						ip6Net.IP = net.ParseIP("fd00::2")
+						result.IPs = []*current.IPConfig{
+							{Address: *ipNet, Gateway: net.ParseIP("10.5.0.1")},
+							{Address: *ip6Net, Gateway: net.ParseIP("fd00::1")},
+						}
+					}
+					return result, nil
+				}).Times(2)
+
+			result, err := service.Inspect(ctx, cid, false)
+			Expect(err).Should(BeNil())
+			ns := result.NetworkSettings
+			Expect(ns).ShouldNot(BeNil())
+			Expect(ns.SandboxKey).Should(Equal("/proc/1234/ns/net"))
+			Expect(*ns.Ports).Should(HaveKey(nat.Port("80/tcp")))
+			Expect(ns.Networks).Should(HaveLen(2))
+
+			bridge := ns.Networks["bridge"]
+			Expect(bridge.NetworkID).Should(Equal(bridgeID))
+			Expect(bridge.IPAddress).Should(Equal("10.4.0.2"))
+			Expect(bridge.Gateway).Should(Equal("10.4.0.1"))
+			Expect(bridge.MacAddress).Should(Equal("aa:aa:aa:aa:aa:aa"))
+			Expect(bridge.EndpointID).Should(Equal(digest.FromString(cid + bridgeID).Encoded()))
+			Expect(bridge.Aliases).Should(BeEmpty())
+
+			custom := ns.Networks["custom"]
+			Expect(custom.NetworkID).Should(Equal(customID))
+			Expect(custom.IPAddress).Should(Equal("10.5.0.2"))
+			Expect(custom.IPPrefixLen).Should(Equal(24))
+			Expect(custom.Gateway).Should(Equal("10.5.0.1"))
+			Expect(custom.GlobalIPv6Address).Should(Equal("fd00::2"))
+			Expect(custom.GlobalIPv6PrefixLen).Should(Equal(64))
+			Expect(custom.IPv6Gateway).Should(Equal("fd00::1"))
+			Expect(custom.MacAddress).Should(Equal("bb:bb:bb:bb:bb:bb"))
+			Expect(custom.EndpointID).Should(Equal(digest.FromString(cid + customID).Encoded()))
+			// the container is resolvable by its hostname and name on user defined networks
+			Expect(custom.Aliases).Should(Equal([]string{"test-hostname", "test-cont"}))
+
+			Expect(ns.IPAddress).Should(Equal("10.4.0.2"))
+			Expect(ns.Gateway).Should(Equal("10.4.0.1"))
+			Expect(ns.EndpointID).Should(Equal(bridge.EndpointID))
+		})
+		It("should report the attached networks of a stopped container without runtime state", func() {
+			inspect.State = &dockercompat.ContainerState{Running: false}
+			inspect.NetworkSettings.Networks = map[string]*dockercompat.NetworkEndpointSettings{}
+			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return([]containerd.Container{con}, nil)
+			ncClient.EXPECT().InspectContainer(gomock.Any(), con, false).Return(&inspect, nil)
+			con.EXPECT().Extensions(gomock.Any()).Return(nil, nil)
+			con.EXPECT().Labels(gomock.Any()).Return(netLabel, nil)
+
+			result, err := service.Inspect(ctx, cid, false)
+			Expect(err).Should(BeNil())
+			ns := result.NetworkSettings
+			Expect(ns.SandboxKey).Should(BeEmpty())
+			Expect(ns.Networks).Should(HaveLen(2))
+			Expect(ns.Networks["custom"].NetworkID).Should(Equal(customID))
+			Expect(ns.Networks["custom"].EndpointID).Should(BeEmpty())
+			Expect(ns.Networks["custom"].IPAddress).Should(BeEmpty())
+		})
+	})
 	Context("service with size flag", func() {
 		It("should return SizeRw and SizeRootFs when size flag is true", func() {
 			sizeFlag := true
@@ -238,6 +466,7 @@ var _ = Describe("Container Inspect API ", func() {
 
 			ncClient.EXPECT().InspectContainer(gomock.Any(), con, sizeFlag).Return(
 				&inspectWithSize, nil)
+			con.EXPECT().Extensions(gomock.Any()).Return(nil, nil)
 			con.EXPECT().Labels(gomock.Any()).Return(nil, nil)
 			result, err := service.Inspect(ctx, cid, sizeFlag)
 			Expect(err).Should(BeNil())
@@ -255,6 +484,7 @@ var _ = Describe("Container Inspect API ", func() {
 
 			ncClient.EXPECT().InspectContainer(gomock.Any(), con, sizeFlag).Return(
 				&inspect, nil)
+			con.EXPECT().Extensions(gomock.Any()).Return(nil, nil)
 			con.EXPECT().Labels(gomock.Any()).Return(nil, nil)
 			result, err := service.Inspect(ctx, cid, sizeFlag)
 			Expect(err).Should(BeNil())
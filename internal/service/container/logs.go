@@ -5,15 +5,33 @@ package container
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/runtime"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
 	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
 	"github.com/moby/moby/pkg/stdcopy"
 
 	"github.com/runfinch/finch-daemon/api/types"
 )
 
+const (
+	containerUpdateTopic = "/containers/update"
+	containerDeleteTopic = "/containers/delete"
+)
+
 // Logs attaches the stdout and stderr to the container using nerdctl logs.
 func (s *service) Logs(ctx context.Context, cid string, opts *types.LogsOptions) error {
 	// fetch container
@@ -65,11 +83,171 @@ func (s *service) Logs(ctx context.Context, cid string, opts *types.LogsOptions)
 		Tail:       uint(tail),
 		Since:      strconv.FormatInt(opts.Since, 10),
 		Until:      until,
+		Details:    opts.Details,
 	}
-	err = s.attachLogs(ctx, con, logOpts, stopChannel, printSuccessResp)
+	err = s.attachLogs(ctx, con, logOpts, stopChannel, printSuccessResp, true)
 	if err != nil {
 		s.logger.Debugf("failed to retrieve logs for the container: %s", cid)
 		return err
 	}
 	return nil
 }
+
+// waitForLogsEnd blocks until the task of a followed container exits. When followRestarts is set, it keeps
+// waiting through the restarts of the container by its restart policy, until the container exits for good or
+// is removed.
+func (s *service) waitForLogsEnd(
+	ctx context.Context,
+	con containerd.Container,
+	task containerd.Task,
+	waitCh <-chan containerd.ExitStatus,
+	followRestarts bool,
+) {
+	for {
+		var status containerd.ExitStatus
+		select {
+		case status = <-waitCh:
+		case <-ctx.Done():
+			return
+		}
+		if !followRestarts || !willRestart(ctx, con, status.ExitCode()) {
+			s.logger.Debugf("container task has finished, sending kill signal to log viewer")
+
+			// NOTE: This is a temporary workaround to fix the logger issue where strings without newline are not logged:
+			// https://github.com/containerd/nerdctl/issues/2313
+			// TODO: Remove this logic when the issue is fixed in nerdctl
+			// delete finished task to shutdown the logger and print buffered data
+			task.Delete(ctx)
+			time.Sleep(100 * time.Millisecond)
+			return
+		}
+
+		s.logger.Debugf("container %s is restarting, following the logs of its next task", con.ID())
+		var err error
+		if task, waitCh, err = s.waitForRestart(ctx, con, status.ExitCode()); err != nil {
+			s.logger.Debugf("stopped following the logs of container %s: %s", con.ID(), err)
+			return
+		}
+	}
+}
+
+// waitForRestart waits for the restart policy of a container to start a new task, and returns its wait channel.
+// It fails if the container is removed or no longer meant to be restarted, e.g. because it was stopped.
+func (s *service) waitForRestart(ctx context.Context, con containerd.Container, exitCode uint32) (containerd.Task, <-chan containerd.ExitStatus, error) {
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	eventCh, errCh := s.client.SubscribeToEvents(subCtx,
+		fmt.Sprintf(`topic==%q,event.container_id==%q`, runtime.TaskStartEventTopic, con.ID()),
+		fmt.Sprintf(`topic==%q,event.id==%q`, containerUpdateTopic, con.ID()),
+		fmt.Sprintf(`topic==%q,event.id==%q`, containerDeleteTopic, con.ID()),
+	)
+	// the container may have been restarted before the subscription
+	if s.client.GetContainerStatus(ctx, con) == containerd.Running {
+		return s.client.GetContainerTaskWait(ctx, nil, con)
+	}
+	for {
+		select {
+		case e := <-eventCh:
+			switch e.Topic {
+			case runtime.TaskStartEventTopic:
+				return s.client.GetContainerTaskWait(ctx, nil, con)
+			case containerUpdateTopic:
+				if !willRestart(ctx, con, exitCode) {
+					return nil, nil, fmt.Errorf("container %s will not be restarted", con.ID())
+				}
+			default:
+				return nil, nil, fmt.Errorf("container %s was removed", con.ID())
+			}
+		case err := <-errCh:
+			return nil, nil, err
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// willRestart returns whether the restart policy of a container restarts it after its task exited with exitCode.
+// Adapted from github.com/containerd/nerdctl/pkg/formatter.ContainerStatus.
+func willRestart(ctx context.Context, con containerd.Container, exitCode uint32) bool {
+	l, err := con.Labels(ctx)
+	if err != nil {
+		return false
+	}
+	return l[restart.StatusLabel] == string(containerd.Running) &&
+		restart.Reconcile(containerd.Status{Status: containerd.Stopped, ExitStatus: exitCode}, l)
+}
+
+// getLogDetails returns the attributes prepended to each line of the logs when details are requested. They are
+// configured by the labels, labels-regex, env and env-regex options of the log driver of the container.
+// Adapted from https://github.com/moby/moby/blob/v24.0.2/daemon/logger/loginfo.go#L44-L93
+func getLogDetails(ctx context.Context, con containerd.Container, l map[string]string) (string, error) {
+	var logConfig logging.LogConfig
+	if config, ok := l[labels.LogConfig]; ok {
+		if err := json.Unmarshal([]byte(config), &logConfig); err != nil {
+			return "", fmt.Errorf("failed to parse log config: %w", err)
+		}
+	}
+	opts := logConfig.Opts
+
+	attrs := map[string]string{}
+	if keys, ok := opts["labels"]; ok {
+		for _, key := range strings.Split(keys, ",") {
+			if value, ok := l[key]; ok {
+				attrs[key] = value
+			}
+		}
+	}
+	if pattern, ok := opts["labels-regex"]; ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		for key, value := range l {
+			if re.MatchString(key) {
+				attrs[key] = value
+			}
+		}
+	}
+
+	_, hasEnv := opts["env"]
+	_, hasEnvRegex := opts["env-regex"]
+	if hasEnv || hasEnvRegex {
+		spec, err := con.Spec(ctx)
+		if err != nil {
+			return "", err
+		}
+		env := map[string]string{}
+		if spec.Process != nil {
+			for _, e := range spec.Process.Env {
+				if key, value, ok := strings.Cut(e, "="); ok {
+					env[key] = value
+				}
+			}
+		}
+		if keys, ok := opts["env"]; ok {
+			for _, key := range strings.Split(keys, ",") {
+				if value, ok := env[key]; ok {
+					attrs[key] = value
+				}
+			}
+		}
+		if pattern, ok := opts["env-regex"]; ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return "", err
+			}
+			for key, value := range env {
+				if re.MatchString(key) {
+					attrs[key] = value
+				}
+			}
+		}
+	}
+
+	// like docker, attributes are sorted by key and url-encoded
+	pairs := make([]string, 0, len(attrs))
+	for _, key := range slices.Sorted(maps.Keys(attrs)) {
+		pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(attrs[key]))
+	}
+	return strings.Join(pairs, ","), nil
+}
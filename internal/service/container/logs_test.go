@@ -11,14 +11,19 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/mocks/mocks_archive"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/containerd/v2/pkg/oci"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/labels/k8slabels"
 	"github.com/containerd/typeurl/v2"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -255,3 +260,103 @@ var _ = Describe("Container Logs API ", func() {
 		})
 	})
 })
+
+// Unit tests related to following the logs through restarts and log details.
+var _ = Describe("Container Logs follow and details", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		cdClient *mocks_backend.MockContainerdClient
+		cid      string
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		cid = "test-container"
+	})
+	Context("follow", func() {
+		var (
+			svc  *service
+			con  *mocks_container.MockContainer
+			task *mocks_container.MockTask
+		)
+		BeforeEach(func() {
+			svc = &service{client: cdClient, logger: logger}
+			con = mocks_container.NewMockContainer(mockCtrl)
+			con.EXPECT().ID().Return(cid).AnyTimes()
+			task = mocks_container.NewMockTask(mockCtrl)
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+			logger.EXPECT().Debugf(gomock.Any()).AnyTimes()
+		})
+		exited := func(code uint32) <-chan containerd.ExitStatus {
+			ch := make(chan containerd.ExitStatus, 1)
+			ch <- *containerd.NewExitStatus(code, time.Now(), nil)
+			return ch
+		}
+		restartLabels := map[string]string{
+			restart.StatusLabel: string(containerd.Running),
+			restart.PolicyLabel: "always",
+		}
+		It("should keep following the logs through the restarts of the container", func() {
+			con.EXPECT().Labels(gomock.Any()).Return(restartLabels, nil)
+			cdClient.EXPECT().SubscribeToEvents(gomock.Any(), gomock.Any()).Return(nil, nil)
+			cdClient.EXPECT().GetContainerStatus(gomock.Any(), con).Return(containerd.Running)
+			nextTask := mocks_container.NewMockTask(mockCtrl)
+			cdClient.EXPECT().GetContainerTaskWait(gomock.Any(), nil, con).Return(nextTask, exited(0), nil)
+			// the container is stopped, so its next exit ends the logs
+			con.EXPECT().Labels(gomock.Any()).Return(map[string]string{}, nil)
+			nextTask.EXPECT().Delete(gomock.Any())
+
+			svc.waitForLogsEnd(ctx, con, task, exited(1), true)
+		})
+		It("should stop following the logs when the container is removed while restarting", func() {
+			con.EXPECT().Labels(gomock.Any()).Return(restartLabels, nil)
+			eventCh := make(chan *events.Envelope, 1)
+			eventCh <- &events.Envelope{Topic: containerDeleteTopic}
+			cdClient.EXPECT().SubscribeToEvents(gomock.Any(), gomock.Any()).Return(eventCh, nil)
+			cdClient.EXPECT().GetContainerStatus(gomock.Any(), con).Return(containerd.Stopped)
+
+			svc.waitForLogsEnd(ctx, con, task, exited(1), true)
+		})
+		It("should stop following the logs on exit when restarts are not followed", func() {
+			task.EXPECT().Delete(gomock.Any())
+
+			svc.waitForLogsEnd(ctx, con, task, exited(1), false)
+		})
+		It("should stop following the logs on exit when the restart policy does not restart the container", func() {
+			con.EXPECT().Labels(gomock.Any()).Return(map[string]string{
+				restart.StatusLabel: string(containerd.Running),
+				restart.PolicyLabel: "on-failure",
+			}, nil)
+			task.EXPECT().Delete(gomock.Any())
+
+			svc.waitForLogsEnd(ctx, con, task, exited(0), true)
+		})
+	})
+	Context("details", func() {
+		It("should return the configured labels and env sorted by key", func() {
+			con := mocks_container.NewMockContainer(mockCtrl)
+			con.EXPECT().Spec(gomock.Any()).Return(&oci.Spec{Process: &specs.Process{
+				Env: []string{"FOO=bar", "PATH=/bin", "APP_ENV=a b"},
+			}}, nil)
+			l := map[string]string{
+				labels.LogConfig: `{"driver":"json-file","opts":{"labels":"com.example.team,missing","env":"FOO","env-regex":"^APP_"}}`,
+				"com.example.team": "core",
+			}
+
+			details, err := getLogDetails(ctx, con, l)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(details).Should(Equal("APP_ENV=a+b,FOO=bar,com.example.team=core"))
+		})
+		It("should return no details without log options", func() {
+			con := mocks_container.NewMockContainer(mockCtrl)
+
+			details, err := getLogDetails(ctx, con, map[string]string{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(details).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,171 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apievents "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/containerd/v2/core/runtime"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/typeurl/v2"
+
+	eventtype "github.com/runfinch/finch-daemon/api/events"
+	"github.com/runfinch/finch-daemon/internal/backend"
+	"github.com/runfinch/finch-daemon/pkg/flog"
+)
+
+const (
+	oomEventAction = "oom"
+	// oomExtension is the name of the containerd extension recording whether the last task of a container
+	// was killed by the kernel because it ran out of memory.
+	oomExtension = "finch-daemon/oom"
+	// resubscribeDelay is the time to wait before subscribing again to containerd events after a failure.
+	resubscribeDelay = time.Second
+)
+
+// oomState is the content of the oomExtension.
+type oomState struct {
+	OOMKilled bool
+}
+
+func init() {
+	typeurl.Register(&oomState{}, "github.com/runfinch/finch-daemon", "oomState")
+}
+
+// MonitorOOM records the OOM kills reported by containerd for the containers of the namespace of ctx and
+// publishes an oom event for each of them. The record of a container is cleared when its task starts again.
+// It blocks until ctx is done.
+func MonitorOOM(ctx context.Context, client backend.ContainerdClient, logger flog.Logger) {
+	namespace, err := namespaces.NamespaceRequired(ctx)
+	if err != nil {
+		logger.Errorf("failed to monitor OOM kills: %s", err)
+		return
+	}
+	filters := []string{
+		fmt.Sprintf(`topic==%q,namespace==%q`, runtime.TaskOOMEventTopic, namespace),
+		fmt.Sprintf(`topic==%q,namespace==%q`, runtime.TaskStartEventTopic, namespace),
+	}
+	for {
+		eventCh, errCh := client.SubscribeToEvents(ctx, filters...)
+	receive:
+		for {
+			select {
+			case e := <-eventCh:
+				handleTaskEvent(ctx, client, logger, e)
+			case err := <-errCh:
+				logger.Warnf("failed to receive task events, subscribing again: %s", err)
+				break receive
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-time.After(resubscribeDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func handleTaskEvent(ctx context.Context, client backend.ContainerdClient, logger flog.Logger, e *events.Envelope) {
+	if e == nil || e.Event == nil {
+		return
+	}
+	v, err := typeurl.UnmarshalAny(e.Event)
+	if err != nil {
+		logger.Errorf("failed to unmarshal task event: %s", err)
+		return
+	}
+	switch event := v.(type) {
+	case *apievents.TaskOOM:
+		cont, err := loadContainer(ctx, client, event.ContainerID)
+		if err != nil {
+			logger.Debugf("failed to find OOM killed container %s: %s", event.ContainerID, err)
+			return
+		}
+		if err := setOOMKilled(ctx, client, cont, true); err != nil {
+			logger.Errorf("failed to record OOM kill of container %s: %s", event.ContainerID, err)
+		}
+		if err := publishOOMEvent(ctx, client, cont); err != nil {
+			logger.Errorf("failed to publish oom event of container %s: %s", event.ContainerID, err)
+		}
+	case *apievents.TaskStart:
+		cont, err := loadContainer(ctx, client, event.ContainerID)
+		if err != nil {
+			return
+		}
+		extensions, err := cont.Extensions(ctx)
+		if err != nil || !isOOMKilled(extensions) {
+			return
+		}
+		if err := setOOMKilled(ctx, client, cont, false); err != nil {
+			logger.Errorf("failed to clear OOM kill of container %s: %s", event.ContainerID, err)
+		}
+	}
+}
+
+// loadContainer returns the container with the exact id.
+func loadContainer(ctx context.Context, client backend.ContainerdClient, id string) (containerd.Container, error) {
+	containers, err := client.SearchContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, cont := range containers {
+		if cont.ID() == id {
+			return cont, nil
+		}
+	}
+	return nil, fmt.Errorf("no such container: %s", id)
+}
+
+func setOOMKilled(ctx context.Context, client backend.ContainerdClient, cont containerd.Container, killed bool) error {
+	return client.UpdateContainerExtension(ctx, cont.ID(), oomExtension, &oomState{OOMKilled: killed})
+}
+
+// isOOMKilled returns whether the last task of a container was OOM killed, from the extensions of the container.
+func isOOMKilled(extensions map[string]typeurl.Any) bool {
+	ext, ok := extensions[oomExtension]
+	if !ok || ext == nil {
+		return false
+	}
+	var state oomState
+	if err := typeurl.UnmarshalTo(ext, &state); err != nil {
+		return false
+	}
+	return state.OOMKilled
+}
+
+func publishOOMEvent(ctx context.Context, client backend.ContainerdClient, cont containerd.Container) error {
+	info, err := cont.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return err
+	}
+	return client.PublishEvent(ctx, oomTopic(), getOOMEvent(cont.ID(), info.Labels[labels.Name], info.Image))
+}
+
+func oomTopic() string {
+	return fmt.Sprintf("/%s/%s/%s", eventtype.CompatibleTopicPrefix, "container", oomEventAction)
+}
+
+func getOOMEvent(cid, name, image string) *eventtype.Event {
+	return &eventtype.Event{
+		ID:     cid,
+		Status: oomEventAction,
+		Type:   "container",
+		Action: oomEventAction,
+		Actor: eventtype.EventActor{
+			Id: cid,
+			Attributes: map[string]string{
+				"name":  name,
+				"image": image,
+			},
+		},
+	}
+}
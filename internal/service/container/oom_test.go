@@ -0,0 +1,111 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"context"
+	"errors"
+
+	apievents "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/typeurl/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_container"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+)
+
+// Unit tests related to OOM kill monitoring.
+var _ = Describe("Container OOM monitor", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		cdClient *mocks_backend.MockContainerdClient
+		con      *mocks_container.MockContainer
+		cid      string
+	)
+	BeforeEach(func() {
+		ctx = namespaces.WithNamespace(context.Background(), "finch")
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		con = mocks_container.NewMockContainer(mockCtrl)
+		cid = "123"
+		con.EXPECT().ID().Return(cid).AnyTimes()
+	})
+	envelope := func(event interface{}) *events.Envelope {
+		any, err := typeurl.MarshalAny(event)
+		Expect(err).ShouldNot(HaveOccurred())
+		return &events.Envelope{Namespace: "finch", Event: any}
+	}
+	oomExt := func(killed bool) typeurl.Any {
+		any, err := typeurl.MarshalAny(&oomState{OOMKilled: killed})
+		Expect(err).ShouldNot(HaveOccurred())
+		return any
+	}
+	It("should record the OOM kill and publish an oom event", func() {
+		cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return([]containerd.Container{con}, nil)
+		cdClient.EXPECT().UpdateContainerExtension(gomock.Any(), cid, oomExtension, &oomState{OOMKilled: true}).Return(nil)
+		con.EXPECT().Info(gomock.Any(), gomock.Any()).Return(containers.Container{
+			ID:     cid,
+			Image:  "test-image",
+			Labels: map[string]string{labels.Name: "test-cont"},
+		}, nil)
+		cdClient.EXPECT().PublishEvent(gomock.Any(), "/dockercompat/container/oom", getOOMEvent(cid, "test-cont", "test-image")).Return(nil)
+
+		handleTaskEvent(ctx, cdClient, logger, envelope(&apievents.TaskOOM{ContainerID: cid}))
+	})
+	It("should not publish an event for unknown containers", func() {
+		cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(nil, nil)
+		logger.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		handleTaskEvent(ctx, cdClient, logger, envelope(&apievents.TaskOOM{ContainerID: cid}))
+	})
+	It("should clear the OOM kill when the container starts again", func() {
+		cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return([]containerd.Container{con}, nil)
+		con.EXPECT().Extensions(gomock.Any()).Return(map[string]typeurl.Any{oomExtension: oomExt(true)}, nil)
+		cdClient.EXPECT().UpdateContainerExtension(gomock.Any(), cid, oomExtension, &oomState{OOMKilled: false}).Return(nil)
+
+		handleTaskEvent(ctx, cdClient, logger, envelope(&apievents.TaskStart{ContainerID: cid, Pid: 1}))
+	})
+	It("should not update containers which were not OOM killed when they start", func() {
+		cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return([]containerd.Container{con}, nil)
+		con.EXPECT().Extensions(gomock.Any()).Return(nil, nil)
+
+		handleTaskEvent(ctx, cdClient, logger, envelope(&apievents.TaskStart{ContainerID: cid, Pid: 1}))
+	})
+	It("should log failures to record the OOM kill", func() {
+		cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return([]containerd.Container{con}, nil)
+		cdClient.EXPECT().UpdateContainerExtension(gomock.Any(), cid, oomExtension, gomock.Any()).Return(errors.New("update error"))
+		logger.EXPECT().Errorf(gomock.Any(), gomock.Any(), gomock.Any())
+		con.EXPECT().Info(gomock.Any(), gomock.Any()).Return(containers.Container{ID: cid}, nil)
+		cdClient.EXPECT().PublishEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		handleTaskEvent(ctx, cdClient, logger, envelope(&apievents.TaskOOM{ContainerID: cid}))
+	})
+	It("should report the OOM kill in the state of the container", func() {
+		Expect(isOOMKilled(map[string]typeurl.Any{oomExtension: oomExt(true)})).Should(BeTrue())
+		Expect(isOOMKilled(map[string]typeurl.Any{oomExtension: oomExt(false)})).Should(BeFalse())
+		Expect(isOOMKilled(nil)).Should(BeFalse())
+
+		state := getState(&dockercompat.ContainerState{Status: "exited", ExitCode: 137}, true)
+		Expect(state.OOMKilled).Should(BeTrue())
+		Expect(state.ExitCode).Should(Equal(137))
+		state = getState(&dockercompat.ContainerState{Status: "exited"}, true)
+		Expect(state.OOMKilled).Should(BeTrue())
+		Expect(state.ExitCode).Should(BeZero())
+		state = getState(&dockercompat.ContainerState{Status: "exited", ExitCode: 1}, false)
+		Expect(state.OOMKilled).Should(BeFalse())
+		Expect(state.ExitCode).Should(Equal(1))
+	})
+})
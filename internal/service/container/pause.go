@@ -22,11 +22,11 @@ func (s *service) Pause(ctx context.Context, cid string, options ncTypes.Contain
 		}
 		return err
 	}
-	status := s.client.GetContainerStatus(ctx, cont)
-	if status != containerd.Running {
-		if status == containerd.Paused {
-			return errdefs.NewConflict(fmt.Errorf("container %s is already paused", cid))
-		}
+	switch s.client.GetContainerStatus(ctx, cont) {
+	case containerd.Running:
+	case containerd.Pausing, containerd.Paused:
+		return errdefs.NewNotModified(fmt.Errorf("container %s is already paused", cid))
+	default:
 		return errdefs.NewConflict(fmt.Errorf("container %s is not running", cid))
 	}
 
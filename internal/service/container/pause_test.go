@@ -79,13 +79,13 @@ var _ = Describe("Container Pause API", func() {
 			Expect(err.Error()).Should(Equal(errdefs.NewNotFound(fmt.Errorf("no such container: %s", cid)).Error()))
 		})
 
-		It("should return a Conflict error if container is already paused", func() {
+		It("should return a NotModified error if container is already paused", func() {
 			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
 				[]containerd.Container{con}, nil)
 			cdClient.EXPECT().GetContainerStatus(gomock.Any(), gomock.Any()).Return(containerd.Paused)
 
 			err := svc.Pause(ctx, cid, pauseOptions)
-			Expect(err.Error()).Should(Equal(errdefs.NewConflict(fmt.Errorf("container %s is already paused", cid)).Error()))
+			Expect(errdefs.IsNotModified(err)).Should(BeTrue())
 		})
 
 		It("should return a Conflict error if container is not running", func() {
@@ -5,6 +5,7 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -15,8 +16,10 @@ import (
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/moby/go-archive"
 	"github.com/moby/sys/user"
+	"github.com/opencontainers/runtime-spec/specs-go"
 
 	"github.com/runfinch/finch-daemon/api/types"
+	fsarchive "github.com/runfinch/finch-daemon/pkg/archive"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
@@ -25,17 +28,21 @@ import (
 // container. If it is not, the error will be an errdefs.InvalidFormat. If
 // noOverwriteDirNonDir is true then it will be an error if unpacking the
 // given content would cause an existing directory to be replaced with a non-
-// directory and vice versa.
-// setting uidgid has no effect due to https://github.com/docker/docs/issues/17533#issuecomment-1588223056
-// Fix once the bug is resolved.
-
+// directory and vice versa. If copyUIDGID is true, the extracted files are
+// owned by the container's user instead of the owner recorded in the archive.
+// All paths, including symlinks in the archive and the container, are
+// resolved inside the container's root filesystem.
 func (s *service) ExtractArchiveInContainer(ctx context.Context, opts *types.PutArchiveOptions, body io.ReadCloser) error {
 	con, err := s.getContainer(ctx, opts.ContainerId)
 	if err != nil {
 		return err
 	}
+	spec, err := con.Spec(ctx)
+	if err != nil {
+		return err
+	}
 	// First check if the mount is a volume and readonly or in a readonly rootfs
-	err = s.isReadOnlyMount(ctx, con, opts.Path)
+	err = isReadOnlyMount(spec, opts.Path)
 	if err != nil {
 		return err
 	}
@@ -80,9 +87,11 @@ func (s *service) ExtractArchiveInContainer(ctx context.Context, opts *types.Put
 	if cleanup != nil {
 		defer cleanup()
 	}
-	// TODO: ideally this should use securejoin.SecureJoin() to sanitize inputs but securejoin can't work with afero.MemMapFs yet
-	// so it makes testing difficult
-	filePath = path.Join(root, opts.Path)
+	filePath, err = s.resolvePathInRoot(root, opts.Path, true)
+	if err != nil {
+		s.logger.Errorf("Error resolving %s in container: %s", opts.Path, err)
+		return err
+	}
 
 	stat, err := s.fs.Stat(filePath)
 	if err != nil {
@@ -100,15 +109,35 @@ func (s *service) ExtractArchiveInContainer(ctx context.Context, opts *types.Put
 		NoOverwriteDirNonDir: opts.Overwrite,
 		IDMap:                user.IdentityMapping{},
 	}
-	return s.tarExtractor.ExtractCompressed(body, filePath, tarOptions)
-}
-
-func (s *service) isReadOnlyMount(ctx context.Context, con containerd.Container, containerPath string) error {
-	filePath := filepath.Clean(containerPath)
-	spec, err := con.Spec(ctx)
+	if opts.CopyUIDGID {
+		tarOptions.ChownOpts = containerUser(spec)
+	}
+	// extract relative to the root so that symlinks in the archive or in the container cannot escape it
+	rel, err := filepath.Rel(root, filePath)
 	if err != nil {
 		return err
 	}
+	err = s.tarExtractor.ExtractInRoot(body, root, path.Join("/", rel), tarOptions)
+	if errors.Is(err, fsarchive.ErrOverwriteDirNonDir) {
+		return errdefs.NewInvalidFormat(err)
+	}
+	return err
+}
+
+// containerUser returns the uid and gid of the container's process user, which
+// nerdctl resolves from the image's /etc/passwd at creation time.
+func containerUser(spec *specs.Spec) *archive.ChownOpts {
+	if spec.Process == nil {
+		return &archive.ChownOpts{}
+	}
+	return &archive.ChownOpts{
+		UID: int(spec.Process.User.UID),
+		GID: int(spec.Process.User.GID),
+	}
+}
+
+func isReadOnlyMount(spec *specs.Spec, containerPath string) error {
+	filePath := filepath.Clean(containerPath)
 	if spec.Root.Readonly {
 		return errdefs.NewForbidden(fmt.Errorf("container rootfs: %s is marked read-only", spec.Root.Path))
 	}
@@ -29,6 +29,7 @@ import (
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+	fsarchive "github.com/runfinch/finch-daemon/pkg/archive"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
@@ -115,13 +116,59 @@ var _ = Describe("Extract in container API", func() {
 				},
 			}, nil)
 			task.EXPECT().Pid().Return(mockPid)
-			tarExtractor.EXPECT().ExtractCompressed(mockReader, containerPath, &archive.TarOptions{
+			tarExtractor.EXPECT().ExtractInRoot(mockReader, fmt.Sprintf("/proc/%d/root", mockPid), mockPath, &archive.TarOptions{
 				NoOverwriteDirNonDir: false,
 				IDMap:                user.IdentityMapping{},
 			}).Return(nil)
 			err = s.ExtractArchiveInContainer(ctx, putArchiveOpts, mockReader)
 			Expect(err).Should(BeNil())
 		})
+		It("should chown extracted files to the container user when copyUIDGID is set", func() {
+			err := fs.MkdirAll(containerPath, 0o755)
+			Expect(err).Should(BeNil())
+			putArchiveOpts.CopyUIDGID = true
+			cdClient.EXPECT().SearchContainer(ctx, cid).Return([]containerd.Container{con}, nil)
+			con.EXPECT().Task(ctx, nil).Return(task, nil)
+			task.EXPECT().Status(ctx).Return(containerd.Status{Status: "running"}, nil)
+			con.EXPECT().Spec(ctx).Return(&specs.Spec{
+				Process: &specs.Process{
+					User: specs.User{UID: 1000, GID: 1001},
+				},
+				Root: &specs.Root{
+					Path:     "rootfs",
+					Readonly: false,
+				},
+			}, nil)
+			task.EXPECT().Pid().Return(mockPid)
+			tarExtractor.EXPECT().ExtractInRoot(mockReader, fmt.Sprintf("/proc/%d/root", mockPid), mockPath, &archive.TarOptions{
+				NoOverwriteDirNonDir: false,
+				IDMap:                user.IdentityMapping{},
+				ChownOpts:            &archive.ChownOpts{UID: 1000, GID: 1001},
+			}).Return(nil)
+			err = s.ExtractArchiveInContainer(ctx, putArchiveOpts, mockReader)
+			Expect(err).Should(BeNil())
+		})
+		It("should return an InvalidFormat error when a directory would be overwritten by a non-directory", func() {
+			err := fs.MkdirAll(containerPath, 0o755)
+			Expect(err).Should(BeNil())
+			putArchiveOpts.Overwrite = true
+			cdClient.EXPECT().SearchContainer(ctx, cid).Return([]containerd.Container{con}, nil)
+			con.EXPECT().Task(ctx, nil).Return(task, nil)
+			task.EXPECT().Status(ctx).Return(containerd.Status{Status: "running"}, nil)
+			con.EXPECT().Spec(ctx).Return(&specs.Spec{
+				Root: &specs.Root{
+					Path:     "rootfs",
+					Readonly: false,
+				},
+			}, nil)
+			task.EXPECT().Pid().Return(mockPid)
+			tarExtractor.EXPECT().ExtractInRoot(mockReader, fmt.Sprintf("/proc/%d/root", mockPid), mockPath, &archive.TarOptions{
+				NoOverwriteDirNonDir: true,
+				IDMap:                user.IdentityMapping{},
+			}).Return(fmt.Errorf("%w: /files", fsarchive.ErrOverwriteDirNonDir))
+			err = s.ExtractArchiveInContainer(ctx, putArchiveOpts, mockReader)
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+		})
 		It("should return an error when container is running and volume is read-only", func() {
 			err := fs.MkdirAll(containerPath, 0o755)
 			Expect(err).Should(BeNil())
@@ -237,7 +284,7 @@ var _ = Describe("Extract in container API", func() {
 				Expect(err).Should(BeNil())
 				return nil
 			})
-			tarExtractor.EXPECT().ExtractCompressed(mockReader, gomock.Any(), &archive.TarOptions{
+			tarExtractor.EXPECT().ExtractInRoot(mockReader, gomock.Any(), mockPath, &archive.TarOptions{
 				NoOverwriteDirNonDir: false,
 				IDMap:                user.IdentityMapping{},
 			}).Return(nil)
@@ -282,7 +329,7 @@ var _ = Describe("Extract in container API", func() {
 				Expect(err).Should(BeNil())
 				return nil
 			})
-			tarExtractor.EXPECT().ExtractCompressed(mockReader, gomock.Any(), &archive.TarOptions{
+			tarExtractor.EXPECT().ExtractInRoot(mockReader, gomock.Any(), mockPath, &archive.TarOptions{
 				NoOverwriteDirNonDir: false,
 				IDMap:                user.IdentityMapping{},
 			}).Return(nil)
@@ -7,11 +7,16 @@ import (
 	"context"
 	"fmt"
 
+	containerd "github.com/containerd/containerd/v2/client"
 	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
+const renameEventAction = "rename"
+
 // Rename function renames a running container. It returns nil when it successfully renames the container.
 func (s *service) Rename(ctx context.Context, cid string, newName string, opts ncTypes.ContainerRenameOptions) error {
 	var err error
@@ -26,10 +31,41 @@ func (s *service) Rename(ctx context.Context, cid string, newName string, opts n
 	if err != nil {
 		return err
 	}
+	info, err := con.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return err
+	}
 	if err = s.nctlContainerSvc.RenameContainer(ctx, con, newName, opts); err != nil {
 		s.logger.Errorf("Failed to rename container: %s. Error: %v", cid, err)
 		return err
 	}
 	s.logger.Debugf("successfully renamed %s to %s", cid, newName)
+
+	oldName := info.Labels[labels.Name]
+	if err = s.client.PublishEvent(ctx, renameTopic(), getRenameEvent(con.ID(), oldName, newName, info.Image)); err != nil {
+		s.logger.Errorf("failed to publish rename event of container %s: %s", cid, err)
+	}
 	return nil
 }
+
+func renameTopic() string {
+	return fmt.Sprintf("/%s/%s/%s", eventtype.CompatibleTopicPrefix, "container", renameEventAction)
+}
+
+// getRenameEvent returns the rename event of a container. Like docker, oldName is prefixed with a slash.
+func getRenameEvent(cid, oldName, newName, image string) *eventtype.Event {
+	return &eventtype.Event{
+		ID:     cid,
+		Status: renameEventAction,
+		Type:   "container",
+		Action: renameEventAction,
+		Actor: eventtype.EventActor{
+			Id: cid,
+			Attributes: map[string]string{
+				"name":    newName,
+				"oldName": "/" + oldName,
+				"image":   image,
+			},
+		},
+	}
+}
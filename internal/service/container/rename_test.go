@@ -8,7 +8,9 @@ import (
 	"fmt"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
 	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -60,9 +62,31 @@ var _ = Describe("Container Rename API ", func() {
 				[]containerd.Container{}, nil)
 			cdClient.EXPECT().SearchContainer(gomock.Any(), gomock.Any()).Return(
 				[]containerd.Container{con}, nil)
+			con.EXPECT().Info(gomock.Any(), gomock.Any()).Return(containers.Container{
+				ID:     cid,
+				Image:  "test-image",
+				Labels: map[string]string{labels.Name: "oldName"},
+			}, nil)
 			ncClient.EXPECT().RenameContainer(ctx, con, testContainerName, gomock.Any())
 			logger.EXPECT().Debugf("no such container: %s", testContainerName)
 			logger.EXPECT().Debugf("successfully renamed %s to %s", cid, testContainerName)
+			cdClient.EXPECT().PublishEvent(gomock.Any(), "/dockercompat/container/rename",
+				getRenameEvent(cid, "oldName", testContainerName, "test-image")).Return(nil)
+
+			err := service.Rename(ctx, cid, testContainerName, opts)
+			Expect(err).Should(BeNil())
+		})
+		It("should not fail when the rename event cannot be published", func() {
+			cdClient.EXPECT().SearchContainer(gomock.Any(), testContainerName).Return(
+				[]containerd.Container{}, nil)
+			cdClient.EXPECT().SearchContainer(gomock.Any(), gomock.Any()).Return(
+				[]containerd.Container{con}, nil)
+			con.EXPECT().Info(gomock.Any(), gomock.Any()).Return(containers.Container{ID: cid}, nil)
+			ncClient.EXPECT().RenameContainer(ctx, con, testContainerName, gomock.Any())
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cdClient.EXPECT().PublishEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("publish error"))
+			logger.EXPECT().Errorf(gomock.Any(), gomock.Any(), gomock.Any())
 
 			err := service.Rename(ctx, cid, testContainerName, opts)
 			Expect(err).Should(BeNil())
@@ -106,6 +130,7 @@ var _ = Describe("Container Rename API ", func() {
 			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
 				[]containerd.Container{con}, nil)
 
+			con.EXPECT().Info(gomock.Any(), gomock.Any()).Return(containers.Container{ID: cid}, nil)
 			expectedErr := fmt.Errorf("nerdctl error")
 			ncClient.EXPECT().RenameContainer(ctx, con, testContainerName, gomock.Any()).Return(expectedErr)
 			logger.EXPECT().Debugf("no such container: %s", testContainerName)
@@ -36,9 +36,8 @@ func (s *service) assertStartContainer(ctx context.Context, c containerd.Contain
 	switch status {
 	case containerd.Running:
 		return errdefs.NewNotModified(fmt.Errorf("container already running"))
-	case containerd.Pausing:
-	case containerd.Paused:
-		return fmt.Errorf("cannot start a paused container, try unpause instead")
+	case containerd.Pausing, containerd.Paused:
+		return errdefs.NewConflict(fmt.Errorf("cannot start a paused container, try unpause instead"))
 	}
 	return nil
 }
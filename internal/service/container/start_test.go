@@ -92,15 +92,15 @@ var _ = Describe("Container Start API ", func() {
 			err := service.Start(ctx, cid, options)
 			Expect(errdefs.IsNotModified(err)).Should(BeTrue())
 		})
-		It("should return not modified error as container is paused", func() {
+		It("should return conflict error as container is paused", func() {
 			// set up the mock to return a container that is not running
 			cdClient.EXPECT().GetContainerStatus(gomock.Any(), gomock.Any()).Return(containerd.Paused)
 			cdClient.EXPECT().SearchContainer(gomock.Any(), gomock.Any()).Return(
 				[]containerd.Container{con}, nil)
 
-			// service should return not modified error.
+			// service should return conflict error.
 			err := service.Start(ctx, cid, options)
-			Expect(err).Should(Not(BeNil()))
+			Expect(errdefs.IsConflict(err)).Should(BeTrue())
 		})
 		It("should fail due to nerdctl client error", func() {
 			// set up the mock to mimic an error occurred while starting the container using nerdctl function.
@@ -0,0 +1,139 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"bufio"
+	"path"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	cgroupRoot = "/sys/fs/cgroup"
+	// procSelfCgroup lists the cgroups of the daemon, which docker probes the cgroup v2 features from.
+	procSelfCgroup = "/proc/self/cgroup"
+)
+
+// sysInfo describes the cgroup features supported by the host.
+// Adapted from https://github.com/moby/moby/blob/v24.0.2/pkg/sysinfo/sysinfo.go
+type sysInfo struct {
+	CgroupV2 bool
+
+	MemoryLimit       bool
+	SwapLimit         bool
+	MemoryReservation bool
+	MemorySwappiness  bool
+	OomKillDisable    bool
+
+	CPUShares   bool
+	CPUCfs      bool
+	CPURealtime bool
+	Cpuset      bool
+
+	PidsLimit bool
+
+	BlkioWeight          bool
+	BlkioWeightDevice    bool
+	BlkioReadBpsDevice   bool
+	BlkioWriteBpsDevice  bool
+	BlkioReadIOpsDevice  bool
+	BlkioWriteIOpsDevice bool
+}
+
+// probeSysInfo returns the cgroup features of the host, read from the cgroup filesystem.
+func probeSysInfo(fs afero.Fs) *sysInfo {
+	if exists(fs, path.Join(cgroupRoot, "cgroup.controllers")) {
+		return probeCgroupV2(fs)
+	}
+	return probeCgroupV1(fs)
+}
+
+func probeCgroupV2(fs afero.Fs) *sysInfo {
+	info := &sysInfo{CgroupV2: true}
+	group := cgroupV2Group(fs)
+	controllers := map[string]bool{}
+	for _, groupPath := range []string{path.Join(cgroupRoot, group), cgroupRoot} {
+		b, err := afero.ReadFile(fs, path.Join(groupPath, "cgroup.controllers"))
+		if err != nil {
+			continue
+		}
+		for _, c := range strings.Fields(string(b)) {
+			controllers[c] = true
+		}
+		break
+	}
+
+	if controllers["memory"] {
+		info.MemoryLimit = true
+		info.MemoryReservation = true
+		// swap accounting can be disabled with the swapaccount=0 kernel parameter, in which case
+		// the swap interface files are missing from non-root cgroups.
+		info.SwapLimit = group == "/" || exists(fs, path.Join(cgroupRoot, group, "memory.swap.max"))
+	}
+	if controllers["cpu"] {
+		info.CPUShares = true
+		info.CPUCfs = true
+	}
+	info.Cpuset = controllers["cpuset"]
+	info.PidsLimit = controllers["pids"]
+	if controllers["io"] {
+		info.BlkioWeight = true
+		info.BlkioWeightDevice = true
+		info.BlkioReadBpsDevice = true
+		info.BlkioWriteBpsDevice = true
+		info.BlkioReadIOpsDevice = true
+		info.BlkioWriteIOpsDevice = true
+	}
+	return info
+}
+
+func probeCgroupV1(fs afero.Fs) *sysInfo {
+	memory := path.Join(cgroupRoot, "memory")
+	cpu := path.Join(cgroupRoot, "cpu")
+	blkio := path.Join(cgroupRoot, "blkio")
+	return &sysInfo{
+		MemoryLimit:       exists(fs, memory),
+		SwapLimit:         exists(fs, path.Join(memory, "memory.memsw.limit_in_bytes")),
+		MemoryReservation: exists(fs, path.Join(memory, "memory.soft_limit_in_bytes")),
+		MemorySwappiness:  exists(fs, path.Join(memory, "memory.swappiness")),
+		OomKillDisable:    exists(fs, path.Join(memory, "memory.oom_control")),
+
+		CPUShares:   exists(fs, path.Join(cpu, "cpu.shares")),
+		CPUCfs:      exists(fs, path.Join(cpu, "cpu.cfs_quota_us")),
+		CPURealtime: exists(fs, path.Join(cpu, "cpu.rt_period_us")),
+		Cpuset:      exists(fs, path.Join(cgroupRoot, "cpuset")),
+
+		PidsLimit: exists(fs, path.Join(cgroupRoot, "pids")),
+
+		BlkioWeight:          exists(fs, path.Join(blkio, "blkio.weight")),
+		BlkioWeightDevice:    exists(fs, path.Join(blkio, "blkio.weight_device")),
+		BlkioReadBpsDevice:   exists(fs, path.Join(blkio, "blkio.throttle.read_bps_device")),
+		BlkioWriteBpsDevice:  exists(fs, path.Join(blkio, "blkio.throttle.write_bps_device")),
+		BlkioReadIOpsDevice:  exists(fs, path.Join(blkio, "blkio.throttle.read_iops_device")),
+		BlkioWriteIOpsDevice: exists(fs, path.Join(blkio, "blkio.throttle.write_iops_device")),
+	}
+}
+
+// cgroupV2Group returns the cgroup v2 group of the daemon, or "/" if it cannot be determined.
+func cgroupV2Group(fs afero.Fs) string {
+	f, err := fs.Open(procSelfCgroup)
+	if err != nil {
+		return "/"
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if group, ok := strings.CutPrefix(scanner.Text(), "0::"); ok && group != "" {
+			return path.Clean(group)
+		}
+	}
+	return "/"
+}
+
+func exists(fs afero.Fs, name string) bool {
+	_, err := fs.Stat(name)
+	return err == nil
+}
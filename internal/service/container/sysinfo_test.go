@@ -0,0 +1,117 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"github.com/containerd/go-cni"
+	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+// Unit tests related to the host capability probe and the validation of create options.
+var _ = Describe("Container sysinfo", func() {
+	var fs afero.Fs
+	BeforeEach(func() {
+		fs = afero.NewMemMapFs()
+	})
+	writeFile := func(name, content string) {
+		Expect(afero.WriteFile(fs, name, []byte(content), 0o644)).Should(Succeed())
+	}
+	Context("probeSysInfo", func() {
+		It("should read the controllers of the daemon cgroup on cgroup v2", func() {
+			writeFile("/sys/fs/cgroup/cgroup.controllers", "cpuset cpu io memory pids")
+			writeFile("/proc/self/cgroup", "0::/system.slice/finch.service\n")
+			writeFile("/sys/fs/cgroup/system.slice/finch.service/cgroup.controllers", "cpu memory")
+
+			info := probeSysInfo(fs)
+			Expect(info.CgroupV2).Should(BeTrue())
+			Expect(info.MemoryLimit).Should(BeTrue())
+			Expect(info.CPUShares).Should(BeTrue())
+			Expect(info.PidsLimit).Should(BeFalse())
+			Expect(info.BlkioWeight).Should(BeFalse())
+			Expect(info.OomKillDisable).Should(BeFalse())
+			// swap accounting is disabled, as memory.swap.max is missing
+			Expect(info.SwapLimit).Should(BeFalse())
+		})
+		It("should detect swap accounting on cgroup v2", func() {
+			writeFile("/sys/fs/cgroup/cgroup.controllers", "memory")
+			writeFile("/proc/self/cgroup", "0::/finch\n")
+			writeFile("/sys/fs/cgroup/finch/memory.swap.max", "max")
+
+			Expect(probeSysInfo(fs).SwapLimit).Should(BeTrue())
+		})
+		It("should check the cgroup v1 interface files", func() {
+			writeFile("/sys/fs/cgroup/memory/memory.limit_in_bytes", "")
+			writeFile("/sys/fs/cgroup/memory/memory.oom_control", "")
+			writeFile("/sys/fs/cgroup/blkio/blkio.weight", "")
+			Expect(fs.MkdirAll("/sys/fs/cgroup/pids", 0o755)).Should(Succeed())
+
+			info := probeSysInfo(fs)
+			Expect(info.CgroupV2).Should(BeFalse())
+			Expect(info.MemoryLimit).Should(BeTrue())
+			Expect(info.OomKillDisable).Should(BeTrue())
+			Expect(info.SwapLimit).Should(BeFalse())
+			Expect(info.PidsLimit).Should(BeTrue())
+			Expect(info.BlkioWeight).Should(BeTrue())
+			Expect(info.BlkioWeightDevice).Should(BeFalse())
+		})
+	})
+	Context("verifyCreateOptions", func() {
+		var createOpt ncTypes.ContainerCreateOptions
+		var netOpt ncTypes.NetworkOptions
+		BeforeEach(func() {
+			createOpt = ncTypes.ContainerCreateOptions{MemorySwappiness64: -1, PidsLimit: -1, CPUQuota: -1}
+			netOpt = ncTypes.NetworkOptions{}
+		})
+		It("should not warn about supported resources", func() {
+			createOpt.Memory = "104857600"
+			createOpt.PidsLimit = 10
+			warnings, err := verifyCreateOptions(&sysInfo{MemoryLimit: true, PidsLimit: true}, &createOpt, &netOpt)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(BeEmpty())
+			Expect(createOpt.Memory).Should(Equal("104857600"))
+			Expect(createOpt.PidsLimit).Should(Equal(int64(10)))
+		})
+		It("should discard unsupported resources with a warning", func() {
+			createOpt.Memory = "104857600"
+			createOpt.MemorySwap = "209715200"
+			createOpt.PidsLimit = 10
+			createOpt.BlkioDeviceReadBps = []string{"/dev/sda:1mb"}
+			warnings, err := verifyCreateOptions(&sysInfo{MemoryLimit: true}, &createOpt, &netOpt)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(HaveLen(3))
+			Expect(createOpt.Memory).Should(Equal("104857600"))
+			Expect(createOpt.MemorySwap).Should(BeEmpty())
+			Expect(createOpt.PidsLimit).Should(Equal(int64(-1)))
+			Expect(createOpt.BlkioDeviceReadBps).Should(BeNil())
+		})
+		It("should warn when the OOM killer is disabled without memory limit", func() {
+			createOpt.OomKillDisable = true
+			warnings, err := verifyCreateOptions(&sysInfo{OomKillDisable: true}, &createOpt, &netOpt)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(ConsistOf(ContainSubstring("no memory limit is set")))
+		})
+		It("should discard published ports on the host network", func() {
+			netOpt.NetworkSlice = []string{"host"}
+			netOpt.PortMappings = []cni.PortMapping{{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}}
+			warnings, err := verifyCreateOptions(&sysInfo{}, &createOpt, &netOpt)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(ConsistOf("Published ports are discarded when using host network mode"))
+			Expect(netOpt.PortMappings).Should(BeNil())
+		})
+		It("should return an error for inconsistent memory limits", func() {
+			createOpt.Memory = "209715200"
+			createOpt.MemorySwap = "104857600"
+			_, err := verifyCreateOptions(&sysInfo{MemoryLimit: true, SwapLimit: true}, &createOpt, &netOpt)
+			Expect(err).Should(HaveOccurred())
+
+			createOpt.Memory = "1024"
+			createOpt.MemorySwap = ""
+			_, err = verifyCreateOptions(&sysInfo{MemoryLimit: true}, &createOpt, &netOpt)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -22,8 +22,11 @@ func (s *service) Unpause(ctx context.Context, cid string, options ncTypes.Conta
 		}
 		return err
 	}
-	status := s.client.GetContainerStatus(ctx, cont)
-	if status != containerd.Paused {
+	switch s.client.GetContainerStatus(ctx, cont) {
+	case containerd.Paused:
+	case containerd.Running:
+		return errdefs.NewNotModified(fmt.Errorf("container %s is not paused", cid))
+	default:
 		//nolint:staticcheck // Maintaining Docker-compatible error message format
 		return errdefs.NewConflict(fmt.Errorf("Container %s is not paused", cid))
 	}
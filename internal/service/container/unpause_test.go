@@ -79,13 +79,13 @@ var _ = Describe("Container Unpause API", func() {
 			Expect(err.Error()).Should(Equal(errdefs.NewNotFound(fmt.Errorf("no such container: %s", cid)).Error()))
 		})
 
-		It("should return a Conflict error if container is already running", func() {
+		It("should return a NotModified error if container is already running", func() {
 			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
 				[]containerd.Container{con}, nil)
 			cdClient.EXPECT().GetContainerStatus(gomock.Any(), gomock.Any()).Return(containerd.Running)
 
 			err := svc.Unpause(ctx, cid, unpauseOptions)
-			Expect(err.Error()).Should(Equal(errdefs.NewConflict(fmt.Errorf("Container %s is not paused", cid)).Error()))
+			Expect(errdefs.IsNotModified(err)).Should(BeTrue())
 		})
 
 		It("should return a Conflict error if container is not paused", func() {
@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+
+	eventtype "github.com/runfinch/finch-daemon/api/events"
+	"github.com/runfinch/finch-daemon/internal/execstore"
+)
+
+const (
+	execStartEventAction = "exec_start"
+	execDieEventAction   = "exec_die"
+)
+
+// publishExecEvent publishes an event of the exec instance. Failures are only logged, as the exec instance
+// has already changed state.
+func (s *service) publishExecEvent(ctx context.Context, con containerd.Container, exec *execstore.Exec, action string, exitCode *int) {
+	info, err := con.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		s.logger.Errorf("failed to publish %s event of exec instance %s: %s", action, exec.ID, err)
+		return
+	}
+	event := getExecEvent(action, exec, info.Labels[labels.Name], info.Image, exitCode)
+	if err = s.client.PublishEvent(ctx, execTopic(action), event); err != nil {
+		s.logger.Errorf("failed to publish %s event of exec instance %s: %s", action, exec.ID, err)
+	}
+}
+
+func execTopic(action string) string {
+	return fmt.Sprintf("/%s/%s/%s", eventtype.CompatibleTopicPrefix, "container", action)
+}
+
+// getExecEvent returns an event of an exec instance of a container. Like docker, the action of an exec_start event
+// is followed by the command of the exec instance, and exec_die events have the exit code of the process.
+func getExecEvent(action string, exec *execstore.Exec, name, image string, exitCode *int) *eventtype.Event {
+	attributes := map[string]string{
+		"name":   name,
+		"image":  image,
+		"execID": exec.ID,
+	}
+	if exitCode != nil {
+		attributes["exitCode"] = strconv.Itoa(*exitCode)
+	}
+	if action == execStartEventAction {
+		action = fmt.Sprintf("%s: %s", action, strings.Join(exec.Config.Cmd, " "))
+	}
+	return &eventtype.Event{
+		ID:     exec.ContainerID,
+		Status: action,
+		Type:   "container",
+		Action: action,
+		Actor: eventtype.EventActor{
+			Id:         exec.ContainerID,
+			Attributes: attributes,
+		},
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"context"
+	"errors"
+
+	"github.com/containerd/containerd/v2/core/containers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/runfinch/finch-daemon/internal/execstore"
+	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_container"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+)
+
+var _ = Describe("Exec events", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		cdClient *mocks_backend.MockContainerdClient
+		con      *mocks_container.MockContainer
+		exec     *execstore.Exec
+		s        service
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		con = mocks_container.NewMockContainer(mockCtrl)
+		exec = &execstore.Exec{ID: "exec-123", ContainerID: "123"}
+		s = service{
+			client: cdClient,
+			logger: logger,
+		}
+	})
+	It("should only log errors from publishing an event", func() {
+		con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{}, nil)
+		cdClient.EXPECT().PublishEvent(ctx, "/dockercompat/container/exec_start", gomock.Any()).Return(errors.New("publish error"))
+		logger.EXPECT().Errorf("failed to publish %s event of exec instance %s: %s", "exec_start", "exec-123", errors.New("publish error"))
+
+		s.publishExecEvent(ctx, con, exec, execStartEventAction, nil)
+	})
+	It("should not publish an event without the container info", func() {
+		con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{}, errors.New("info error"))
+		logger.EXPECT().Errorf("failed to publish %s event of exec instance %s: %s", "exec_die", "exec-123", errors.New("info error"))
+
+		exitCode := 0
+		s.publishExecEvent(ctx, con, exec, execDieEventAction, &exitCode)
+	})
+	It("should not append the command to the action of exec_die events", func() {
+		exec.Config.Cmd = []string{"ls"}
+		exitCode := 1
+
+		event := getExecEvent(execDieEventAction, exec, "test-con", "test-image", &exitCode)
+		Expect(event.Action).Should(Equal("exec_die"))
+		Expect(event.Actor.Attributes).Should(HaveKeyWithValue("exitCode", "1"))
+	})
+})
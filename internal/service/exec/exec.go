@@ -13,6 +13,7 @@ import (
 
 	"github.com/runfinch/finch-daemon/api/handlers/exec"
 	"github.com/runfinch/finch-daemon/internal/backend"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 	"github.com/runfinch/finch-daemon/pkg/flog"
 )
@@ -37,48 +38,28 @@ type execInstance struct {
 	Container containerd.Container
 	Task      containerd.Task
 	Process   containerd.Process
+	Exec      *execstore.Exec
 }
 
-func (s *service) getContainer(ctx context.Context, cid string) (containerd.Container, error) {
-	searchResult, err := s.client.SearchContainer(ctx, cid)
+// loadExecInstance loads the process of the exec instance with the id, which must belong to a running task.
+func (s *service) loadExecInstance(ctx context.Context, execID string, attach cio.Attach) (*execInstance, error) {
+	con, exec, err := execstore.Find(ctx, s.client, execID)
 	if err != nil {
-		s.logger.Errorf("failed to search container: %s. error: %v", cid, err)
 		return nil, err
 	}
-	matchCount := len(searchResult)
-
-	// if container not found then return NotFound error.
-	if matchCount == 0 {
-		s.logger.Debugf("no such container: %s", cid)
-		return nil, errdefs.NewNotFound(fmt.Errorf("no such container: %s", cid))
-	}
-	// if more than one container found with the provided id return error.
-	if matchCount > 1 {
-		s.logger.Debugf("multiple IDs found with provided prefix: %s, total containers found: %d", cid, matchCount)
-		return nil, fmt.Errorf("multiple IDs found with provided prefix: %s", cid)
-	}
-
-	return searchResult[0], nil
+	return s.loadProcess(ctx, con, exec, attach)
 }
 
-func (s *service) loadExecInstance(ctx context.Context, conID, execID string, attach cio.Attach) (*execInstance, error) {
-	con, err := s.getContainer(ctx, conID)
-	if err != nil {
-		if cerrdefs.IsNotFound(err) || errdefs.IsNotFound(err) {
-			return nil, errdefs.NewNotFound(fmt.Errorf("container not found: %v", err))
-		}
-		return nil, err
-	}
-
+func (s *service) loadProcess(ctx context.Context, con containerd.Container, exec *execstore.Exec, attach cio.Attach) (*execInstance, error) {
 	task, err := con.Task(ctx, nil)
 	if err != nil {
 		if cerrdefs.IsNotFound(err) {
-			return nil, errdefs.NewNotFound(fmt.Errorf("task not found: %v", err))
+			return nil, errdefs.NewConflict(fmt.Errorf("container %s is not running", exec.ContainerID))
 		}
 		return nil, err
 	}
 
-	proc, err := task.LoadProcess(ctx, execID, attach)
+	proc, err := task.LoadProcess(ctx, exec.ID, attach)
 	if err != nil {
 		if cerrdefs.IsNotFound(err) {
 			return nil, errdefs.NewNotFound(fmt.Errorf("process not found: %v", err))
@@ -90,5 +71,6 @@ func (s *service) loadExecInstance(ctx context.Context, conID, execID string, at
 		Container: con,
 		Task:      task,
 		Process:   proc,
+		Exec:      exec,
 	}, nil
 }
@@ -6,15 +6,19 @@ package exec
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
 	"github.com/containerd/containerd/v2/pkg/cio"
 	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/typeurl/v2"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
@@ -38,6 +42,7 @@ var _ = Describe("Exec API service common ", func() {
 		proc     *mocks_container.MockProcess
 		cid      string
 		execId   string
+		exec     *execstore.Exec
 		s        service
 	)
 	BeforeEach(func() {
@@ -48,6 +53,7 @@ var _ = Describe("Exec API service common ", func() {
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
 		cid = "123"
 		execId = "exec-123"
+		exec = &execstore.Exec{ID: execId, ContainerID: cid}
 		con = mocks_container.NewMockContainer(mockCtrl)
 		task = mocks_container.NewMockTask(mockCtrl)
 		proc = mocks_container.NewMockProcess(mockCtrl)
@@ -57,137 +63,121 @@ var _ = Describe("Exec API service common ", func() {
 			logger: logger,
 		}
 	})
-	Context("getContainer", func() {
-		It("should return the container object if it was found", func() {
-			// search method returns one container
-			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
-				[]containerd.Container{con}, nil)
-
-			result, err := s.getContainer(ctx, cid)
-			Expect(result).Should(Equal(con))
-			Expect(err).Should(BeNil())
-		})
-		It("should return an error if search container method fails", func() {
-			// search method returns no container
-			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
-				nil, errors.New("search container error"))
-			logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
-
-			result, err := s.getContainer(ctx, cid)
-			Expect(result).Should(BeNil())
-			Expect(err).Should(Not(BeNil()))
-		})
-		It("should return NotFound error if no container was found", func() {
-			// search method returns no container
-			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
-				[]containerd.Container{}, nil)
-			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
-
-			result, err := s.getContainer(ctx, cid)
-			Expect(result).Should(BeNil())
-			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
-		})
-		It("should return an error if multiple containers were found", func() {
-			// search method returns two containers
-			cdClient.EXPECT().SearchContainer(gomock.Any(), cid).Return(
-				[]containerd.Container{con, con}, nil)
-			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
-
-			result, err := s.getContainer(ctx, cid)
-			Expect(result).Should(BeNil())
-			Expect(err).Should(Not(BeNil()))
-		})
-	})
 	Context("loadExecInstance", func() {
 		It("should return the exec instance if found", func() {
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, exec)
 			con.EXPECT().Task(ctx, nil).Return(task, nil)
 			task.EXPECT().LoadProcess(ctx, execId, nil).Return(proc, nil)
 
-			Expect(s.loadExecInstance(ctx, cid, execId, nil)).Should(Equal(&execInstance{
+			Expect(s.loadExecInstance(ctx, execId, nil)).Should(Equal(&execInstance{
 				Container: con,
 				Task:      task,
 				Process:   proc,
+				Exec:      exec,
 			}))
 		})
 		It("should use the provided attach to attach to the process", func() {
 			attach := cio.NewAttach()
 
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, exec)
 			con.EXPECT().Task(ctx, nil).Return(task, nil)
 			// we can't check equality because cio.Attach is a function type, which can't be compared. non-nil should
 			// be sufficient, though, as the function either uses the provided attach or nil
 			task.EXPECT().LoadProcess(ctx, execId, gomock.Not(nil)).Return(proc, nil)
 
-			Expect(s.loadExecInstance(ctx, cid, execId, attach)).Should(Equal(&execInstance{
+			Expect(s.loadExecInstance(ctx, execId, attach)).Should(Equal(&execInstance{
 				Container: con,
 				Task:      task,
 				Process:   proc,
+				Exec:      exec,
 			}))
 		})
-		It("should return a NotFound error if the container is not found", func() {
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{}, nil)
-			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
+		It("should return a NotFound error if the exec instance is not found", func() {
+			expectExecs(ctx, cdClient, con, &execstore.Exec{ID: "exec-456", ContainerID: cid})
 
-			result, err := s.loadExecInstance(ctx, cid, execId, nil)
-			Expect(err).ShouldNot(BeNil())
-			Expect(err.Error()).Should(HavePrefix("container not found:"))
+			result, err := s.loadExecInstance(ctx, execId, nil)
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+			Expect(err.Error()).Should(Equal("no such exec instance: exec-123"))
 			Expect(result).Should(BeNil())
 		})
-		It("should pass through other errors from getContainer", func() {
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{}, errors.New("getContainer error"))
-			logger.EXPECT().Errorf(gomock.Any(), gomock.Any(), gomock.Any())
+		It("should pass through errors from listing the containers", func() {
+			cdClient.EXPECT().GetContainers(ctx).Return(nil, errors.New("list error"))
 
-			result, err := s.loadExecInstance(ctx, cid, execId, nil)
-			Expect(err).ShouldNot(BeNil())
-			Expect(err.Error()).Should(Equal("getContainer error"))
+			result, err := s.loadExecInstance(ctx, execId, nil)
+			Expect(err).Should(MatchError("list error"))
 			Expect(result).Should(BeNil())
 		})
-		It("should return a NotFound error if the task is not found", func() {
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{con}, nil)
+		It("should return a Conflict error if the task is not found", func() {
+			expectExecs(ctx, cdClient, con, exec)
 			con.EXPECT().Task(ctx, nil).Return(nil, cerrdefs.ErrNotFound)
 
-			result, err := s.loadExecInstance(ctx, cid, execId, nil)
-			Expect(err).ShouldNot(BeNil())
-			Expect(err.Error()).Should(HavePrefix("task not found:"))
+			result, err := s.loadExecInstance(ctx, execId, nil)
+			Expect(errdefs.IsConflict(err)).Should(BeTrue())
+			Expect(err.Error()).Should(Equal("container 123 is not running"))
 			Expect(result).Should(BeNil())
 		})
 		It("should pass through other errors from con.Task", func() {
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, exec)
 			con.EXPECT().Task(ctx, nil).Return(nil, errors.New("task error"))
 
-			result, err := s.loadExecInstance(ctx, cid, execId, nil)
+			result, err := s.loadExecInstance(ctx, execId, nil)
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).Should(Equal("task error"))
 			Expect(result).Should(BeNil())
 		})
 		It("should return a NotFound error if the process is not found", func() {
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, exec)
 			con.EXPECT().Task(ctx, nil).Return(task, nil)
 			task.EXPECT().LoadProcess(ctx, execId, nil).Return(nil, cerrdefs.ErrNotFound)
 
-			result, err := s.loadExecInstance(ctx, cid, execId, nil)
+			result, err := s.loadExecInstance(ctx, execId, nil)
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).Should(HavePrefix("process not found:"))
 			Expect(result).Should(BeNil())
 		})
 		It("should pass through other errors from task.Process", func() {
-			cdClient.EXPECT().SearchContainer(ctx, cid).Return(
-				[]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, exec)
 			con.EXPECT().Task(ctx, nil).Return(task, nil)
 			task.EXPECT().LoadProcess(ctx, execId, nil).Return(nil, errors.New("process error"))
 
-			result, err := s.loadExecInstance(ctx, cid, execId, nil)
+			result, err := s.loadExecInstance(ctx, execId, nil)
 			Expect(err).ShouldNot(BeNil())
 			Expect(err.Error()).Should(Equal("process error"))
 			Expect(result).Should(BeNil())
 		})
 	})
 })
+
+// expectExecs sets up the container to be the only container of the namespace, with the exec instances.
+func expectExecs(ctx context.Context, cdClient *mocks_backend.MockContainerdClient, con *mocks_container.MockContainer, execs ...*execstore.Exec) {
+	ext, err := typeurl.MarshalAny(&execstore.Execs{Execs: execs})
+	Expect(err).ShouldNot(HaveOccurred())
+	cdClient.EXPECT().GetContainers(ctx).Return([]containerd.Container{con}, nil)
+	con.EXPECT().Info(ctx, gomock.Any()).Return(containers.Container{
+		Extensions: map[string]typeurl.Any{execstore.Extension: ext},
+	}, nil)
+}
+
+// expectExecUpdate expects the exec instances of the container to be read then saved,
+// and returns the exec instances as they are saved.
+func expectExecUpdate(cdClient *mocks_backend.MockContainerdClient, con *mocks_container.MockContainer, execs ...*execstore.Exec) func() []*execstore.Exec {
+	ext, err := typeurl.MarshalAny(&execstore.Execs{Execs: execs})
+	Expect(err).ShouldNot(HaveOccurred())
+	var (
+		mu    sync.Mutex
+		saved []*execstore.Exec
+	)
+	con.EXPECT().Extensions(gomock.Any()).Return(map[string]typeurl.Any{execstore.Extension: ext}, nil)
+	cdClient.EXPECT().UpdateContainerExtension(gomock.Any(), gomock.Any(), execstore.Extension, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _ string, ext any) error {
+			mu.Lock()
+			defer mu.Unlock()
+			saved = ext.(*execstore.Execs).Execs
+			return nil
+		})
+	return func() []*execstore.Exec {
+		mu.Lock()
+		defer mu.Unlock()
+		return saved
+	}
+}
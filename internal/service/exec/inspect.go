@@ -5,47 +5,86 @@ package exec
 
 import (
 	"context"
+	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
 
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
-func (s *service) Inspect(ctx context.Context, conID string, execId string) (*types.ExecInspect, error) {
-	exec, err := s.loadExecInstance(ctx, conID, execId, nil)
+func (s *service) Inspect(ctx context.Context, execId string) (*types.ExecInspect, error) {
+	con, exec, err := execstore.Find(ctx, s.client, execId)
 	if err != nil {
 		return nil, err
 	}
-
-	var running bool
-	var exitCode int
-	status, err := exec.Process.Status(ctx)
-	if err != nil {
-		s.logger.Warnf("error getting process status for proc %s: %v", exec.Process.ID(), err)
-		running = false
-		exitCode = 0
-	} else {
-		running = status.Status == containerd.Running
-		exitCode = int(status.ExitStatus)
+	if exec.Running {
+		exec = s.refreshExec(ctx, con, exec)
 	}
 
-	inspectResult := &types.ExecInspect{
-		ID:            exec.Process.ID(),
-		Running:       running,
-		ExitCode:      &exitCode,
-		ProcessConfig: &types.ExecProcessConfig{},
-		CanRemove:     running,
-		ContainerID:   exec.Container.ID(),
-		DetachKeys:    []byte(""),
-		Pid:           int(exec.Process.Pid()),
+	var entrypoint string
+	var args []string
+	if len(exec.Config.Cmd) > 0 {
+		entrypoint = exec.Config.Cmd[0]
+		args = exec.Config.Cmd[1:]
 	}
+	privileged := exec.Config.Privileged
+
+	return &types.ExecInspect{
+		ID:       exec.ID,
+		Running:  exec.Running,
+		ExitCode: exec.ExitCode,
+		ProcessConfig: &types.ExecProcessConfig{
+			Tty:        exec.Config.Tty,
+			Entrypoint: entrypoint,
+			Arguments:  args,
+			Privileged: &privileged,
+			User:       exec.Config.User,
+		},
+		OpenStdin:   exec.Config.AttachStdin,
+		OpenStderr:  exec.Config.AttachStderr,
+		OpenStdout:  exec.Config.AttachStdout,
+		CanRemove:   exec.ExitCode != nil,
+		ContainerID: exec.ContainerID,
+		DetachKeys:  []byte(exec.Config.DetachKeys),
+		Pid:         exec.Pid,
+	}, nil
+}
 
-	if exec.Process.IO() != nil {
-		inspectResult.ProcessConfig.Tty = exec.Process.IO().Config().Terminal
-		inspectResult.OpenStdin = exec.Process.IO().Config().Stdin != ""
-		inspectResult.OpenStdout = exec.Process.IO().Config().Stdout != ""
-		inspectResult.OpenStderr = exec.Process.IO().Config().Stderr != ""
+// refreshExec records the exit of the process of a running exec instance if it exited while no one was waiting
+// for it, e.g. while the daemon was down, and returns the up-to-date exec instance.
+func (s *service) refreshExec(ctx context.Context, con containerd.Container, exec *execstore.Exec) *execstore.Exec {
+	exitCode := int(containerd.UnknownExitStatus)
+	instance, err := s.loadProcess(ctx, con, exec, nil)
+	switch {
+	case err == nil:
+		status, err := instance.Process.Status(ctx)
+		if err != nil {
+			s.logger.Warnf("error getting process status for proc %s: %v", exec.ID, err)
+			return exec
+		}
+		if status.Status != containerd.Stopped {
+			return exec
+		}
+		exitCode = int(status.ExitStatus)
+	case errdefs.IsNotFound(err) || errdefs.IsConflict(err):
+		// the process or the task is gone with its exit status.
+	default:
+		s.logger.Warnf("error loading process %s: %v", exec.ID, err)
+		return exec
 	}
 
-	return inspectResult, nil
+	updated, err := execstore.Update(ctx, s.client, con, exec.ID, func(e *execstore.Exec) {
+		if e.Running {
+			e.Running = false
+			e.ExitCode = &exitCode
+			e.FinishedAt = time.Now()
+		}
+	})
+	if err != nil {
+		s.logger.Warnf("failed to record the exit of exec instance %s: %v", exec.ID, err)
+		return exec
+	}
+	return updated
 }
@@ -6,10 +6,8 @@ package exec
 import (
 	"context"
 	"errors"
-	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
-	"github.com/containerd/containerd/v2/pkg/cio"
 	cerrdefs "github.com/containerd/errdefs"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -17,8 +15,8 @@ import (
 
 	"github.com/runfinch/finch-daemon/api/handlers/exec"
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
-	"github.com/runfinch/finch-daemon/mocks/mocks_cio"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
@@ -33,7 +31,6 @@ var _ = Describe("Exec Inspect API ", func() {
 		con      *mocks_container.MockContainer
 		task     *mocks_container.MockTask
 		proc     *mocks_container.MockProcess
-		procIO   *mocks_cio.MockIO
 		s        exec.Service
 	)
 	BeforeEach(func() {
@@ -42,101 +39,122 @@ var _ = Describe("Exec Inspect API ", func() {
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
 		con = mocks_container.NewMockContainer(mockCtrl)
+		con.EXPECT().ID().Return("123").AnyTimes()
 		task = mocks_container.NewMockTask(mockCtrl)
 		proc = mocks_container.NewMockProcess(mockCtrl)
-		procIO = mocks_cio.NewMockIO(mockCtrl)
 		s = NewService(cdClient, logger)
 	})
 	Context("service", func() {
-		It("should not return an error on success", func() {
-			now := time.Now()
-			cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
-			con.EXPECT().Task(ctx, nil).Return(task, nil)
-			task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
-			proc.EXPECT().Status(ctx).Return(containerd.Status{
-				Status:     containerd.Running,
-				ExitStatus: 0,
-				ExitTime:   now,
-			}, nil)
-			proc.EXPECT().ID().Return("exec-123")
-			con.EXPECT().ID().Return("123")
-			proc.EXPECT().Pid().Return(uint32(123))
-			proc.EXPECT().IO().Times(5).Return(procIO)
-			procIO.EXPECT().Config().Times(4).Return(cio.Config{
-				Terminal: false,
-				Stdin:    "",
-				Stdout:   "",
-				Stderr:   "",
+		It("should return the recorded exec instance", func() {
+			exitCode := 2
+			expectExecs(ctx, cdClient, con, &execstore.Exec{
+				ID:          "exec-123",
+				ContainerID: "123",
+				Config: types.ExecConfig{
+					User:         "foo",
+					Tty:          true,
+					AttachStdin:  true,
+					AttachStdout: true,
+					DetachKeys:   "ctrl-p",
+					Cmd:          []string{"sh", "-c", "exit 2"},
+				},
+				ExitCode: &exitCode,
+				Pid:      123,
 			})
 
-			exitCode := 0
-			Expect(s.Inspect(ctx, "123", "exec-123")).Should(Equal(&types.ExecInspect{
+			privileged := false
+			Expect(s.Inspect(ctx, "exec-123")).Should(Equal(&types.ExecInspect{
 				ID:       "exec-123",
-				Running:  true,
+				Running:  false,
 				ExitCode: &exitCode,
 				ProcessConfig: &types.ExecProcessConfig{
-					Tty: false,
+					Tty:        true,
+					Entrypoint: "sh",
+					Arguments:  []string{"-c", "exit 2"},
+					Privileged: &privileged,
+					User:       "foo",
 				},
-				OpenStdin:   false,
+				OpenStdin:   true,
 				OpenStderr:  false,
-				OpenStdout:  false,
+				OpenStdout:  true,
 				CanRemove:   true,
 				ContainerID: "123",
-				DetachKeys:  []byte(""),
+				DetachKeys:  []byte("ctrl-p"),
 				Pid:         123,
 			}))
 		})
-		It("should return a NotFound error if loadExecInstance returns NotFound", func() {
-			cdClient.EXPECT().SearchContainer(ctx, "123").Return(nil, cerrdefs.ErrNotFound)
-			logger.EXPECT().Errorf("failed to search container: %s. error: %v", "123", gomock.Any())
+		It("should not reload the process of an exec instance which was not started", func() {
+			expectExecs(ctx, cdClient, con, &execstore.Exec{ID: "exec-123", ContainerID: "123"})
 
-			inspectResult, err := s.Inspect(ctx, "123", "exec-123")
-			Expect(err).ShouldNot(BeNil())
-			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
-			Expect(inspectResult).Should(BeNil())
+			inspectResult, err := s.Inspect(ctx, "exec-123")
+			Expect(err).Should(BeNil())
+			Expect(inspectResult.Running).Should(BeFalse())
+			Expect(inspectResult.ExitCode).Should(BeNil())
+			Expect(inspectResult.CanRemove).Should(BeFalse())
 		})
-		It("should pass through non-NotFound errors from loadExecInstance", func() {
-			cdClient.EXPECT().SearchContainer(ctx, "123").Return(nil, errors.New("getContainer error"))
-			logger.EXPECT().Errorf("failed to search container: %s. error: %v", "123", gomock.Any())
+		It("should check that a running exec instance is still running", func() {
+			expectExecs(ctx, cdClient, con, &execstore.Exec{ID: "exec-123", ContainerID: "123", Running: true, Pid: 123})
+			con.EXPECT().Task(ctx, nil).Return(task, nil)
+			task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
+			proc.EXPECT().Status(ctx).Return(containerd.Status{Status: containerd.Running}, nil)
 
-			inspectResult, err := s.Inspect(ctx, "123", "exec-123")
-			Expect(err).ShouldNot(BeNil())
-			Expect(err.Error()).Should(Equal("getContainer error"))
-			Expect(inspectResult).Should(BeNil())
+			inspectResult, err := s.Inspect(ctx, "exec-123")
+			Expect(err).Should(BeNil())
+			Expect(inspectResult.Running).Should(BeTrue())
+			Expect(inspectResult.ExitCode).Should(BeNil())
+			Expect(inspectResult.Pid).Should(Equal(123))
+		})
+		It("should record the exit of a running exec instance whose process stopped", func() {
+			exec := &execstore.Exec{ID: "exec-123", ContainerID: "123", Running: true}
+			expectExecs(ctx, cdClient, con, exec)
+			con.EXPECT().Task(ctx, nil).Return(task, nil)
+			task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
+			proc.EXPECT().Status(ctx).Return(containerd.Status{Status: containerd.Stopped, ExitStatus: 3}, nil)
+			saved := expectExecUpdate(cdClient, con, exec)
+
+			inspectResult, err := s.Inspect(ctx, "exec-123")
+			Expect(err).Should(BeNil())
+			Expect(inspectResult.Running).Should(BeFalse())
+			Expect(*inspectResult.ExitCode).Should(Equal(3))
+			Expect(*saved()[0].ExitCode).Should(Equal(3))
+		})
+		It("should record the exit of a running exec instance whose process is gone", func() {
+			exec := &execstore.Exec{ID: "exec-123", ContainerID: "123", Running: true}
+			expectExecs(ctx, cdClient, con, exec)
+			con.EXPECT().Task(ctx, nil).Return(nil, cerrdefs.ErrNotFound)
+			expectExecUpdate(cdClient, con, exec)
+
+			inspectResult, err := s.Inspect(ctx, "exec-123")
+			Expect(err).Should(BeNil())
+			Expect(inspectResult.Running).Should(BeFalse())
+			Expect(*inspectResult.ExitCode).Should(Equal(int(containerd.UnknownExitStatus)))
 		})
 		It("should log a warning if proc.Status returns an error", func() {
-			cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, &execstore.Exec{ID: "exec-123", ContainerID: "123", Running: true})
 			con.EXPECT().Task(ctx, nil).Return(task, nil)
 			task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 			proc.EXPECT().Status(ctx).Return(containerd.Status{}, errors.New("status error"))
-			proc.EXPECT().ID().Return("exec-123").Times(2)
 			logger.EXPECT().Warnf("error getting process status for proc %s: %v", "exec-123", gomock.Any())
-			con.EXPECT().ID().Return("123")
-			proc.EXPECT().Pid().Return(uint32(123))
-			proc.EXPECT().IO().Times(5).Return(procIO)
-			procIO.EXPECT().Config().Times(4).Return(cio.Config{
-				Terminal: false,
-				Stdin:    "",
-				Stdout:   "",
-				Stderr:   "",
-			})
 
-			exitCode := 0
-			Expect(s.Inspect(ctx, "123", "exec-123")).Should(Equal(&types.ExecInspect{
-				ID:       "exec-123",
-				Running:  false,
-				ExitCode: &exitCode,
-				ProcessConfig: &types.ExecProcessConfig{
-					Tty: false,
-				},
-				OpenStdin:   false,
-				OpenStderr:  false,
-				OpenStdout:  false,
-				CanRemove:   false,
-				ContainerID: "123",
-				DetachKeys:  []byte(""),
-				Pid:         123,
-			}))
+			inspectResult, err := s.Inspect(ctx, "exec-123")
+			Expect(err).Should(BeNil())
+			Expect(inspectResult.Running).Should(BeTrue())
+		})
+		It("should return a NotFound error if the exec instance is not found", func() {
+			expectExecs(ctx, cdClient, con)
+
+			inspectResult, err := s.Inspect(ctx, "exec-123")
+			Expect(err).ShouldNot(BeNil())
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+			Expect(inspectResult).Should(BeNil())
+		})
+		It("should pass through errors from finding the exec instance", func() {
+			cdClient.EXPECT().GetContainers(ctx).Return(nil, errors.New("list error"))
+
+			inspectResult, err := s.Inspect(ctx, "exec-123")
+			Expect(err).ShouldNot(BeNil())
+			Expect(err.Error()).Should(Equal("list error"))
+			Expect(inspectResult).Should(BeNil())
 		})
 	})
 })
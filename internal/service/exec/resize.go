@@ -10,7 +10,7 @@ import (
 )
 
 func (s *service) Resize(ctx context.Context, options *types.ExecResizeOptions) error {
-	exec, err := s.loadExecInstance(ctx, options.ConID, options.ExecID, nil)
+	exec, err := s.loadExecInstance(ctx, options.ExecID, nil)
 	if err != nil {
 		return err
 	}
@@ -7,14 +7,13 @@ import (
 	"context"
 	"errors"
 
-	containerd "github.com/containerd/containerd/v2/client"
-	cerrdefs "github.com/containerd/errdefs"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/runfinch/finch-daemon/api/handlers/exec"
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
@@ -44,13 +43,12 @@ var _ = Describe("Exec Resize API ", func() {
 	})
 	Context("service", func() {
 		It("should not return an error on success", func() {
-			cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, &execstore.Exec{ID: "exec-123", ContainerID: "123", Running: true})
 			con.EXPECT().Task(ctx, nil).Return(task, nil)
 			task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 			proc.EXPECT().Resize(ctx, uint32(321), uint32(123)).Return(nil)
 
 			err := s.Resize(ctx, &types.ExecResizeOptions{
-				ConID:  "123",
 				ExecID: "exec-123",
 				Height: 123,
 				Width:  321,
@@ -58,11 +56,9 @@ var _ = Describe("Exec Resize API ", func() {
 			Expect(err).Should(BeNil())
 		})
 		It("should return a not found error if the exec instance is not found", func() {
-			cdClient.EXPECT().SearchContainer(ctx, "123").Return(nil, cerrdefs.ErrNotFound)
-			logger.EXPECT().Errorf("failed to search container: %s. error: %v", "123", gomock.Any())
+			expectExecs(ctx, cdClient, con)
 
 			err := s.Resize(ctx, &types.ExecResizeOptions{
-				ConID:  "123",
 				ExecID: "exec-123",
 				Height: 123,
 				Width:  321,
@@ -71,13 +67,12 @@ var _ = Describe("Exec Resize API ", func() {
 			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 		})
 		It("should pass through any errors from resize", func() {
-			cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+			expectExecs(ctx, cdClient, con, &execstore.Exec{ID: "exec-123", ContainerID: "123", Running: true})
 			con.EXPECT().Task(ctx, nil).Return(task, nil)
 			task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 			proc.EXPECT().Resize(ctx, uint32(321), uint32(123)).Return(errors.New("resize error"))
 
 			err := s.Resize(ctx, &types.ExecResizeOptions{
-				ConID:  "123",
 				ExecID: "exec-123",
 				Height: 123,
 				Width:  321,
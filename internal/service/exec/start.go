@@ -7,9 +7,9 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/cio"
@@ -17,6 +17,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/signalutil"
 
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
@@ -45,6 +46,17 @@ func (s *StdinCloser) Read(p []byte) (int, error) {
 }
 
 func (s *service) Start(ctx context.Context, options *types.ExecStartOptions) error {
+	con, rec, err := execstore.Find(ctx, s.client, options.ExecID)
+	if err != nil {
+		return err
+	}
+	if rec.Running {
+		return errdefs.NewConflict(fmt.Errorf("exec instance %s is already running", rec.ID))
+	}
+	if rec.ExitCode != nil {
+		return errdefs.NewConflict(fmt.Errorf("exec instance %s has already run", rec.ID))
+	}
+
 	var attach cio.Attach
 	var in io.Reader
 	stdinC := &StdinCloser{
@@ -54,32 +66,29 @@ func (s *service) Start(ctx context.Context, options *types.ExecStartOptions) er
 		in = stdinC
 		attach = cio.NewAttach(cio.WithStreams(in, options.Stdout, options.Stderr))
 	}
-	exec, err := s.loadExecInstance(ctx, options.ConID, options.ExecID, attach)
+	exec, err := s.loadProcess(ctx, con, rec, attach)
 	if err != nil {
-		switch {
-		case strings.HasPrefix(err.Error(), "task not found"):
-			return errdefs.NewConflict(fmt.Errorf("container %s is not running", options.ConID))
-		default:
-			return err
-		}
+		return err
 	}
 
 	taskStatus, err := exec.Task.Status(ctx)
 	if err != nil {
 		if cerrdefs.IsNotFound(err) {
-			return errdefs.NewConflict(fmt.Errorf("container %s is not running", options.ConID))
+			return errdefs.NewConflict(fmt.Errorf("container %s is not running", rec.ContainerID))
 		}
 		return err
 	}
 	if taskStatus.Status != containerd.Running {
-		return errdefs.NewConflict(fmt.Errorf("container %s is not running", options.ConID))
+		return errdefs.NewConflict(fmt.Errorf("container %s is not running", rec.ContainerID))
 	}
 
 	stdinC.Closer = func() {
 		exec.Process.CloseIO(ctx, containerd.WithStdinCloser)
 	}
 
-	statusC, err := exec.Process.Wait(ctx)
+	// the process is waited for beyond the request in detached mode, to record its exit.
+	waitCtx := context.WithoutCancel(ctx)
+	statusC, err := exec.Process.Wait(waitCtx)
 	if err != nil {
 		return err
 	}
@@ -101,11 +110,22 @@ func (s *service) Start(ctx context.Context, options *types.ExecStartOptions) er
 	if err = exec.Process.Start(ctx); err != nil {
 		return err
 	}
+	if _, err = execstore.Update(ctx, s.client, con, rec.ID, func(e *execstore.Exec) {
+		e.Running = true
+		e.Pid = int(exec.Process.Pid())
+		e.StartedAt = time.Now()
+	}); err != nil {
+		s.logger.Warnf("failed to record the start of exec instance %s: %v", rec.ID, err)
+	}
+	s.publishExecEvent(ctx, con, rec, execStartEventAction, nil)
+
+	exitC := make(chan containerd.ExitStatus, 1)
+	go s.waitExec(waitCtx, exec, statusC, exitC)
 	if options.Detach {
 		return nil
 	}
 
-	status := <-statusC
+	status := <-exitC
 	code, _, err := status.Result()
 	if err != nil {
 		return err
@@ -116,3 +136,27 @@ func (s *service) Start(ctx context.Context, options *types.ExecStartOptions) er
 
 	return nil
 }
+
+// waitExec records the exit status of the process of an exec instance and deletes the process,
+// then forwards the exit status to exitC.
+func (s *service) waitExec(ctx context.Context, exec *execInstance, statusC <-chan containerd.ExitStatus, exitC chan<- containerd.ExitStatus) {
+	status := <-statusC
+	code, _, err := status.Result()
+	if err != nil {
+		s.logger.Warnf("failed to wait for exec instance %s: %v", exec.Exec.ID, err)
+		code = containerd.UnknownExitStatus
+	}
+	exitCode := int(code)
+	if _, err = execstore.Update(ctx, s.client, exec.Container, exec.Exec.ID, func(e *execstore.Exec) {
+		e.Running = false
+		e.ExitCode = &exitCode
+		e.FinishedAt = time.Now()
+	}); err != nil {
+		s.logger.Warnf("failed to record the exit of exec instance %s: %v", exec.Exec.ID, err)
+	}
+	s.publishExecEvent(ctx, exec.Container, exec.Exec, execDieEventAction, &exitCode)
+	if _, err = exec.Process.Delete(ctx); err != nil {
+		s.logger.Warnf("failed to delete the process of exec instance %s: %v", exec.Exec.ID, err)
+	}
+	exitC <- status
+}
@@ -8,16 +8,21 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync"
 	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
 	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/api/handlers/exec"
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/internal/execstore"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_container"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
@@ -39,6 +44,7 @@ var _ = Describe("Exec Start API ", func() {
 		statusC     chan containerd.ExitStatus
 		s           exec.Service
 		startOpts   *types.ExecStartOptions
+		execRecord  *execstore.Exec
 	)
 	BeforeEach(func() {
 		ctx = context.Background()
@@ -46,6 +52,7 @@ var _ = Describe("Exec Start API ", func() {
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
 		con = mocks_container.NewMockContainer(mockCtrl)
+		con.EXPECT().ID().Return("123").AnyTimes()
 		task = mocks_container.NewMockTask(mockCtrl)
 		proc = mocks_container.NewMockProcess(mockCtrl)
 		s = NewService(cdClient, logger)
@@ -58,6 +65,11 @@ var _ = Describe("Exec Start API ", func() {
 			success = true
 		}
 		statusC = make(chan containerd.ExitStatus)
+		execRecord = &execstore.Exec{
+			ID:          "exec-123",
+			ContainerID: "123",
+			Config:      types.ExecConfig{Cmd: []string{"sh", "-c", "exit 3"}},
+		}
 	})
 	Context("service", func() {
 		Context("detach", func() {
@@ -68,7 +80,6 @@ var _ = Describe("Exec Start API ", func() {
 						Tty:         false,
 						ConsoleSize: &[2]uint{123, 321},
 					},
-					ConID:           "123",
 					ExecID:          "exec-123",
 					Stdin:           rw,
 					Stdout:          rw,
@@ -77,29 +88,95 @@ var _ = Describe("Exec Start API ", func() {
 				}
 			})
 			It("should not return error on success", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
 					Status: containerd.Running,
 				}, nil)
-				proc.EXPECT().Wait(ctx).Return(statusC, nil)
+				proc.EXPECT().Wait(gomock.Any()).Return(statusC, nil)
 				proc.EXPECT().Start(ctx).Return(nil)
+				proc.EXPECT().Pid().Return(uint32(456))
+				started := expectExecUpdate(cdClient, con, execRecord)
+				exited := expectExecUpdate(cdClient, con, execRecord)
+				events := expectExecEvents(cdClient, con)
+				deleted := make(chan struct{})
+				proc.EXPECT().Delete(gomock.Any()).DoAndReturn(
+					func(context.Context, ...containerd.ProcessDeleteOpts) (*containerd.ExitStatus, error) {
+						close(deleted)
+						return nil, nil
+					})
 
 				err := s.Start(ctx, startOpts)
 				Expect(err).Should(BeNil())
 				Expect(success).Should(BeTrue())
+				Expect(started()[0].Running).Should(BeTrue())
+				Expect(started()[0].Pid).Should(Equal(456))
+				Expect(started()[0].StartedAt).ShouldNot(BeZero())
+
+				// the exit of the process is recorded after the request returns.
+				statusC <- *containerd.NewExitStatus(uint32(3), time.Now(), nil)
+				Eventually(deleted).Should(BeClosed())
+				Expect(exited()[0].Running).Should(BeFalse())
+				Expect(*exited()[0].ExitCode).Should(Equal(3))
+				Expect(events()).Should(Equal([]*eventtype.Event{
+					{
+						ID:     "123",
+						Status: "exec_start: sh -c exit 3",
+						Type:   "container",
+						Action: "exec_start: sh -c exit 3",
+						Actor: eventtype.EventActor{
+							Id: "123",
+							Attributes: map[string]string{
+								"name":   "test-con",
+								"image":  "test-image",
+								"execID": "exec-123",
+							},
+						},
+					},
+					{
+						ID:     "123",
+						Status: "exec_die",
+						Type:   "container",
+						Action: "exec_die",
+						Actor: eventtype.EventActor{
+							Id: "123",
+							Attributes: map[string]string{
+								"name":     "test-con",
+								"image":    "test-image",
+								"execID":   "exec-123",
+								"exitCode": "3",
+							},
+						},
+					},
+				}))
 			})
-			It("should return a NotFound error if the container is not found", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return(nil, cerrdefs.ErrNotFound)
-				logger.EXPECT().Errorf("failed to search container: %s. error: %v", "123", gomock.Any())
+			It("should return a NotFound error if the exec instance is not found", func() {
+				expectExecs(ctx, cdClient, con)
 
 				err := s.Start(ctx, startOpts)
 				Expect(err).ShouldNot(BeNil())
 				Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 			})
+			It("should return a Conflict error if the exec instance is running", func() {
+				execRecord.Running = true
+				expectExecs(ctx, cdClient, con, execRecord)
+
+				err := s.Start(ctx, startOpts)
+				Expect(errdefs.IsConflict(err)).Should(BeTrue())
+				Expect(err.Error()).Should(Equal("exec instance exec-123 is already running"))
+			})
+			It("should return a Conflict error if the exec instance has already run", func() {
+				exitCode := 0
+				execRecord.ExitCode = &exitCode
+				expectExecs(ctx, cdClient, con, execRecord)
+
+				err := s.Start(ctx, startOpts)
+				Expect(errdefs.IsConflict(err)).Should(BeTrue())
+				Expect(err.Error()).Should(Equal("exec instance exec-123 has already run"))
+			})
 			It("should return a Conflict error if the task is not found", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(nil, cerrdefs.ErrNotFound)
 
 				err := s.Start(ctx, startOpts)
@@ -107,7 +184,7 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(errdefs.IsConflict(err)).Should(BeTrue())
 			})
 			It("should return a Conflict error if the task status cannot be found", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{}, cerrdefs.ErrNotFound)
@@ -117,7 +194,7 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(errdefs.IsConflict(err)).Should(BeTrue())
 			})
 			It("should return a Conflict error if the task status is not running", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
@@ -129,7 +206,7 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(errdefs.IsConflict(err)).Should(BeTrue())
 			})
 			It("should return a NotFound error if the process is not found", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(nil, cerrdefs.ErrNotFound)
 
@@ -137,17 +214,16 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(err).ShouldNot(BeNil())
 				Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 			})
-			It("should pass through errors from loadExecInstance", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{}, errors.New("getContainer error"))
-				logger.EXPECT().Errorf("failed to search container: %s. error: %v", "123", gomock.Any())
+			It("should pass through errors from finding the exec instance", func() {
+				cdClient.EXPECT().GetContainers(ctx).Return(nil, errors.New("list error"))
 
 				err := s.Start(ctx, startOpts)
 				Expect(err).ShouldNot(BeNil())
 				Expect(errdefs.IsNotFound(err)).Should(BeFalse())
-				Expect(err.Error()).Should(Equal("getContainer error"))
+				Expect(err.Error()).Should(Equal("list error"))
 			})
 			It("should pass through errors from task.Status", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{}, errors.New("status error"))
@@ -157,26 +233,26 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(err.Error()).Should(Equal("status error"))
 			})
 			It("should pass through errors from proc.Wait", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
 					Status: containerd.Running,
 				}, nil)
-				proc.EXPECT().Wait(ctx).Return(nil, errors.New("wait error"))
+				proc.EXPECT().Wait(gomock.Any()).Return(nil, errors.New("wait error"))
 
 				err := s.Start(ctx, startOpts)
 				Expect(err).ShouldNot(BeNil())
 				Expect(err.Error()).Should(Equal("wait error"))
 			})
 			It("should pass through errors from proc.Start", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", nil).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
 					Status: containerd.Running,
 				}, nil)
-				proc.EXPECT().Wait(ctx).Return(statusC, nil)
+				proc.EXPECT().Wait(gomock.Any()).Return(statusC, nil)
 				proc.EXPECT().Start(ctx).Return(errors.New("start error"))
 
 				err := s.Start(ctx, startOpts)
@@ -193,7 +269,6 @@ var _ = Describe("Exec Start API ", func() {
 						Tty:         true,
 						ConsoleSize: &[2]uint{123, 321},
 					},
-					ConID:           "123",
 					ExecID:          "exec-123",
 					Stdin:           rw,
 					Stdout:          rw,
@@ -203,15 +278,16 @@ var _ = Describe("Exec Start API ", func() {
 				now = time.Now()
 			})
 			It("should not throw any errors on success", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", gomock.Any()).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
 					Status: containerd.Running,
 				}, nil)
-				proc.EXPECT().Wait(ctx).Return(statusC, nil)
+				proc.EXPECT().Wait(gomock.Any()).Return(statusC, nil)
 				proc.EXPECT().Resize(ctx, uint32(321), uint32(123)).Return(nil)
 				proc.EXPECT().Start(ctx).Return(nil)
+				expectExecStartAndExit(cdClient, con, proc, execRecord)
 
 				go func() {
 					statusC <- *containerd.NewExitStatus(uint32(0), now, nil)
@@ -222,16 +298,17 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(success).Should(BeTrue())
 			})
 			It("should log errors from proc.Resize", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", gomock.Any()).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
 					Status: containerd.Running,
 				}, nil)
-				proc.EXPECT().Wait(ctx).Return(statusC, nil)
+				proc.EXPECT().Wait(gomock.Any()).Return(statusC, nil)
 				proc.EXPECT().Resize(ctx, uint32(321), uint32(123)).Return(errors.New("resize error"))
 				logger.EXPECT().Errorf("could not resize console: %v", errors.New("resize error"))
 				proc.EXPECT().Start(ctx).Return(nil)
+				expectExecStartAndExit(cdClient, con, proc, execRecord)
 
 				go func() {
 					statusC <- *containerd.NewExitStatus(uint32(0), now, nil)
@@ -242,15 +319,18 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(success).Should(BeTrue())
 			})
 			It("should return errors from the process", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", gomock.Any()).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
 					Status: containerd.Running,
 				}, nil)
-				proc.EXPECT().Wait(ctx).Return(statusC, nil)
+				proc.EXPECT().Wait(gomock.Any()).Return(statusC, nil)
 				proc.EXPECT().Resize(ctx, uint32(321), uint32(123)).Return(nil)
 				proc.EXPECT().Start(ctx).Return(nil)
+				expectExecStartAndExit(cdClient, con, proc, execRecord)
+
+				logger.EXPECT().Warnf("failed to wait for exec instance %s: %v", "exec-123", errors.New("process error"))
 
 				go func() {
 					statusC <- *containerd.NewExitStatus(uint32(0), now, errors.New("process error"))
@@ -262,15 +342,16 @@ var _ = Describe("Exec Start API ", func() {
 				Expect(success).Should(BeTrue())
 			})
 			It("should throw an error on a non-zero exit code", func() {
-				cdClient.EXPECT().SearchContainer(ctx, "123").Return([]containerd.Container{con}, nil)
+				expectExecs(ctx, cdClient, con, execRecord)
 				con.EXPECT().Task(ctx, nil).Return(task, nil)
 				task.EXPECT().LoadProcess(ctx, "exec-123", gomock.Any()).Return(proc, nil)
 				task.EXPECT().Status(ctx).Return(containerd.Status{
 					Status: containerd.Running,
 				}, nil)
-				proc.EXPECT().Wait(ctx).Return(statusC, nil)
+				proc.EXPECT().Wait(gomock.Any()).Return(statusC, nil)
 				proc.EXPECT().Resize(ctx, uint32(321), uint32(123)).Return(nil)
 				proc.EXPECT().Start(ctx).Return(nil)
+				expectExecStartAndExit(cdClient, con, proc, execRecord)
 
 				go func() {
 					statusC <- *containerd.NewExitStatus(uint32(1), now, nil)
@@ -285,6 +366,44 @@ var _ = Describe("Exec Start API ", func() {
 	})
 })
 
+// expectExecStartAndExit expects the start then the exit of the process of the exec instance to be recorded,
+// and the process to be deleted.
+func expectExecStartAndExit(cdClient *mocks_backend.MockContainerdClient, con *mocks_container.MockContainer, proc *mocks_container.MockProcess, exec *execstore.Exec) {
+	proc.EXPECT().Pid().Return(uint32(456))
+	expectExecUpdate(cdClient, con, exec)
+	expectExecUpdate(cdClient, con, exec)
+	proc.EXPECT().Delete(gomock.Any()).Return(nil, nil)
+	expectExecEvents(cdClient, con)
+}
+
+// expectExecEvents expects the exec_start then the exec_die events of the exec instance to be published,
+// and returns the published events.
+func expectExecEvents(cdClient *mocks_backend.MockContainerdClient, con *mocks_container.MockContainer) func() []*eventtype.Event {
+	var (
+		mu     sync.Mutex
+		events []*eventtype.Event
+	)
+	con.EXPECT().Info(gomock.Any(), gomock.Any()).Return(containers.Container{
+		Labels: map[string]string{labels.Name: "test-con"},
+		Image:  "test-image",
+	}, nil).Times(2)
+	publish := func(_ context.Context, _ string, e *eventtype.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+		return nil
+	}
+	gomock.InOrder(
+		cdClient.EXPECT().PublishEvent(gomock.Any(), "/dockercompat/container/exec_start", gomock.Any()).DoAndReturn(publish),
+		cdClient.EXPECT().PublishEvent(gomock.Any(), "/dockercompat/container/exec_die", gomock.Any()).DoAndReturn(publish),
+	)
+	return func() []*eventtype.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return events
+	}
+}
+
 type CloseableBuffer struct {
 	*bytes.Buffer
 }
@@ -0,0 +1,63 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	eventtype "github.com/runfinch/finch-daemon/api/events"
+)
+
+// The actions of the image events, as published by dockerd.
+const (
+	pullEventAction   = "pull"
+	pushEventAction   = "push"
+	tagEventAction    = "tag"
+	untagEventAction  = "untag"
+	deleteEventAction = "delete"
+	loadEventAction   = "load"
+	saveEventAction   = "save"
+	importEventAction = "import"
+)
+
+// publishImageEvent publishes an event of the image with the target digest and the name. Failures are only logged,
+// as the action has already happened.
+func (s *service) publishImageEvent(ctx context.Context, action string, target digest.Digest, name string) {
+	if err := s.client.PublishEvent(ctx, imageTopic(action), getImageEvent(action, target, name)); err != nil {
+		s.logger.Errorf("failed to publish %s event of image %s: %s", action, name, err)
+	}
+}
+
+func imageTopic(action string) string {
+	return fmt.Sprintf("/%s/%s/%s", eventtype.CompatibleTopicPrefix, eventType, action)
+}
+
+// getImageEvent returns an event of an image, which is identified by its digest and has its name as attribute.
+func getImageEvent(action string, target digest.Digest, name string) *eventtype.Event {
+	return &eventtype.Event{
+		ID:     target.String(),
+		Status: action,
+		Type:   eventType,
+		Action: action,
+		Actor: eventtype.EventActor{
+			Id: target.String(),
+			Attributes: map[string]string{
+				"name": name,
+			},
+		},
+	}
+}
+
+// familiarName returns the familiar form of the reference, e.g. "alpine:latest" for "docker.io/library/alpine:latest",
+// or the reference as is if it cannot be parsed.
+func familiarName(ref string) string {
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		return reference.FamiliarString(named)
+	}
+	return ref
+}
@@ -0,0 +1,75 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	"go.uber.org/mock/gomock"
+
+	eventtype "github.com/runfinch/finch-daemon/api/events"
+	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+)
+
+var _ = Describe("Image events", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		cdClient *mocks_backend.MockContainerdClient
+		target   digest.Digest
+		s        service
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		target = digest.FromString("test-image")
+		s = service{
+			client: cdClient,
+			logger: logger,
+		}
+	})
+	It("should publish the event with the name and the ID of the image", func() {
+		events := recordEvents(cdClient)
+
+		s.publishImageEvent(ctx, pullEventAction, target, "test-image:latest")
+		Expect(events()).Should(Equal([]*eventtype.Event{{
+			ID:     target.String(),
+			Status: "pull",
+			Type:   "image",
+			Action: "pull",
+			Actor: eventtype.EventActor{
+				Id:         target.String(),
+				Attributes: map[string]string{"name": "test-image:latest"},
+			},
+		}}))
+	})
+	It("should only log a failure to publish the event", func() {
+		cdClient.EXPECT().PublishEvent(ctx, "/dockercompat/image/delete", gomock.Any()).Return(errors.New("publish error"))
+		logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
+
+		s.publishImageEvent(ctx, deleteEventAction, target, target.String())
+	})
+})
+
+// recordEvents records the image events published through the client, and returns the events published so far.
+func recordEvents(cdClient *mocks_backend.MockContainerdClient) func() []*eventtype.Event {
+	var events []*eventtype.Event
+	cdClient.EXPECT().PublishEvent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, topic string, e *eventtype.Event) error {
+			Expect(topic).Should(Equal(imageTopic(e.Action)))
+			events = append(events, e)
+			return nil
+		}).AnyTimes()
+	return func() []*eventtype.Event {
+		return events
+	}
+}
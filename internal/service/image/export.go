@@ -6,14 +6,32 @@ package image
 import (
 	"context"
 	"io"
+	"slices"
 
+	"github.com/containerd/containerd/v2/core/images"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
-func (s *service) Export(ctx context.Context, name string, platform *ocispec.Platform, outStream io.Writer) error {
-	img, err := s.getImage(ctx, name)
-	if err != nil {
+// Export writes one tarball with all the images to outStream. The content shared by the images
+// is only written once, and the manifest.json and index.json of the tarball list all the images.
+func (s *service) Export(ctx context.Context, names []string, platform *ocispec.Platform, outStream io.Writer) error {
+	imageNames := make([]string, 0, len(names))
+	imgs := make([]*images.Image, 0, len(names))
+	for _, name := range names {
+		img, err := s.getImage(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(imageNames, img.Name) {
+			imageNames = append(imageNames, img.Name)
+			imgs = append(imgs, img)
+		}
+	}
+	if err := s.nctlImageSvc.ExportImage(ctx, imageNames, platform, outStream); err != nil {
 		return err
 	}
-	return s.nctlImageSvc.ExportImage(ctx, []string{img.Name}, platform, outStream)
+	for _, img := range imgs {
+		s.publishImageEvent(ctx, saveEventAction, img.Target.Digest, familiarName(img.Name))
+	}
+	return nil
 }
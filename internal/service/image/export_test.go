@@ -11,8 +11,10 @@ import (
 	"github.com/containerd/containerd/v2/core/images"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.uber.org/mock/gomock"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/api/handlers/image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
@@ -25,6 +27,7 @@ var _ = Describe("Image Export API", func() {
 		mockCtrl *gomock.Controller
 		logger   *mocks_logger.Logger
 		cdClient *mocks_backend.MockContainerdClient
+		events   func() []*eventtype.Event
 		ncClient *mocks_backend.MockNerdctlImageSvc
 		service  image.Service
 		name     string
@@ -34,6 +37,7 @@ var _ = Describe("Image Export API", func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		events = recordEvents(cdClient)
 		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
 		name = "test-image"
 		service = NewService(cdClient, ncClient, logger)
@@ -47,7 +51,7 @@ var _ = Describe("Image Export API", func() {
 				Return(nil)
 
 			var buf bytes.Buffer
-			err := service.Export(ctx, name, nil, &buf)
+			err := service.Export(ctx, []string{name}, nil, &buf)
 			Expect(err).Should(BeNil())
 		})
 		It("should return NotFound error if image not found", func() {
@@ -56,7 +60,7 @@ var _ = Describe("Image Export API", func() {
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
 
 			var buf bytes.Buffer
-			err := service.Export(ctx, name, nil, &buf)
+			err := service.Export(ctx, []string{name}, nil, &buf)
 			Expect(err).ShouldNot(BeNil())
 			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 		})
@@ -68,8 +72,35 @@ var _ = Describe("Image Export API", func() {
 				Return(errors.New("export error"))
 
 			var buf bytes.Buffer
-			err := service.Export(ctx, name, nil, &buf)
+			err := service.Export(ctx, []string{name}, nil, &buf)
 			Expect(err).ShouldNot(BeNil())
 		})
+		It("should export all the images once in one tarball", func() {
+			alpine := images.Image{Name: "docker.io/library/alpine:latest"}
+			busybox := images.Image{Name: "docker.io/library/busybox:latest"}
+			platform := &ocispec.Platform{OS: "linux", Architecture: "arm64"}
+			cdClient.EXPECT().SearchImage(gomock.Any(), "alpine").Return([]images.Image{alpine}, nil).Times(2)
+			cdClient.EXPECT().SearchImage(gomock.Any(), "busybox").Return([]images.Image{busybox}, nil)
+			ncClient.EXPECT().ExportImage(gomock.Any(), []string{alpine.Name, busybox.Name}, platform, gomock.Any()).
+				Return(nil)
+
+			var buf bytes.Buffer
+			err := service.Export(ctx, []string{"alpine", "busybox", "alpine"}, platform, &buf)
+			Expect(err).Should(BeNil())
+			Expect(events()).Should(Equal([]*eventtype.Event{
+				getImageEvent(saveEventAction, "", "alpine:latest"),
+				getImageEvent(saveEventAction, "", "busybox:latest"),
+			}))
+		})
+		It("should not export anything if one of the images is not found", func() {
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return([]images.Image{{Name: name}}, nil)
+			cdClient.EXPECT().SearchImage(gomock.Any(), "missing").Return([]images.Image{}, nil)
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
+
+			var buf bytes.Buffer
+			err := service.Export(ctx, []string{name, "missing"}, nil, &buf)
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+			Expect(events()).Should(BeEmpty())
+		})
 	})
 })
@@ -0,0 +1,92 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/image-spec/identity"
+
+	"github.com/runfinch/finch-daemon/api/types"
+)
+
+// historyMissingID is the ID docker reports for the steps of the history which are not images themselves.
+const historyMissingID = "<missing>"
+
+// History returns the steps of the history of the image, from the most recent one,
+// with the size of the layer created by each step.
+func (s *service) History(ctx context.Context, name string) ([]types.ImageHistoryItem, error) {
+	img, err := s.getImage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.nctlImageSvc.GetImageConfig(ctx, *img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config of image %s: %w", name, err)
+	}
+	// the layers of an image which is not unpacked have no known size.
+	layerSizes, err := s.nctlImageSvc.GetImageLayerSizes(ctx, *img)
+	if err != nil && !cerrdefs.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the layer sizes of image %s: %w", name, err)
+	}
+	// ChainIDs computes the chain IDs in place, so don't pass the diff IDs of the config.
+	chainIDs := identity.ChainIDs(slices.Clone(config.RootFS.DiffIDs))
+
+	history := make([]types.ImageHistoryItem, 0, len(config.History))
+	layer := 0
+	for _, h := range config.History {
+		var size int64
+		// steps which did not create a layer, e.g. ENV, have no diff ID in the rootfs.
+		if !h.EmptyLayer {
+			if layer < len(chainIDs) {
+				size = layerSizes[chainIDs[layer].String()]
+			}
+			layer++
+		}
+		var created int64
+		if h.Created != nil {
+			created = h.Created.Unix()
+		}
+		history = append([]types.ImageHistoryItem{{
+			ID:        historyMissingID,
+			Created:   created,
+			CreatedBy: h.CreatedBy,
+			Size:      size,
+			Comment:   h.Comment,
+		}}, history...)
+	}
+
+	if len(history) > 0 {
+		tags, err := s.getTags(ctx, img.Target.Digest.String())
+		if err != nil {
+			return nil, err
+		}
+		history[0].ID = img.Target.Digest.String()
+		history[0].Tags = tags
+	}
+	return history, nil
+}
+
+// getTags returns the familiar names of the images with the target digest.
+func (s *service) getTags(ctx context.Context, digest string) ([]string, error) {
+	imgs, err := s.client.ImageService().List(ctx, fmt.Sprintf("target.digest==%s", digest))
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0, len(imgs))
+	for _, img := range imgs {
+		named, err := reference.ParseNormalizedNamed(img.Name)
+		if err != nil {
+			continue
+		}
+		if _, ok := named.(reference.Tagged); ok {
+			tags = append(tags, reference.FamiliarString(named))
+		}
+	}
+	return tags, nil
+}
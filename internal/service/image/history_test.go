@@ -0,0 +1,126 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/images"
+	cerrdefs "github.com/containerd/errdefs"
+	"go.uber.org/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/api/handlers/image"
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+// Unit tests related to image history API.
+var _ = Describe("Image History API", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		cdClient *mocks_backend.MockContainerdClient
+		ncClient *mocks_backend.MockNerdctlImageSvc
+		store    *mocks_image.MockStore
+		name     string
+		img      images.Image
+		created  time.Time
+		diffIDs  []digest.Digest
+		config   ocispec.Image
+		service  image.Service
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		// initialize mocks
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
+		store = mocks_image.NewMockStore(mockCtrl)
+		name = "test-image"
+		img = images.Image{
+			Name:   "docker.io/library/test-image:latest",
+			Target: ocispec.Descriptor{Digest: "sha256:123"},
+		}
+		created = time.Unix(1700000000, 0)
+		diffIDs = []digest.Digest{digest.FromString("layer1"), digest.FromString("layer2")}
+		config = ocispec.Image{
+			RootFS: ocispec.RootFS{Type: "layers", DiffIDs: diffIDs},
+			History: []ocispec.History{
+				{Created: &created, CreatedBy: "ADD rootfs.tar /"},
+				{Created: &created, CreatedBy: "ENV FOO=bar", EmptyLayer: true},
+				{Created: &created, CreatedBy: "RUN touch /foo", Comment: "buildkit.dockerfile.v0"},
+				{CreatedBy: "CMD [\"sh\"]", EmptyLayer: true},
+			},
+		}
+		service = NewService(cdClient, ncClient, logger)
+	})
+	Context("service", func() {
+		It("should return the history of the image from the most recent step", func() {
+			chainIDs := identity.ChainIDs(slices.Clone(diffIDs))
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageConfig(gomock.Any(), img).Return(&config, nil)
+			ncClient.EXPECT().GetImageLayerSizes(gomock.Any(), img).Return(map[string]int64{
+				chainIDs[0].String(): 100,
+				chainIDs[1].String(): 20,
+			}, nil)
+			cdClient.EXPECT().ImageService().Return(store)
+			store.EXPECT().List(gomock.Any(), "target.digest==sha256:123").Return([]images.Image{
+				img,
+				{Name: "docker.io/library/test-image:1", Target: img.Target},
+			}, nil)
+
+			history, err := service.History(ctx, name)
+			Expect(err).Should(BeNil())
+			Expect(history).Should(Equal([]types.ImageHistoryItem{
+				{ID: "sha256:123", CreatedBy: "CMD [\"sh\"]", Tags: []string{"test-image:latest", "test-image:1"}},
+				{ID: "<missing>", Created: created.Unix(), CreatedBy: "RUN touch /foo", Size: 20, Comment: "buildkit.dockerfile.v0"},
+				{ID: "<missing>", Created: created.Unix(), CreatedBy: "ENV FOO=bar"},
+				{ID: "<missing>", Created: created.Unix(), CreatedBy: "ADD rootfs.tar /", Size: 100},
+			}))
+		})
+		It("should return zero sizes if the image is not unpacked", func() {
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageConfig(gomock.Any(), img).Return(&config, nil)
+			ncClient.EXPECT().GetImageLayerSizes(gomock.Any(), img).Return(nil, cerrdefs.ErrNotFound)
+			cdClient.EXPECT().ImageService().Return(store)
+			store.EXPECT().List(gomock.Any(), gomock.Any()).Return([]images.Image{img}, nil)
+
+			history, err := service.History(ctx, name)
+			Expect(err).Should(BeNil())
+			Expect(history).Should(HaveLen(4))
+			for _, h := range history {
+				Expect(h.Size).Should(BeZero())
+			}
+		})
+		It("should return NotFound error if image was not found", func() {
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return([]images.Image{}, nil)
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
+
+			history, err := service.History(ctx, name)
+			Expect(history).Should(BeNil())
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+		})
+		It("should return an error if the image config can't be read", func() {
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageConfig(gomock.Any(), img).Return(nil, errors.New("config error"))
+
+			history, err := service.History(ctx, name)
+			Expect(history).Should(BeNil())
+			Expect(err).Should(MatchError(ContainSubstring("config error")))
+		})
+	})
+})
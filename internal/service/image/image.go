@@ -6,8 +6,13 @@ package image
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/labels"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/runfinch/finch-daemon/api/handlers/image"
 	"github.com/runfinch/finch-daemon/internal/backend"
@@ -59,6 +64,64 @@ func (s *service) getImage(ctx context.Context, name string) (*images.Image, err
 	return &images[0], nil
 }
 
+// repoTagsAndDigests returns the familiar tagged references and the repository digests of the images with the target.
+// Like docker, an image only has a digest in the repositories of the registries it was pulled from or pushed to.
+func repoTagsAndDigests(imgs []images.Image, target digest.Digest, repositories map[string]bool) (repoTags, repoDigests []string) {
+	repoTags, repoDigests = []string{}, []string{}
+	seenDigests := make(map[string]bool)
+	for _, img := range imgs {
+		named, ok := imageReference(img, target)
+		if !ok {
+			continue
+		}
+		if _, ok := named.(reference.Tagged); ok {
+			repoTags = append(repoTags, reference.FamiliarString(named))
+		}
+		if !repositories[named.Name()] {
+			continue
+		}
+		repoDigest := fmt.Sprintf("%s@%s", reference.FamiliarName(named), target)
+		if !seenDigests[repoDigest] {
+			seenDigests[repoDigest] = true
+			repoDigests = append(repoDigests, repoDigest)
+		}
+	}
+	return repoTags, repoDigests
+}
+
+// registryRepositories returns the repositories of the registries the content with the digest was pulled from
+// or pushed to, e.g. "docker.io/library/alpine", from the distribution source labels of the content.
+func (s *service) registryRepositories(ctx context.Context, dgst digest.Digest) (map[string]bool, error) {
+	contentLabels, err := s.client.GetContentLabels(ctx, dgst)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	repositories := make(map[string]bool)
+	for key, value := range contentLabels {
+		host, ok := strings.CutPrefix(key, labels.LabelDistributionSource+".")
+		if !ok {
+			continue
+		}
+		for _, path := range strings.Split(value, ",") {
+			repositories[host+"/"+path] = true
+		}
+	}
+	return repositories, nil
+}
+
+// imageReference returns the reference an image with the target is named with, if it is named with one.
+func imageReference(img images.Image, target digest.Digest) (reference.Named, bool) {
+	// dangling images are named with their digest, or names which are not references, e.g. ":" for untagged images.
+	if img.Name == target.String() {
+		return nil, false
+	}
+	named, err := reference.ParseNormalizedNamed(img.Name)
+	return named, err == nil
+}
+
 func NewService(client backend.ContainerdClient, nerdctlImageSvc backend.NerdctlImageSvc, logger flog.Logger) image.Service {
 	return &service{
 		client:       client,
@@ -71,4 +134,6 @@ const (
 	defaultTag      = "latest"
 	tagDigestPrefix = "sha256:"
 	eventType       = "image"
+	// overlayfsSnapshotter is the snapshotter whose snapshot directories are reported by inspect.
+	overlayfsSnapshotter = "overlayfs"
 )
@@ -162,11 +162,14 @@ func (m *mockGetAuthCreds) GetAuthCreds(domain string, ac dockertypes.AuthConfig
 	m.ctrl.Call(m, "GetAuthCreds", domain, ac)
 }
 
-// dummy remotes resolver.
-type mockResolver struct{}
+// dummy remotes resolver, which resolves any reference to the root descriptor or fails with the error.
+type mockResolver struct {
+	root ocispec.Descriptor
+	err  error
+}
 
-func (m *mockResolver) Resolve(context.Context, string) (string, ocispec.Descriptor, error) {
-	return "", ocispec.Descriptor{}, nil
+func (m *mockResolver) Resolve(_ context.Context, ref string) (string, ocispec.Descriptor, error) {
+	return ref, m.root, m.err
 }
 
 func (m *mockResolver) Fetcher(context.Context, string) (remotes.Fetcher, error) {
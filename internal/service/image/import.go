@@ -0,0 +1,282 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+// importSourceStdin is the import source which reads the rootfs tarball from the request body.
+const importSourceStdin = "-"
+
+// Import creates a single-layer image from a rootfs tarball, with the config resulting from the changes,
+// and writes the ID of the image to outStream.
+func (s *service) Import(ctx context.Context, options types.ImageImportOptions, outStream io.Writer) error {
+	var config ocispec.ImageConfig
+	if err := applyChanges(&config, options.Changes); err != nil {
+		return errdefs.NewInvalidFormat(err)
+	}
+
+	var ref string
+	if options.Repo != "" {
+		var err error
+		ref, _, err = s.client.ParseDockerRef(toImageRef(options.Repo, options.Tag))
+		if err != nil {
+			return errdefs.NewInvalidFormat(err)
+		}
+	}
+
+	// get host platform's default spec if unspecified
+	var platform ocispec.Platform
+	if options.Platform == "" {
+		platform = s.client.DefaultPlatformSpec()
+	} else {
+		var err error
+		platform, err = s.client.ParsePlatform(options.Platform)
+		if err != nil {
+			return errdefs.NewInvalidFormat(fmt.Errorf("invalid platform %s: %s", options.Platform, err))
+		}
+	}
+
+	rootfs, err := openImportSource(options)
+	if err != nil {
+		return err
+	}
+	defer rootfs.Close()
+
+	img, err := s.nctlImageSvc.ImportImage(ctx, rootfs, ref, platform, config, options.Message)
+	if err != nil {
+		return err
+	}
+	name := img.Target.Digest.String()
+	if ref != "" {
+		name = familiarName(ref)
+	}
+	s.publishImageEvent(ctx, importEventAction, img.Target.Digest, name)
+	_, err = fmt.Fprintln(outStream, img.Target.Digest.String())
+	return err
+}
+
+// openImportSource opens the rootfs tarball, which is either the request body or a local file.
+func openImportSource(options types.ImageImportOptions) (io.ReadCloser, error) {
+	if options.Source == importSourceStdin {
+		return io.NopCloser(options.Stdin), nil
+	}
+	u, err := url.Parse(options.Source)
+	if err != nil || u.Scheme != "file" {
+		return nil, errdefs.NewInvalidFormat(fmt.Errorf("unsupported import source %q, expected \"-\" or a file URL", options.Source))
+	}
+	f, err := os.Open(u.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errdefs.NewNotFound(fmt.Errorf("import source not found: %s", u.Path))
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// applyChanges applies Dockerfile instructions, as given to `docker import --change`, to the image config.
+func applyChanges(config *ocispec.ImageConfig, changes []string) error {
+	for _, change := range changes {
+		change = strings.TrimSpace(change)
+		if change == "" {
+			continue
+		}
+		instruction, args, _ := strings.Cut(change, " ")
+		args = strings.TrimSpace(args)
+		if args == "" {
+			return fmt.Errorf("%s requires at least one argument", strings.ToUpper(instruction))
+		}
+
+		switch strings.ToUpper(instruction) {
+		case "CMD":
+			config.Cmd = parseCommand(args)
+		case "ENTRYPOINT":
+			config.Entrypoint = parseCommand(args)
+		case "ENV":
+			env, err := parseKeyValues(args, true)
+			if err != nil {
+				return fmt.Errorf("invalid ENV %q: %w", args, err)
+			}
+			for _, kv := range env {
+				config.Env = setEnv(config.Env, kv[0], kv[1])
+			}
+		case "LABEL":
+			labels, err := parseKeyValues(args, false)
+			if err != nil {
+				return fmt.Errorf("invalid LABEL %q: %w", args, err)
+			}
+			if config.Labels == nil {
+				config.Labels = make(map[string]string)
+			}
+			for _, kv := range labels {
+				config.Labels[kv[0]] = kv[1]
+			}
+		case "WORKDIR":
+			workdir := args
+			if !path.IsAbs(workdir) {
+				workdir = path.Join("/", config.WorkingDir, workdir)
+			}
+			config.WorkingDir = workdir
+		case "EXPOSE":
+			ports, err := splitWords(args)
+			if err != nil {
+				return fmt.Errorf("invalid EXPOSE %q: %w", args, err)
+			}
+			if config.ExposedPorts == nil {
+				config.ExposedPorts = make(map[string]struct{})
+			}
+			for _, port := range ports {
+				port, err := parseExposedPort(port)
+				if err != nil {
+					return err
+				}
+				config.ExposedPorts[port] = struct{}{}
+			}
+		case "USER":
+			config.User = args
+		case "VOLUME":
+			var volumes []string
+			if err := json.Unmarshal([]byte(args), &volumes); err != nil {
+				if volumes, err = splitWords(args); err != nil {
+					return fmt.Errorf("invalid VOLUME %q: %w", args, err)
+				}
+			}
+			if config.Volumes == nil {
+				config.Volumes = make(map[string]struct{})
+			}
+			for _, volume := range volumes {
+				config.Volumes[volume] = struct{}{}
+			}
+		default:
+			return fmt.Errorf("%s is not a valid change command", instruction)
+		}
+	}
+	return nil
+}
+
+// parseCommand parses the exec form (a JSON array) or the shell form of CMD and ENTRYPOINT.
+func parseCommand(args string) []string {
+	var command []string
+	if err := json.Unmarshal([]byte(args), &command); err == nil {
+		return command
+	}
+	return []string{"/bin/sh", "-c", args}
+}
+
+// parseKeyValues parses the key=value pairs of ENV and LABEL. With legacy set, the "key value" form of ENV is
+// also accepted.
+func parseKeyValues(args string, legacy bool) ([][2]string, error) {
+	if key, value, found := strings.Cut(args, " "); legacy && found && !strings.Contains(key, "=") {
+		return [][2]string{{key, strings.TrimSpace(value)}}, nil
+	}
+	words, err := splitWords(args)
+	if err != nil {
+		return nil, err
+	}
+	kvs := make([][2]string, 0, len(words))
+	for _, word := range words {
+		key, value, found := strings.Cut(word, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("%q is not of the form key=value", word)
+		}
+		kvs = append(kvs, [2]string{key, value})
+	}
+	return kvs, nil
+}
+
+// splitWords splits the arguments on whitespace, honoring quotes and backslash escapes like a shell.
+func splitWords(args string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range args {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// setEnv sets the variable in the environment, replacing its previous value.
+func setEnv(env []string, key, value string) []string {
+	for i, kv := range env {
+		if k, _, _ := strings.Cut(kv, "="); k == key {
+			env[i] = key + "=" + value
+			return env
+		}
+	}
+	return append(env, key+"="+value)
+}
+
+// parseExposedPort validates a port of EXPOSE, which is a port or a port range with an optional protocol,
+// and returns it in the port/protocol format of the image config.
+func parseExposedPort(port string) (string, error) {
+	ports, proto, found := strings.Cut(port, "/")
+	if !found {
+		proto = "tcp"
+	}
+	switch strings.ToLower(proto) {
+	case "tcp", "udp", "sctp":
+	default:
+		return "", fmt.Errorf("invalid proto %q in EXPOSE %s", proto, port)
+	}
+	bounds := []string{ports}
+	if start, end, isRange := strings.Cut(ports, "-"); isRange {
+		bounds = []string{start, end}
+	}
+	for _, p := range bounds {
+		if n, err := strconv.ParseUint(p, 10, 16); err != nil || n == 0 {
+			return "", fmt.Errorf("invalid port %q in EXPOSE %s", p, port)
+		}
+	}
+	return ports + "/" + strings.ToLower(proto), nil
+}
@@ -0,0 +1,146 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/images"
+	"go.uber.org/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	eventtype "github.com/runfinch/finch-daemon/api/events"
+	"github.com/runfinch/finch-daemon/api/handlers/image"
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+// Unit tests related to image import API.
+var _ = Describe("Image Import API", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		cdClient *mocks_backend.MockContainerdClient
+		events   func() []*eventtype.Event
+		ncClient *mocks_backend.MockNerdctlImageSvc
+		platform ocispec.Platform
+		img      images.Image
+		out      *bytes.Buffer
+		service  image.Service
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		// initialize mocks
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		events = recordEvents(cdClient)
+		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
+		platform = ocispec.Platform{OS: "linux", Architecture: "amd64"}
+		img = images.Image{
+			Name:   "docker.io/library/test-image:test-tag",
+			Target: ocispec.Descriptor{Digest: "sha256:123"},
+		}
+		out = &bytes.Buffer{}
+		service = NewService(cdClient, ncClient, logger)
+	})
+	Context("service", func() {
+		It("should import the rootfs from stdin with the changes applied to the config", func() {
+			cdClient.EXPECT().ParseDockerRef("test-image:test-tag").Return("docker.io/library/test-image:test-tag", "docker.io", nil)
+			cdClient.EXPECT().DefaultPlatformSpec().Return(platform)
+			ncClient.EXPECT().ImportImage(gomock.Any(), gomock.Any(), img.Name, platform, ocispec.ImageConfig{
+				Cmd:          []string{"/bin/sh", "-c", "echo hello"},
+				Entrypoint:   []string{"/entrypoint.sh"},
+				Env:          []string{"FOO=bar baz", "QUX=1"},
+				WorkingDir:   "/app/src",
+				ExposedPorts: map[string]struct{}{"80/tcp": {}, "53/udp": {}, "8000-8010/tcp": {}},
+				Labels:       map[string]string{"a": "b", "c": "d e"},
+				User:         "nobody",
+				Volumes:      map[string]struct{}{"/data": {}, "/logs": {}},
+			}, "imported").DoAndReturn(
+				func(_ context.Context, rootfs io.Reader, _ string, _ ocispec.Platform, _ ocispec.ImageConfig, _ string) (*images.Image, error) {
+					Expect(io.ReadAll(rootfs)).Should(Equal([]byte("rootfs")))
+					return &img, nil
+				})
+
+			err := service.Import(ctx, types.ImageImportOptions{
+				Source:  "-",
+				Stdin:   strings.NewReader("rootfs"),
+				Repo:    "test-image",
+				Tag:     "test-tag",
+				Message: "imported",
+				Changes: []string{
+					"CMD echo hello",
+					`ENTRYPOINT ["/entrypoint.sh"]`,
+					"ENV FOO bar baz",
+					"env QUX=1",
+					"WORKDIR /app",
+					"WORKDIR src",
+					"EXPOSE 80 53/udp 8000-8010",
+					`LABEL a=b c="d e"`,
+					"USER nobody",
+					`VOLUME ["/data"]`,
+					"VOLUME /logs",
+				},
+			}, out)
+			Expect(err).Should(BeNil())
+			Expect(out.String()).Should(Equal("sha256:123\n"))
+			Expect(events()).Should(Equal([]*eventtype.Event{getImageEvent(importEventAction, img.Target.Digest, "test-image:test-tag")}))
+		})
+		It("should import the rootfs from a local file without a name", func() {
+			rootfsPath := filepath.Join(GinkgoT().TempDir(), "rootfs.tar")
+			Expect(os.WriteFile(rootfsPath, []byte("rootfs"), 0o600)).Should(Succeed())
+			cdClient.EXPECT().ParsePlatform("linux/amd64").Return(platform, nil)
+			ncClient.EXPECT().ImportImage(gomock.Any(), gomock.Any(), "", platform, ocispec.ImageConfig{}, "").DoAndReturn(
+				func(_ context.Context, rootfs io.Reader, _ string, _ ocispec.Platform, _ ocispec.ImageConfig, _ string) (*images.Image, error) {
+					Expect(io.ReadAll(rootfs)).Should(Equal([]byte("rootfs")))
+					return &img, nil
+				})
+
+			err := service.Import(ctx, types.ImageImportOptions{
+				Source:   "file://" + rootfsPath,
+				Platform: "linux/amd64",
+			}, out)
+			Expect(err).Should(BeNil())
+			// images imported without a name are named with their ID
+			Expect(events()).Should(Equal([]*eventtype.Event{getImageEvent(importEventAction, img.Target.Digest, img.Target.Digest.String())}))
+		})
+		It("should return a NotFound error if the local file does not exist", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(platform)
+
+			err := service.Import(ctx, types.ImageImportOptions{Source: "file:///does/not/exist.tar"}, out)
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+		})
+		It("should return an InvalidFormat error for unsupported sources", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(platform)
+
+			err := service.Import(ctx, types.ImageImportOptions{Source: "ftp://example.com/rootfs.tar"}, out)
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+		})
+		It("should return an InvalidFormat error for invalid changes", func() {
+			for _, change := range []string{"FROM scratch", "CMD", "EXPOSE 80/foo", "EXPOSE 0", "LABEL foo", `ENV FOO="bar`} {
+				err := service.Import(ctx, types.ImageImportOptions{Source: "-", Changes: []string{change}}, out)
+				Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue(), change)
+			}
+		})
+		It("should pass through errors from importing the image", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(platform)
+			ncClient.EXPECT().ImportImage(gomock.Any(), gomock.Any(), "", platform, gomock.Any(), "").Return(nil, errors.New("import error"))
+
+			err := service.Import(ctx, types.ImageImportOptions{Source: "-", Stdin: strings.NewReader("rootfs")}, out)
+			Expect(err).Should(MatchError("import error"))
+		})
+	})
+})
@@ -5,22 +5,137 @@ package image
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 
-	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
-func (s *service) Inspect(ctx context.Context, name string) (*dockercompat.Image, error) {
+// Inspect inspects the image for the platform, or for the default platform if none is given.
+func (s *service) Inspect(ctx context.Context, name string, platform *ocispec.Platform) (*types.ImageInspect, error) {
 	img, err := s.getImage(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
-	image, err := s.nctlImageSvc.InspectImage(ctx, *img)
+	inspect, err := s.nctlImageSvc.InspectImage(ctx, *img, platform)
+	if err != nil {
+		if platform != nil && cerrdefs.IsNotFound(err) {
+			return nil, errdefs.NewNotFound(fmt.Errorf("image with reference %s was found but does not provide the specified platform (%s)",
+				name, platforms.FormatAll(*platform)))
+		}
+		return nil, err
+	}
+
+	records, err := s.client.ImageService().List(ctx, fmt.Sprintf("target.digest==%s", img.Target.Digest))
+	if err != nil {
+		return nil, err
+	}
+	snapshotters, err := s.nctlImageSvc.GetImageSnapshotters(ctx, *img, platform)
+	if err != nil && !cerrdefs.IsNotFound(err) {
+		return nil, err
+	}
+	// the storage driver is the configured snapshotter, unless the image is only unpacked into others.
+	graphDriver := s.nctlImageSvc.Snapshotter()
+	if len(snapshotters) > 0 && !slices.Contains(snapshotters, graphDriver) {
+		graphDriver = snapshotters[0]
+	}
+	image := &types.ImageInspect{
+		// the ID is the image digest (nerdctl compatible) instead of docker-compatible id
+		ID:            img.Target.Digest.String(),
+		Parent:        inspect.Parent,
+		Comment:       inspect.Comment,
+		Created:       inspect.Created,
+		DockerVersion: inspect.DockerVersion,
+		Author:        inspect.Author,
+		Config:        inspect.Config,
+		Architecture:  inspect.Architecture,
+		Variant:       inspect.Variant,
+		Os:            inspect.Os,
+		Size:          inspect.Size,
+		VirtualSize:   inspect.VirtualSize,
+		GraphDriver:   types.GraphDriverData{Name: graphDriver},
+		RootFS:        inspect.RootFS,
+		Snapshotters:  snapshotters,
+	}
+	if graphDriver == overlayfsSnapshotter && slices.Contains(snapshotters, graphDriver) {
+		image.GraphDriver.Data = s.overlayDirs(ctx, inspect.RootFS.Layers)
+	}
+	repositories, err := s.registryRepositories(ctx, img.Target.Digest)
 	if err != nil {
 		return nil, err
 	}
+	image.RepoTags, image.RepoDigests = repoTagsAndDigests(records, img.Target.Digest, repositories)
 
-	// reset image Id so that it matches image digest (nerdctl compatible) instead of docker-compatible id
-	image.ID = img.Target.Digest.String()
+	// the image was last tagged when its most recent tagged reference was updated.
+	for _, record := range records {
+		named, ok := imageReference(record, img.Target.Digest)
+		if !ok {
+			continue
+		}
+		if _, ok := named.(reference.Tagged); ok && record.UpdatedAt.After(image.Metadata.LastTagTime) {
+			image.Metadata.LastTagTime = record.UpdatedAt
+		}
+	}
 	return image, nil
 }
+
+// overlayDirs returns the directories of the overlayfs snapshots of the image with the layers, in the format of the
+// overlay2 storage driver of docker: the directory of the top layer is the UpperDir, and the directories of the layers
+// below it are the LowerDir. The images are not mounted, so there is no MergedDir.
+func (s *service) overlayDirs(ctx context.Context, layers []string) map[string]string {
+	if len(layers) == 0 {
+		return nil
+	}
+	diffIDs := make([]digest.Digest, 0, len(layers))
+	for _, layer := range layers {
+		diffID, err := digest.Parse(layer)
+		if err != nil {
+			return nil
+		}
+		diffIDs = append(diffIDs, diffID)
+	}
+	// the mounts of a committed snapshot are only available through a view of it.
+	mounts, err := s.client.ViewSnapshotMounts(ctx, overlayfsSnapshotter, identity.ChainID(diffIDs).String())
+	if err != nil {
+		s.logger.Warnf("failed to get the snapshot mounts of the image layers: %s", err)
+		return nil
+	}
+	if len(mounts) != 1 {
+		return nil
+	}
+	// a view of a single layer is a bind mount of its directory, otherwise the layers are the lower directories
+	// of an overlay mount, from the top one.
+	var dirs []string
+	switch mounts[0].Type {
+	case "bind":
+		dirs = []string{mounts[0].Source}
+	case "overlay":
+		for _, opt := range mounts[0].Options {
+			if lower, ok := strings.CutPrefix(opt, "lowerdir="); ok {
+				dirs = strings.Split(lower, ":")
+			}
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+	data := map[string]string{
+		"UpperDir": dirs[0],
+		"WorkDir":  filepath.Join(filepath.Dir(dirs[0]), "work"),
+	}
+	if len(dirs) > 1 {
+		data["LowerDir"] = strings.Join(dirs[1:], ":")
+	}
+	return data
+}
@@ -6,15 +6,24 @@ package image
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/mount"
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/runfinch/finch-daemon/api/handlers/image"
+	"github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
@@ -27,7 +36,9 @@ var _ = Describe("Image Inspect API ", func() {
 		logger   *mocks_logger.Logger
 		cdClient *mocks_backend.MockContainerdClient
 		ncClient *mocks_backend.MockNerdctlImageSvc
+		store    *mocks_image.MockStore
 		name     string
+		target   digest.Digest
 		img      images.Image
 		inspect  dockercompat.Image
 		service  image.Service
@@ -39,30 +50,106 @@ var _ = Describe("Image Inspect API ", func() {
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
 		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
+		store = mocks_image.NewMockStore(mockCtrl)
 		name = "test-image"
-		img = images.Image{Name: name}
+		target = digest.FromString("test-image")
+		img = images.Image{
+			Name:   "docker.io/library/test-image:latest",
+			Target: ocispec.Descriptor{Digest: target},
+		}
 		inspect = dockercompat.Image{
-			ID:          name,
-			RepoTags:    []string{"test-image:latest"},
-			RepoDigests: []string{"test-image@test-digest"},
-			Size:        100,
+			ID:           "sha256:config",
+			RepoTags:     []string{"docker.io/library/test-image:latest"},
+			RepoDigests:  []string{"docker.io/library/test-image@" + target.String()},
+			Parent:       "sha256:parent",
+			Comment:      "test-comment",
+			Author:       "test-author",
+			Architecture: "amd64",
+			Os:           "linux",
+			Size:         100,
+			RootFS: dockercompat.RootFS{
+				Type:   "layers",
+				Layers: []string{digest.FromString("layer").String()},
+			},
 		}
 
+		cdClient.EXPECT().ImageService().Return(store).AnyTimes()
+		ncClient.EXPECT().Snapshotter().Return("overlayfs").AnyTimes()
+		cdClient.EXPECT().GetContentLabels(gomock.Any(), target).Return(map[string]string{
+			"containerd.io/distribution.source.docker.io": "library/test-image",
+		}, nil).AnyTimes()
 		service = NewService(cdClient, ncClient, logger)
 	})
 	Context("service", func() {
-		It("should return the inspect object upon success", func() {
-			// search image method returns one image
+		It("should return the inspect object with all the references of the image upon success", func() {
+			tagTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			other := images.Image{Name: "docker.io/library/test-image:1", Target: img.Target, UpdatedAt: tagTime}
+			dangling := images.Image{Name: target.String(), Target: img.Target, UpdatedAt: tagTime.Add(time.Hour)}
 			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
 				[]images.Image{img}, nil)
-
-			ncClient.EXPECT().InspectImage(gomock.Any(), img).Return(
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, nil).Return(
 				&inspect, nil)
+			store.EXPECT().List(gomock.Any(), "target.digest=="+target.String()).
+				Return([]images.Image{img, other, dangling}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), img, nil).Return([]string{"overlayfs", "stargz"}, nil)
+			// the chain ID of a single layer is its diff ID.
+			cdClient.EXPECT().ViewSnapshotMounts(gomock.Any(), "overlayfs", inspect.RootFS.Layers[0]).Return([]mount.Mount{{
+				Type:    "bind",
+				Source:  "/var/lib/containerd/snapshots/1/fs",
+				Options: []string{"ro", "rbind"},
+			}}, nil)
 
 			// service should return inspect object
-			result, err := service.Inspect(ctx, name)
-			Expect(*result).Should(Equal(inspect))
+			result, err := service.Inspect(ctx, name, nil)
+			Expect(err).Should(BeNil())
+			Expect(*result).Should(Equal(types.ImageInspect{
+				ID:           target.String(),
+				RepoTags:     []string{"test-image:latest", "test-image:1"},
+				RepoDigests:  []string{"test-image@" + target.String()},
+				Parent:       inspect.Parent,
+				Comment:      inspect.Comment,
+				Author:       inspect.Author,
+				Architecture: inspect.Architecture,
+				Os:           inspect.Os,
+				Size:         inspect.Size,
+				GraphDriver: types.GraphDriverData{Name: "overlayfs", Data: map[string]string{
+					"UpperDir": "/var/lib/containerd/snapshots/1/fs",
+					"WorkDir":  "/var/lib/containerd/snapshots/1/work",
+				}},
+				RootFS:       inspect.RootFS,
+				Metadata:     dockercompat.ImageMetadata{LastTagTime: tagTime},
+				Snapshotters: []string{"overlayfs", "stargz"},
+			}))
+		})
+		It("should inspect the image for the platform", func() {
+			platform := &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+			inspect.Architecture = "arm64"
+			inspect.Variant = "v8"
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
+				[]images.Image{img}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, platform).Return(
+				&inspect, nil)
+			store.EXPECT().List(gomock.Any(), gomock.Any()).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), img, platform).Return(nil, cerrdefs.ErrNotFound)
+
+			// service should return the inspect object of the platform
+			result, err := service.Inspect(ctx, name, platform)
 			Expect(err).Should(BeNil())
+			Expect(result.Architecture).Should(Equal("arm64"))
+			Expect(result.Variant).Should(Equal("v8"))
+		})
+		It("should return NotFound error if the image does not provide the platform", func() {
+			platform := &ocispec.Platform{OS: "linux", Architecture: "s390x"}
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
+				[]images.Image{img}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, platform).Return(
+				nil, fmt.Errorf("no match for platform in manifest: %w", cerrdefs.ErrNotFound))
+
+			// service should return a NotFound error
+			result, err := service.Inspect(ctx, name, platform)
+			Expect(result).Should(BeNil())
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+			Expect(err.Error()).Should(ContainSubstring("does not provide the specified platform (linux/s390x)"))
 		})
 		It("should return NotFound error if image was not found", func() {
 			// search image method returns no image
@@ -71,7 +158,7 @@ var _ = Describe("Image Inspect API ", func() {
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any())
 
 			// service should return a NotFound error
-			result, err := service.Inspect(ctx, name)
+			result, err := service.Inspect(ctx, name, nil)
 			Expect(result).Should(BeNil())
 			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 		})
@@ -79,14 +166,68 @@ var _ = Describe("Image Inspect API ", func() {
 			// search image method returns multiple images
 			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
 				[]images.Image{img, img}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, nil).Return(
+				&inspect, nil)
+			store.EXPECT().List(gomock.Any(), gomock.Any()).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), img, nil).Return(nil, nil)
 
-			ncClient.EXPECT().InspectImage(gomock.Any(), img).Return(
+			// service should return the inspect object
+			result, err := service.Inspect(ctx, name, nil)
+			Expect(err).Should(BeNil())
+			Expect(result.ID).Should(Equal(target.String()))
+		})
+		It("should report the snapshotter the image is unpacked into as its storage driver", func() {
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
+				[]images.Image{img}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, nil).Return(
 				&inspect, nil)
+			store.EXPECT().List(gomock.Any(), gomock.Any()).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), img, nil).Return([]string{"soci"}, nil)
 
-			// service should return an error
-			result, err := service.Inspect(ctx, name)
+			// the image is only unpacked into soci, not into the configured overlayfs
+			result, err := service.Inspect(ctx, name, nil)
 			Expect(err).Should(BeNil())
-			Expect(*result).Should(Equal(inspect))
+			Expect(result.GraphDriver.Name).Should(Equal("soci"))
+			Expect(result.Snapshotters).Should(Equal([]string{"soci"}))
+		})
+		It("should report the overlayfs directories of the layers of the image", func() {
+			diffIDs := []digest.Digest{digest.FromString("layer1"), digest.FromString("layer2"), digest.FromString("layer3")}
+			inspect.RootFS.Layers = []string{diffIDs[0].String(), diffIDs[1].String(), diffIDs[2].String()}
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
+				[]images.Image{img}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, nil).Return(
+				&inspect, nil)
+			store.EXPECT().List(gomock.Any(), gomock.Any()).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), img, nil).Return([]string{"overlayfs"}, nil)
+			cdClient.EXPECT().ViewSnapshotMounts(gomock.Any(), "overlayfs", identity.ChainID(diffIDs).String()).Return([]mount.Mount{{
+				Type:   "overlay",
+				Source: "overlay",
+				Options: []string{
+					"lowerdir=/var/lib/containerd/snapshots/3/fs:/var/lib/containerd/snapshots/2/fs:/var/lib/containerd/snapshots/1/fs",
+				},
+			}}, nil)
+
+			result, err := service.Inspect(ctx, name, nil)
+			Expect(err).Should(BeNil())
+			Expect(result.GraphDriver).Should(Equal(types.GraphDriverData{Name: "overlayfs", Data: map[string]string{
+				"LowerDir": "/var/lib/containerd/snapshots/2/fs:/var/lib/containerd/snapshots/1/fs",
+				"UpperDir": "/var/lib/containerd/snapshots/3/fs",
+				"WorkDir":  "/var/lib/containerd/snapshots/3/work",
+			}}))
+		})
+		It("should not report the overlayfs directories if the snapshot mounts are not available", func() {
+			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
+				[]images.Image{img}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, nil).Return(
+				&inspect, nil)
+			store.EXPECT().List(gomock.Any(), gomock.Any()).Return([]images.Image{img}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), img, nil).Return([]string{"overlayfs"}, nil)
+			cdClient.EXPECT().ViewSnapshotMounts(gomock.Any(), "overlayfs", gomock.Any()).Return(nil, cerrdefs.ErrNotFound)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
+
+			result, err := service.Inspect(ctx, name, nil)
+			Expect(err).Should(BeNil())
+			Expect(result.GraphDriver).Should(Equal(types.GraphDriverData{Name: "overlayfs"}))
 		})
 		It("should return an error if search image method failed", func() {
 			// search image method returns no image
@@ -95,7 +236,7 @@ var _ = Describe("Image Inspect API ", func() {
 			logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
 
 			// service should return an error
-			result, err := service.Inspect(ctx, name)
+			result, err := service.Inspect(ctx, name, nil)
 			Expect(result).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 		})
@@ -103,12 +244,11 @@ var _ = Describe("Image Inspect API ", func() {
 			// search image method returns one image
 			cdClient.EXPECT().SearchImage(gomock.Any(), name).Return(
 				[]images.Image{img}, nil)
-
-			ncClient.EXPECT().InspectImage(gomock.Any(), img).Return(
+			ncClient.EXPECT().InspectImage(gomock.Any(), img, nil).Return(
 				nil, errors.New("error message"))
 
 			// service should return an error
-			result, err := service.Inspect(ctx, name)
+			result, err := service.Inspect(ctx, name, nil)
 			Expect(result).Should(BeNil())
 			Expect(err).ShouldNot(BeNil())
 		})
@@ -6,42 +6,145 @@ package image
 import (
 	"context"
 	"fmt"
-	"log"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
-func (s *service) List(ctx context.Context) ([]types.ImageSummary, error) {
-	imgs, err := s.client.GetClient().ListImages(context.Background())
+func (s *service) List(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	filters, err := imgutil.ParseFilters(options.Filters)
+	if err != nil {
+		return nil, errdefs.NewInvalidFormat(err)
+	}
+	imgs, err := s.nctlImageSvc.ListImages(ctx, filters)
 	if err != nil {
 		return nil, fmt.Errorf("list: failed to list images: %w", err)
 	}
-	summaries := []types.ImageSummary{}
-	// TODO post-process to dedup and populate RepoTags/RepoDigests
-	// TODO walk image.Target() with the content store to get manifest digests?
+
+	// an image has one record per name, so group the records by target digest to get one summary per image.
+	var targets []digest.Digest
+	records := make(map[digest.Digest][]images.Image)
 	for _, img := range imgs {
-		log.Println(img)
-		if ok, err := img.IsUnpacked(ctx, "overlayfs"); !ok {
-			continue
-		} else if err != nil {
+		target := img.Target.Digest
+		if _, ok := records[target]; !ok {
+			targets = append(targets, target)
+		}
+		records[target] = append(records[target], img)
+	}
+
+	containerCounts, err := s.countContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to count the containers of images: %w", err)
+	}
+
+	summaries := []types.ImageSummary{}
+	var listed [][]images.Image
+	for _, target := range targets {
+		group := records[target]
+		// an image which does not provide the default platform is not unpacked.
+		snapshotters, err := s.nctlImageSvc.GetImageSnapshotters(ctx, group[0], nil)
+		if err != nil && !cerrdefs.IsNotFound(err) {
 			return nil, err
 		}
-		info, err := img.ContentStore().Info(ctx, img.Target().Digest)
+		if !options.All && len(snapshotters) == 0 {
+			continue
+		}
+		summary, err := s.getImageSummary(ctx, target, group, containerCounts)
 		if err != nil {
 			return nil, err
 		}
-		size, err := img.Size(ctx)
-		if err != nil {
+		summary.Snapshotters = snapshotters
+		summaries = append(summaries, summary)
+		listed = append(listed, group)
+	}
+
+	if options.SharedSize {
+		if err := s.setSharedSizes(ctx, summaries, listed); err != nil {
 			return nil, err
 		}
-		summaries = append(summaries, types.ImageSummary{
-			ID: string(img.Target().Digest),
-			RepoTags: []string{
-				img.Name(),
-			},
-			Created: info.CreatedAt.Unix(),
-			Size:    size,
-		})
 	}
 	return summaries, nil
 }
+
+// getImageSummary summarizes the records of the image with the target digest.
+func (s *service) getImageSummary(ctx context.Context, target digest.Digest, group []images.Image, containerCounts map[string]int64) (types.ImageSummary, error) {
+	inspect, err := s.nctlImageSvc.InspectImage(ctx, group[0], nil)
+	if err != nil {
+		return types.ImageSummary{}, err
+	}
+	summary := types.ImageSummary{
+		ID:          target.String(),
+		ParentID:    inspect.Parent,
+		Size:        inspect.Size,
+		SharedSize:  -1,
+		VirtualSize: inspect.Size,
+	}
+	if inspect.Config != nil {
+		summary.Labels = inspect.Config.Labels
+	}
+
+	created := group[0].CreatedAt
+	for _, img := range group {
+		if img.CreatedAt.Before(created) {
+			created = img.CreatedAt
+		}
+		summary.Containers += containerCounts[img.Name]
+	}
+	repositories, err := s.registryRepositories(ctx, target)
+	if err != nil {
+		return types.ImageSummary{}, err
+	}
+	summary.RepoTags, summary.RepoDigests = repoTagsAndDigests(group, target, repositories)
+	summary.Created = created.Unix()
+	return summary, nil
+}
+
+// countContainers returns the number of containers of each image name.
+func (s *service) countContainers(ctx context.Context) (map[string]int64, error) {
+	cons, err := s.client.GetContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64)
+	for _, con := range cons {
+		info, err := con.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			// the container may have been removed since it was listed.
+			continue
+		}
+		counts[info.Image]++
+	}
+	return counts, nil
+}
+
+// setSharedSizes sets the size of the layers each listed image shares with other listed images.
+func (s *service) setSharedSizes(ctx context.Context, summaries []types.ImageSummary, listed [][]images.Image) error {
+	layerSizes := make([]map[string]int64, len(listed))
+	layerImages := make(map[string]int)
+	for i, group := range listed {
+		// an image which is not unpacked has no layers to share.
+		sizes, err := s.nctlImageSvc.GetImageLayerSizes(ctx, group[0])
+		if err != nil && !cerrdefs.IsNotFound(err) {
+			return fmt.Errorf("list: failed to get the layer sizes of image %s: %w", group[0].Name, err)
+		}
+		layerSizes[i] = sizes
+		for chainID := range sizes {
+			layerImages[chainID]++
+		}
+	}
+	for i := range summaries {
+		summaries[i].SharedSize = 0
+		for chainID, size := range layerSizes[i] {
+			if layerImages[chainID] > 1 {
+				summaries[i].SharedSize += size
+			}
+		}
+	}
+	return nil
+}
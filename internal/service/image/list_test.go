@@ -0,0 +1,244 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/core/images"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"go.uber.org/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/api/handlers/image"
+	"github.com/runfinch/finch-daemon/api/types"
+	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_container"
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+	"github.com/runfinch/finch-daemon/pkg/errdefs"
+)
+
+// Unit tests related to image list API.
+var _ = Describe("Image List API", func() {
+	var (
+		ctx      context.Context
+		mockCtrl *gomock.Controller
+		logger   *mocks_logger.Logger
+		cdClient *mocks_backend.MockContainerdClient
+		ncClient *mocks_backend.MockNerdctlImageSvc
+		con      *mocks_container.MockContainer
+		created  time.Time
+		alpine   images.Image
+		alpine3  images.Image
+		busybox  images.Image
+		service  image.Service
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		// initialize mocks
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
+		con = mocks_container.NewMockContainer(mockCtrl)
+		created = time.Unix(1700000000, 0)
+		alpine = images.Image{
+			Name:      "docker.io/library/alpine:latest",
+			Target:    ocispec.Descriptor{Digest: "sha256:aaa"},
+			CreatedAt: created.Add(time.Minute),
+		}
+		alpine3 = images.Image{
+			Name:      "docker.io/library/alpine:3",
+			Target:    ocispec.Descriptor{Digest: "sha256:aaa"},
+			CreatedAt: created,
+		}
+		busybox = images.Image{
+			Name:      "public.ecr.aws/docker/library/busybox:latest",
+			Target:    ocispec.Descriptor{Digest: "sha256:bbb"},
+			CreatedAt: created,
+		}
+		service = NewService(cdClient, ncClient, logger)
+	})
+	expectContainers := func(imageNames ...string) {
+		cons := make([]containerd.Container, 0, len(imageNames))
+		for _, name := range imageNames {
+			con.EXPECT().Info(gomock.Any(), gomock.Any()).Return(containers.Container{Image: name}, nil)
+			cons = append(cons, con)
+		}
+		cdClient.EXPECT().GetContainers(gomock.Any()).Return(cons, nil)
+	}
+	expectContentLabels := func(target any, contentLabels map[string]string) {
+		cdClient.EXPECT().GetContentLabels(gomock.Any(), target).Return(contentLabels, nil).AnyTimes()
+	}
+	Context("service", func() {
+		It("should return one summary per image with all its names", func() {
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).Return([]images.Image{alpine, busybox, alpine3}, nil)
+			expectContainers(alpine3.Name, alpine3.Name, alpine.Name)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), alpine, nil).Return([]string{"overlayfs"}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), busybox, nil).Return([]string{"overlayfs", "stargz"}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), alpine, nil).Return(&dockercompat.Image{
+				Size:   100,
+				Config: &dockercompat.Config{Labels: map[string]string{"foo": "bar"}},
+			}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), busybox, nil).Return(&dockercompat.Image{Size: 200}, nil)
+			expectContentLabels(alpine.Target.Digest, map[string]string{
+				"containerd.io/distribution.source.docker.io": "library/alpine",
+			})
+			expectContentLabels(busybox.Target.Digest, map[string]string{
+				"containerd.io/distribution.source.public.ecr.aws": "docker/library/busybox,docker/library/other",
+			})
+
+			summaries, err := service.List(ctx, types.ImageListOptions{})
+			Expect(err).Should(BeNil())
+			Expect(summaries).Should(Equal([]types.ImageSummary{
+				{
+					ID:           "sha256:aaa",
+					RepoTags:     []string{"alpine:latest", "alpine:3"},
+					RepoDigests:  []string{"alpine@sha256:aaa"},
+					Created:      created.Unix(),
+					Size:         100,
+					SharedSize:   -1,
+					VirtualSize:  100,
+					Labels:       map[string]string{"foo": "bar"},
+					Containers:   3,
+					Snapshotters: []string{"overlayfs"},
+				},
+				{
+					ID:           "sha256:bbb",
+					RepoTags:     []string{"public.ecr.aws/docker/library/busybox:latest"},
+					RepoDigests:  []string{"public.ecr.aws/docker/library/busybox@sha256:bbb"},
+					Created:      created.Unix(),
+					Size:         200,
+					SharedSize:   -1,
+					VirtualSize:  200,
+					Snapshotters: []string{"overlayfs", "stargz"},
+				},
+			}))
+		})
+		It("should return the repo digest of dangling images without tags", func() {
+			target := digest.FromString("alpine")
+			dangling := images.Image{
+				Name:   "docker.io/library/alpine@" + target.String(),
+				Target: ocispec.Descriptor{Digest: target},
+			}
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).Return([]images.Image{dangling}, nil)
+			expectContainers()
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), dangling, nil).Return([]string{"overlayfs"}, nil)
+			ncClient.EXPECT().InspectImage(gomock.Any(), dangling, nil).Return(&dockercompat.Image{}, nil)
+			expectContentLabels(target, map[string]string{"containerd.io/distribution.source.docker.io": "library/alpine"})
+
+			summaries, err := service.List(ctx, types.ImageListOptions{})
+			Expect(err).Should(BeNil())
+			Expect(summaries).Should(HaveLen(1))
+			Expect(summaries[0].RepoTags).Should(BeEmpty())
+			Expect(summaries[0].RepoDigests).Should(Equal([]string{"alpine@" + target.String()}))
+		})
+		It("should not return repo digests of images which were not pulled or pushed", func() {
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).Return([]images.Image{alpine, busybox}, nil)
+			expectContainers()
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), gomock.Any(), nil).Return([]string{"overlayfs"}, nil).Times(2)
+			ncClient.EXPECT().InspectImage(gomock.Any(), gomock.Any(), nil).Return(&dockercompat.Image{}, nil).Times(2)
+			// alpine was pulled from another repository, and busybox was built or loaded.
+			expectContentLabels(alpine.Target.Digest, map[string]string{
+				"containerd.io/distribution.source.public.ecr.aws": "docker/library/alpine",
+			})
+			cdClient.EXPECT().GetContentLabels(gomock.Any(), busybox.Target.Digest).Return(nil, cerrdefs.ErrNotFound)
+
+			summaries, err := service.List(ctx, types.ImageListOptions{})
+			Expect(err).Should(BeNil())
+			Expect(summaries).Should(HaveLen(2))
+			Expect(summaries[0].RepoTags).Should(Equal([]string{"alpine:latest"}))
+			Expect(summaries[0].RepoDigests).Should(BeEmpty())
+			Expect(summaries[1].RepoDigests).Should(BeEmpty())
+		})
+		It("should skip images which are not unpacked unless all images are requested", func() {
+			expectContentLabels(gomock.Any(), nil)
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).Return([]images.Image{alpine, busybox}, nil).Times(2)
+			expectContainers()
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), alpine, nil).Return(nil, nil).Times(2)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), busybox, nil).Return([]string{"stargz"}, nil).Times(2)
+			ncClient.EXPECT().InspectImage(gomock.Any(), busybox, nil).Return(&dockercompat.Image{}, nil).Times(2)
+
+			summaries, err := service.List(ctx, types.ImageListOptions{})
+			Expect(err).Should(BeNil())
+			Expect(summaries).Should(HaveLen(1))
+			Expect(summaries[0].ID).Should(Equal("sha256:bbb"))
+			Expect(summaries[0].Snapshotters).Should(Equal([]string{"stargz"}))
+
+			expectContainers()
+			ncClient.EXPECT().InspectImage(gomock.Any(), alpine, nil).Return(&dockercompat.Image{}, nil)
+			summaries, err = service.List(ctx, types.ImageListOptions{All: true})
+			Expect(err).Should(BeNil())
+			Expect(summaries).Should(HaveLen(2))
+		})
+		It("should pass the parsed filters to the backend", func() {
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, filters *imgutil.Filters) ([]images.Image, error) {
+					Expect(*filters.Dangling).Should(BeFalse())
+					Expect(filters.Labels).Should(Equal(map[string]string{"foo": "bar"}))
+					Expect(filters.Reference).Should(Equal([]string{"alpine"}))
+					Expect(filters.Until).Should(Equal("10m"))
+					Expect(filters.Before).Should(ContainElement("name==busybox"))
+					Expect(filters.Since).Should(ContainElement("name==alpine:3"))
+					return nil, nil
+				})
+			expectContainers()
+
+			summaries, err := service.List(ctx, types.ImageListOptions{Filters: []string{
+				"dangling=false", "label=foo=bar", "reference=alpine", "until=10m", "before=busybox", "since=alpine:3",
+			}})
+			Expect(err).Should(BeNil())
+			Expect(summaries).Should(BeEmpty())
+		})
+		It("should return an InvalidFormat error if a filter is invalid", func() {
+			_, err := service.List(ctx, types.ImageListOptions{Filters: []string{"foo=bar"}})
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+		})
+		It("should compute the size of the layers shared with other images", func() {
+			expectContentLabels(gomock.Any(), nil)
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).Return([]images.Image{alpine, busybox}, nil)
+			expectContainers()
+			// busybox does not provide the default platform
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), alpine, nil).Return([]string{"overlayfs"}, nil)
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), busybox, nil).Return(nil, cerrdefs.ErrNotFound)
+			ncClient.EXPECT().InspectImage(gomock.Any(), gomock.Any(), nil).Return(&dockercompat.Image{}, nil).Times(2)
+			ncClient.EXPECT().GetImageLayerSizes(gomock.Any(), alpine).Return(map[string]int64{"layer1": 10, "layer2": 20}, nil)
+			ncClient.EXPECT().GetImageLayerSizes(gomock.Any(), busybox).Return(nil, cerrdefs.ErrNotFound)
+
+			summaries, err := service.List(ctx, types.ImageListOptions{All: true, SharedSize: true})
+			Expect(err).Should(BeNil())
+			Expect(summaries[0].SharedSize).Should(BeZero())
+			Expect(summaries[1].SharedSize).Should(BeZero())
+		})
+		It("should only count the layers used by more than one image as shared", func() {
+			expectContentLabels(gomock.Any(), nil)
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).Return([]images.Image{alpine, busybox}, nil)
+			expectContainers()
+			ncClient.EXPECT().GetImageSnapshotters(gomock.Any(), gomock.Any(), nil).Return([]string{"overlayfs"}, nil).Times(2)
+			ncClient.EXPECT().InspectImage(gomock.Any(), gomock.Any(), nil).Return(&dockercompat.Image{}, nil).Times(2)
+			ncClient.EXPECT().GetImageLayerSizes(gomock.Any(), alpine).Return(map[string]int64{"layer1": 10, "layer2": 20}, nil)
+			ncClient.EXPECT().GetImageLayerSizes(gomock.Any(), busybox).Return(map[string]int64{"layer1": 10, "layer3": 30}, nil)
+
+			summaries, err := service.List(ctx, types.ImageListOptions{SharedSize: true})
+			Expect(err).Should(BeNil())
+			Expect(summaries[0].SharedSize).Should(Equal(int64(10)))
+			Expect(summaries[1].SharedSize).Should(Equal(int64(10)))
+		})
+		It("should return an error if listing the images fails", func() {
+			ncClient.EXPECT().ListImages(gomock.Any(), gomock.Any()).Return(nil, errors.New("list error"))
+
+			_, err := service.List(ctx, types.ImageListOptions{})
+			Expect(err).Should(MatchError(ContainSubstring("list error")))
+		})
+	})
+})
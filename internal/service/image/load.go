@@ -7,16 +7,26 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/fifo"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/pkg/jsonmessage"
 )
 
+// loadPollInterval is the interval at which the progress of a load is reported.
+const loadPollInterval = 100 * time.Millisecond
+
+// Load loads the images of the docker-archive or OCI layout tarball read from inStream, and writes the progress
+// of the load, unless quiet, and the loaded images to outStream as JSON messages.
 func (s *service) Load(ctx context.Context, inStream io.Reader, outStream io.Writer, quiet bool) error {
 	if inStream == nil {
 		return fmt.Errorf("import stream should not be nil")
@@ -28,7 +38,6 @@ func (s *service) Load(ctx context.Context, inStream io.Reader, outStream io.Wri
 	}
 	d := filepath.Join(root, "fifo")
 	if err = os.Mkdir(d, 0700); err != nil && !os.IsExist(err) {
-		fmt.Println(err)
 		s.logger.Errorf("failed to create fifo dir %s: %s", d, err)
 		return err
 	}
@@ -44,8 +53,9 @@ func (s *service) Load(ctx context.Context, inStream io.Reader, outStream io.Wri
 	defer func() {
 		os.Remove(img)
 	}()
+	in := &countingReader{Reader: inStream}
 	go func() {
-		written, err := io.Copy(rw, inStream)
+		written, err := io.Copy(rw, in)
 		if err != nil {
 			s.logger.Errorf("failed to copy: %s", err)
 		} else {
@@ -53,9 +63,99 @@ func (s *service) Load(ctx context.Context, inStream io.Reader, outStream io.Wri
 		}
 		rw.Close()
 	}()
-	if err = s.nctlImageSvc.LoadImage(ctx, img, outStream, quiet); err != nil {
+
+	progress := newLoadProgress(outStream)
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		if quiet {
+			return
+		}
+		ticker := time.NewTicker(loadPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+				progress.update(in.count.Load())
+			}
+		}
+	}()
+	loaded, err := s.nctlImageSvc.LoadImage(ctx, img)
+	stopPolling()
+	<-polled
+	if !quiet {
+		progress.update(in.count.Load())
+	}
+
+	// the images loaded before an error are reported too, as they are kept.
+	snapshotter := s.nctlImageSvc.Snapshotter()
+	for _, loadedImg := range loaded {
+		name := progress.loaded(loadedImg, snapshotter)
+		s.publishImageEvent(ctx, loadEventAction, loadedImg.Target.Digest, name)
+	}
+	if err != nil {
 		s.logger.Errorf("failed to load image %s: %s", img, err)
 		return err
 	}
 	return nil
 }
+
+// countingReader counts the bytes read from the reader, which may be read concurrently.
+type countingReader struct {
+	io.Reader
+	count atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+// loadProgress reports the progress of an image load as the JSON messages dockerd streams.
+type loadProgress struct {
+	encoder *json.Encoder
+	current int64
+}
+
+func newLoadProgress(w io.Writer) *loadProgress {
+	return &loadProgress{encoder: json.NewEncoder(w)}
+}
+
+// write writes a message, ignoring errors as the load goes on even if the client has gone away.
+func (p *loadProgress) write(msg jsonmessage.JSONMessage) {
+	_ = p.encoder.Encode(msg)
+}
+
+// update reports the number of bytes of the tarball read so far, if it changed.
+func (p *loadProgress) update(current int64) {
+	if current == p.current {
+		return
+	}
+	p.current = current
+	progress := &jsonmessage.JSONProgress{Current: current}
+	p.write(jsonmessage.JSONMessage{
+		Status:          "Loading",
+		Progress:        progress,
+		ProgressMessage: progress.String(),
+	})
+}
+
+// loaded reports a loaded image, by its reference or by its ID if it was loaded without a name,
+// and returns the name it was reported with.
+func (p *loadProgress) loaded(img images.Image, snapshotter string) string {
+	// nerdctl names the images loaded without a name with the snapshotter and their digest.
+	if img.Name != snapshotter+"@"+img.Target.Digest.String() {
+		if named, err := reference.ParseNormalizedNamed(img.Name); err == nil {
+			name := reference.FamiliarString(named)
+			p.write(jsonmessage.JSONMessage{Stream: fmt.Sprintf("Loaded image: %s\n", name)})
+			return name
+		}
+	}
+	id := img.Target.Digest.String()
+	p.write(jsonmessage.JSONMessage{Stream: fmt.Sprintf("Loaded image ID: %s\n", id)})
+	return id
+}
@@ -4,14 +4,21 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"os"
 	"strings"
 
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/runfinch/finch-daemon/api/handlers/image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
@@ -28,6 +35,7 @@ var _ = Describe("Image Load API", func() {
 		ncClient *mocks_backend.MockNerdctlImageSvc
 		name     string
 		inStream io.Reader
+		out      *bytes.Buffer
 		service  image.Service
 	)
 	BeforeEach(func() {
@@ -36,33 +44,84 @@ var _ = Describe("Image Load API", func() {
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
 		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
-		name = "/tmp"
+		name = GinkgoT().TempDir()
 		inStream = strings.NewReader("")
+		out = &bytes.Buffer{}
+		ncClient.EXPECT().Snapshotter().Return("overlayfs").AnyTimes()
 		service = NewService(cdClient, ncClient, logger)
 	})
 	Context("service", func() {
-		It("should return no errors upon success", func() {
+		It("should report the loaded images upon success", func() {
+			target := digest.FromString("test-image")
 			ncClient.EXPECT().GetDataStore().
 				Return(name, nil)
-			ncClient.EXPECT().LoadImage(gomock.Any(), gomock.Any(), nil, gomock.Any()).
-				Return(nil)
+			ncClient.EXPECT().LoadImage(gomock.Any(), gomock.Any()).
+				Return([]images.Image{
+					{Name: "docker.io/library/test-image:latest", Target: ocispec.Descriptor{Digest: target}},
+					{Name: "registry.example.com/test-image:1", Target: ocispec.Descriptor{Digest: target}},
+				}, nil)
+			cdClient.EXPECT().PublishEvent(gomock.Any(), imageTopic(loadEventAction), getImageEvent(loadEventAction, target, "test-image:latest"))
+			cdClient.EXPECT().PublishEvent(gomock.Any(), imageTopic(loadEventAction), getImageEvent(loadEventAction, target, "registry.example.com/test-image:1"))
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
 
-			// service should return no error
-			err := service.Load(ctx, inStream, nil, false)
+			// service should write a line for every loaded image
+			err := service.Load(ctx, inStream, out, true)
 			Expect(err).Should(BeNil())
+			Expect(out.String()).Should(Equal(
+				`{"stream":"Loaded image: test-image:latest\n"}` + "\n" +
+					`{"stream":"Loaded image: registry.example.com/test-image:1\n"}` + "\n"))
 		})
-		It("should return an error if load image method returns an error", func() {
+		It("should report the ID of images loaded without a name", func() {
+			target := digest.FromString("test-image")
 			ncClient.EXPECT().GetDataStore().
 				Return(name, nil)
+			ncClient.EXPECT().LoadImage(gomock.Any(), gomock.Any()).
+				Return([]images.Image{{Name: "overlayfs@" + target.String(), Target: ocispec.Descriptor{Digest: target}}}, nil)
+			cdClient.EXPECT().PublishEvent(gomock.Any(), gomock.Any(), getImageEvent(loadEventAction, target, target.String()))
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
-			ncClient.EXPECT().LoadImage(gomock.Any(), gomock.Any(), nil, gomock.Any()).
-				Return(errors.New("error message"))
-			logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
+
+			// service should write the ID of the image
+			err := service.Load(ctx, inStream, out, true)
+			Expect(err).Should(BeNil())
+			Expect(out.String()).Should(Equal(`{"stream":"Loaded image ID: ` + target.String() + `\n"}` + "\n"))
+		})
+		It("should report the progress of the load unless quiet", func() {
+			inStream = strings.NewReader("test-archive")
+			ncClient.EXPECT().GetDataStore().
+				Return(name, nil)
+			ncClient.EXPECT().LoadImage(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, img string) ([]images.Image, error) {
+					// read the archive from the fifo, as nerdctl does.
+					b, err := os.ReadFile(img)
+					Expect(err).Should(BeNil())
+					Expect(string(b)).Should(Equal("test-archive"))
+					return nil, nil
+				})
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+
+			// service should write the number of bytes read
+			err := service.Load(ctx, inStream, out, false)
+			Expect(err).Should(BeNil())
+			var msg jsonmessage.JSONMessage
+			Expect(json.NewDecoder(out).Decode(&msg)).Should(Succeed())
+			Expect(msg.Status).Should(Equal("Loading"))
+			Expect(msg.Progress.Current).Should(Equal(int64(len("test-archive"))))
+		})
+		It("should report the images loaded before an error", func() {
+			target := digest.FromString("test-image")
+			ncClient.EXPECT().GetDataStore().
+				Return(name, nil)
+			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+			ncClient.EXPECT().LoadImage(gomock.Any(), gomock.Any()).
+				Return([]images.Image{{Name: "docker.io/library/test-image:latest", Target: ocispec.Descriptor{Digest: target}}},
+					errors.New("error message"))
+			cdClient.EXPECT().PublishEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("publish error"))
+			logger.EXPECT().Errorf(gomock.Any(), gomock.Any()).Times(2)
 
 			// service should return an error
-			err := service.Load(ctx, inStream, nil, false)
+			err := service.Load(ctx, inStream, out, true)
 			Expect(err).ShouldNot(BeNil())
+			Expect(out.String()).Should(ContainSubstring("Loaded image: test-image:latest"))
 		})
 		It("should return an error if get datastore method returns an error", func() {
 			ncClient.EXPECT().GetDataStore().
@@ -70,12 +129,12 @@ var _ = Describe("Image Load API", func() {
 			logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
 
 			// service should return an error
-			err := service.Load(ctx, inStream, nil, false)
+			err := service.Load(ctx, inStream, out, false)
 			Expect(err).ShouldNot(BeNil())
 		})
 		It("should return an error if import stream is nil", func() {
 			// service should return an error
-			err := service.Load(ctx, nil, nil, false)
+			err := service.Load(ctx, nil, out, false)
 			Expect(err).ShouldNot(BeNil())
 		})
 	})
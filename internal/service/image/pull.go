@@ -13,14 +13,18 @@ import (
 
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/imgcrypt/v2/images/encryption"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+	"github.com/distribution/reference"
 	dockertypes "github.com/docker/cli/cli/config/types"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
+	"github.com/runfinch/finch-daemon/pkg/utility/imageutility"
 )
 
-func (s *service) Pull(ctx context.Context, name, tag, platformStr string, ac *dockertypes.AuthConfig, outStream io.Writer) error {
+func (s *service) Pull(ctx context.Context, name, tag, platformStr, snapshotter string, ac *dockertypes.AuthConfig, outStream io.Writer) error {
 	// get host platform's default spec if unspecified
 	var platform ocispec.Platform
 	var err error
@@ -32,6 +36,9 @@ func (s *service) Pull(ctx context.Context, name, tag, platformStr string, ac *d
 	if err != nil {
 		return fmt.Errorf("invalid platform %s: %s", platformStr, err)
 	}
+	if err := s.checkSnapshotter(ctx, snapshotter); err != nil {
+		return err
+	}
 
 	// parse image reference into registry hostname and image name
 	rawRef := toImageRef(name, tag)
@@ -53,31 +60,161 @@ func (s *service) Pull(ctx context.Context, name, tag, platformStr string, ac *d
 		return fmt.Errorf("failed to initialize remotes resolver: %s", err)
 	}
 
+	// resolve the image before writing any progress, so that resolver errors can be returned with a status code.
+	_, root, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return toPullError(err)
+	}
+	pullRef, err := s.verifyImage(ctx, ref, root.Digest)
+	if err != nil {
+		return err
+	}
+	upToDate := s.isPulled(ctx, ref, root.Digest)
+	// the image only has a record of the pinned reference if it was pulled with it before.
+	keepPinned := pullRef == ref || s.isPulled(ctx, pullRef, root.Digest)
+
+	progress := newPullProgress(outStream)
+	progress.start(ref, tag)
+	if layers, err := s.nctlImageSvc.GetLayerProgress(ctx, root, platform, snapshotter); err == nil {
+		progress.init(layers)
+	}
+
+	// poll the progress of the layers while pulling, as the pull does not report it.
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		ticker := time.NewTicker(pullPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+				s.reportLayerProgress(pollCtx, progress, root, platform, snapshotter)
+			}
+		}
+	}()
+
 	// finally, pull the image
 	_, err = s.nctlImageSvc.PullImage(
 		ctx,
-		outStream, outStream,
+		nil, nil,
 		resolver,
-		ref,
+		pullRef,
 		[]ocispec.Platform{platform},
+		snapshotter,
 	)
-
+	stopPolling()
+	<-polled
 	if err != nil {
-		if errors.Is(err, docker.ErrInvalidAuthorization) || cerrdefs.IsNotFound(err) {
-			err = errdefs.NewNotFound(err)
+		if !cerrdefs.IsPermissionDenied(err) && s.isEncrypted(ctx, root, platform, snapshotter) {
+			err = fmt.Errorf("%w: failed to decrypt the layers of image %s, the daemon has no key to decrypt them: %w",
+				cerrdefs.ErrPermissionDenied, ref, err)
+		}
+		return toPullError(err)
+	}
+	if pullRef != ref {
+		if err := imageutility.TagPinnedImage(ctx, s.client.ImageService(), pullRef, ref, keepPinned); err != nil {
+			return err
 		}
+	}
 
-		// nerdctl issue: if there is an error during pull, it is returned before the
-		// progress writer is shutdown properly. This can cause panic as the progress writer
-		// tries to write to the http stream writer, which is nil after the handler returns.
-		// Wait 100ms to give progress writer enough time to exit.
-		//
-		// TODO: Fix upstream. https://github.com/containerd/nerdctl/blob/v1.4.0/pkg/imgutil/pull/pull.go#L95-L101
-		time.Sleep(time.Millisecond * 100)
+	s.reportLayerProgress(ctx, progress, root, platform, snapshotter)
+	progress.complete(ref, root.Digest, upToDate)
+	s.publishImageEvent(ctx, pullEventAction, root.Digest, familiarName(ref))
+	return nil
+}
+
+// toPullError returns a NotFound error if the image could not be resolved or the registry denied access to it,
+// and a Forbidden error if the layers of the image could not be decrypted.
+func toPullError(err error) error {
+	if errors.Is(err, docker.ErrInvalidAuthorization) || cerrdefs.IsNotFound(err) {
+		return errdefs.NewNotFound(err)
+	}
+	if cerrdefs.IsPermissionDenied(err) {
+		return errdefs.NewForbidden(err)
 	}
 	return err
 }
 
+// isEncrypted returns whether the image has encrypted layers which are all downloaded, in which case a failed pull
+// failed to unpack them.
+func (s *service) isEncrypted(ctx context.Context, root ocispec.Descriptor, platform ocispec.Platform, snapshotter string) bool {
+	layers, err := s.nctlImageSvc.GetLayerProgress(ctx, root, platform, snapshotter)
+	if err != nil {
+		return false
+	}
+	encrypted := false
+	for _, layer := range layers {
+		if !layer.Downloaded {
+			return false
+		}
+		encrypted = encrypted || encryption.IsEncryptedDiff(ctx, layer.Descriptor.MediaType)
+	}
+	return encrypted
+}
+
+// verifyImage verifies the image against the verification policy of the daemon, and checks that it was verified
+// at the digest the reference resolved to. It returns the reference to pull, which is pinned to the verified digest
+// so that the pulled image is the verified one even if the reference is pushed to in the meantime.
+func (s *service) verifyImage(ctx context.Context, ref string, target digest.Digest) (string, error) {
+	verified, err := s.nctlImageSvc.VerifyImage(ctx, ref)
+	if err != nil {
+		return "", errdefs.NewForbidden(fmt.Errorf("image verification failed for %s: %w", ref, err))
+	}
+	if verified == "" {
+		return ref, nil
+	}
+	if verified != target {
+		return "", errdefs.NewForbidden(fmt.Errorf("image verification failed for %s: verified digest %s does not match the resolved digest %s",
+			ref, verified, target))
+	}
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", errdefs.NewInvalidFormat(err)
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), verified)
+	if err != nil {
+		return "", err
+	}
+	return pinned.String(), nil
+}
+
+// checkSnapshotter checks that containerd provides the snapshotter the image is requested to be unpacked into, if any.
+func (s *service) checkSnapshotter(ctx context.Context, snapshotter string) error {
+	if snapshotter == "" {
+		return nil
+	}
+	ok, err := s.client.HasSnapshotter(ctx, snapshotter)
+	if err != nil {
+		return fmt.Errorf("failed to check snapshotter %s: %w", snapshotter, err)
+	}
+	if !ok {
+		return errdefs.NewInvalidFormat(fmt.Errorf("snapshotter %s is not available", snapshotter))
+	}
+	return nil
+}
+
+// isPulled returns whether the reference already points to the image with the target digest.
+func (s *service) isPulled(ctx context.Context, ref string, target digest.Digest) bool {
+	img, err := s.client.ImageService().Get(ctx, ref)
+	return err == nil && img.Target.Digest == target
+}
+
+// reportLayerProgress reports the current progress of the layers of the image being pulled.
+func (s *service) reportLayerProgress(ctx context.Context, progress *pullProgress, root ocispec.Descriptor, platform ocispec.Platform, snapshotter string) {
+	layers, err := s.nctlImageSvc.GetLayerProgress(ctx, root, platform, snapshotter)
+	if err != nil {
+		// the manifest is not fetched yet.
+		if !cerrdefs.IsNotFound(err) {
+			s.logger.Debugf("failed to get the progress of the layers: %s", err)
+		}
+		return
+	}
+	progress.update(layers)
+}
+
 func toImageRef(name, tag string) string {
 	if tag == "" {
 		return name
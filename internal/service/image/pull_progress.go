@@ -0,0 +1,169 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/internal/backend"
+)
+
+// pullPollInterval is the interval at which the progress of the layers is polled during a pull.
+const pullPollInterval = 100 * time.Millisecond
+
+// layerState is the state of a layer being pulled, in the order a layer goes through them.
+type layerState int
+
+const (
+	layerPending layerState = iota
+	layerWaiting
+	layerDownloading
+	layerDownloaded
+	layerExtracting
+	layerComplete
+)
+
+// pullProgress reports the progress of an image pull as the JSON messages dockerd streams,
+// with the status of each layer identified by its short digest.
+type pullProgress struct {
+	encoder *json.Encoder
+	// layers are the layers reported so far, in the order they were first reported.
+	layers  []digest.Digest
+	states  map[digest.Digest]layerState
+	offsets map[digest.Digest]int64
+}
+
+func newPullProgress(w io.Writer) *pullProgress {
+	return &pullProgress{
+		encoder: json.NewEncoder(w),
+		states:  make(map[digest.Digest]layerState),
+		offsets: make(map[digest.Digest]int64),
+	}
+}
+
+// write writes a message, ignoring errors as the pull goes on even if the client has gone away.
+func (p *pullProgress) write(msg jsonmessage.JSONMessage) {
+	_ = p.encoder.Encode(msg)
+}
+
+// start reports the start of the pull of the reference, which is identified by its tag or digest.
+func (p *pullProgress) start(ref, tag string) {
+	repo := ref
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		repo = reference.Path(named)
+	}
+	p.write(jsonmessage.JSONMessage{ID: tag, Status: "Pulling from " + repo})
+}
+
+// init reports the layers which are unpacked before the pull as already existing.
+func (p *pullProgress) init(layers []backend.LayerProgress) {
+	for _, layer := range layers {
+		if layer.Unpacked {
+			p.layers = append(p.layers, layer.Descriptor.Digest)
+			p.states[layer.Descriptor.Digest] = layerComplete
+			p.write(jsonmessage.JSONMessage{ID: layerID(layer.Descriptor.Digest), Status: "Already exists"})
+		}
+	}
+}
+
+// update reports the changes in the progress of the layers. Layers are extracted in order,
+// so a layer is only reported as extracting once the layers below it are complete.
+func (p *pullProgress) update(layers []backend.LayerProgress) {
+	belowComplete := true
+	for _, layer := range layers {
+		var target layerState
+		switch {
+		case layer.Unpacked:
+			target = layerComplete
+		case layer.Downloaded && belowComplete:
+			target = layerExtracting
+		case layer.Downloaded:
+			target = layerDownloaded
+		case layer.Downloading:
+			target = layerDownloading
+		default:
+			target = layerWaiting
+		}
+		p.advance(layer, target)
+		belowComplete = belowComplete && p.states[layer.Descriptor.Digest] == layerComplete
+	}
+}
+
+// complete reports the layers which are not reported as complete yet, e.g. the layers of lazily pulled
+// images, followed by the digest and the status of the pulled image.
+func (p *pullProgress) complete(ref string, target digest.Digest, upToDate bool) {
+	for _, dgst := range p.layers {
+		if p.states[dgst] != layerComplete {
+			p.advance(backend.LayerProgress{Descriptor: ocispec.Descriptor{Digest: dgst}}, layerComplete)
+		}
+	}
+	name := ref
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		name = reference.FamiliarString(named)
+	}
+	p.write(jsonmessage.JSONMessage{Status: "Digest: " + target.String()})
+	if upToDate {
+		p.write(jsonmessage.JSONMessage{Status: "Status: Image is up to date for " + name})
+	} else {
+		p.write(jsonmessage.JSONMessage{Status: "Status: Downloaded newer image for " + name})
+	}
+}
+
+// advance moves the layer up to the target state, reporting each state it goes through. The waiting and
+// downloading states are only reported when they are the target, as they are not meaningful afterwards.
+func (p *pullProgress) advance(layer backend.LayerProgress, target layerState) {
+	dgst := layer.Descriptor.Digest
+	id := layerID(dgst)
+	state := p.states[dgst]
+	if state == layerPending {
+		p.layers = append(p.layers, dgst)
+		p.write(jsonmessage.JSONMessage{ID: id, Status: "Pulling fs layer"})
+	}
+	// the progress of a download is reported whenever it changes.
+	downloadChanged := state < layerDownloading || layer.Offset != p.offsets[dgst]
+	if target == layerDownloading && state <= layerDownloading && downloadChanged {
+		progress := &jsonmessage.JSONProgress{Current: layer.Offset, Total: layer.Descriptor.Size}
+		p.write(jsonmessage.JSONMessage{
+			ID:              id,
+			Status:          "Downloading",
+			Progress:        progress,
+			ProgressMessage: progress.String(),
+		})
+		p.offsets[dgst] = layer.Offset
+	}
+	for next := state + 1; next <= target; next++ {
+		switch next {
+		case layerWaiting:
+			if target == layerWaiting {
+				p.write(jsonmessage.JSONMessage{ID: id, Status: "Waiting"})
+			}
+		case layerDownloaded:
+			p.write(jsonmessage.JSONMessage{ID: id, Status: "Verifying Checksum"})
+			p.write(jsonmessage.JSONMessage{ID: id, Status: "Download complete"})
+		case layerExtracting:
+			p.write(jsonmessage.JSONMessage{ID: id, Status: "Extracting"})
+		case layerComplete:
+			p.write(jsonmessage.JSONMessage{ID: id, Status: "Pull complete"})
+		}
+	}
+	if target > state {
+		p.states[dgst] = target
+	}
+}
+
+// layerID returns the short ID dockerd identifies a layer with in the progress messages.
+func layerID(dgst digest.Digest) string {
+	id := dgst.Encoded()
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
@@ -0,0 +1,111 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bytes"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/internal/backend"
+)
+
+// Unit tests related to the progress of image pulls.
+var _ = Describe("Image Pull Progress", func() {
+	var (
+		out      *bytes.Buffer
+		progress *pullProgress
+		layer1   ocispec.Descriptor
+		layer2   ocispec.Descriptor
+		id1, id2 string
+	)
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		progress = newPullProgress(out)
+		layer1 = ocispec.Descriptor{Digest: digest.FromString("layer1"), Size: 100}
+		layer2 = ocispec.Descriptor{Digest: digest.FromString("layer2"), Size: 200}
+		id1 = layer1.Digest.Encoded()[:12]
+		id2 = layer2.Digest.Encoded()[:12]
+	})
+	It("should report the start of the pull with the tag and the repository path", func() {
+		progress.start("docker.io/library/alpine:latest", "latest")
+		Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+			{ID: "latest", Status: "Pulling from library/alpine"},
+		}))
+	})
+	It("should report the layers unpacked before the pull as already existing", func() {
+		progress.init([]backend.LayerProgress{
+			{Descriptor: layer1, Downloaded: true, Unpacked: true},
+			{Descriptor: layer2},
+		})
+		progress.update([]backend.LayerProgress{
+			{Descriptor: layer1, Downloaded: true, Unpacked: true},
+			{Descriptor: layer2},
+		})
+		Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+			{ID: id1, Status: "Already exists"},
+			{ID: id2, Status: "Pulling fs layer"},
+			{ID: id2, Status: "Waiting"},
+		}))
+	})
+	It("should report the download progress whenever it changes", func() {
+		progress.update([]backend.LayerProgress{{Descriptor: layer1, Downloading: true, Offset: 10}})
+		progress.update([]backend.LayerProgress{{Descriptor: layer1, Downloading: true, Offset: 10}})
+		progress.update([]backend.LayerProgress{{Descriptor: layer1, Downloading: true, Offset: 50}})
+		msgs := decodeMessages(out)
+		Expect(msgs).Should(HaveLen(3))
+		Expect(msgs[0]).Should(Equal(jsonmessage.JSONMessage{ID: id1, Status: "Pulling fs layer"}))
+		Expect(msgs[1].Status).Should(Equal("Downloading"))
+		Expect(msgs[1].Progress).Should(Equal(&jsonmessage.JSONProgress{Current: 10, Total: 100}))
+		Expect(msgs[2].Progress).Should(Equal(&jsonmessage.JSONProgress{Current: 50, Total: 100}))
+	})
+	It("should only report a layer as extracting once the layers below it are complete", func() {
+		progress.update([]backend.LayerProgress{
+			{Descriptor: layer1, Downloaded: true},
+			{Descriptor: layer2, Downloaded: true},
+		})
+		progress.update([]backend.LayerProgress{
+			{Descriptor: layer1, Downloaded: true, Unpacked: true},
+			{Descriptor: layer2, Downloaded: true},
+		})
+		Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+			{ID: id1, Status: "Pulling fs layer"},
+			{ID: id1, Status: "Verifying Checksum"},
+			{ID: id1, Status: "Download complete"},
+			{ID: id1, Status: "Extracting"},
+			{ID: id2, Status: "Pulling fs layer"},
+			{ID: id2, Status: "Verifying Checksum"},
+			{ID: id2, Status: "Download complete"},
+			{ID: id1, Status: "Pull complete"},
+			{ID: id2, Status: "Extracting"},
+		}))
+	})
+	It("should complete the remaining layers and report the digest and the status", func() {
+		target := digest.FromString("manifest")
+		progress.update([]backend.LayerProgress{{Descriptor: layer1, Downloading: true, Offset: 10}})
+		out.Reset()
+
+		progress.complete("docker.io/library/alpine:latest", target, false)
+		Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+			{ID: id1, Status: "Verifying Checksum"},
+			{ID: id1, Status: "Download complete"},
+			{ID: id1, Status: "Extracting"},
+			{ID: id1, Status: "Pull complete"},
+			{Status: "Digest: " + target.String()},
+			{Status: "Status: Downloaded newer image for alpine:latest"},
+		}))
+	})
+	It("should report that the image is up to date", func() {
+		target := digest.FromString("manifest")
+		progress.complete("docker.io/library/alpine:latest", target, true)
+		Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+			{Status: "Digest: " + target.String()},
+			{Status: "Status: Image is up to date for alpine:latest"},
+		}))
+	})
+})
@@ -4,20 +4,30 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 
+	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/containerd/v2/core/remotes"
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	dockertypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
+	"github.com/runfinch/finch-daemon/internal/backend"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
@@ -30,6 +40,7 @@ var _ = Describe("Image Pull API ", func() {
 			mockCtrl    *gomock.Controller
 			logger      *mocks_logger.Logger
 			cdClient    *mocks_backend.MockContainerdClient
+			events      func() []*eventtype.Event
 			ncClient    *mocks_backend.MockNerdctlImageSvc
 			name        string
 			tag         string
@@ -40,6 +51,12 @@ var _ = Describe("Image Pull API ", func() {
 			authCfg     dockertypes.AuthConfig
 			authCreds   dockerconfigresolver.AuthCreds
 			resolver    remotes.Resolver
+			store       *mocks_image.MockStore
+			records     map[string]images.Image
+			out         *bytes.Buffer
+			progress    func() ([]backend.LayerProgress, error)
+			verified    digest.Digest
+			verifyErr   error
 			s           service
 		)
 		BeforeEach(func() {
@@ -48,6 +65,7 @@ var _ = Describe("Image Pull API ", func() {
 			mockCtrl = gomock.NewController(GinkgoT())
 			logger = mocks_logger.NewLogger(mockCtrl)
 			cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+			events = recordEvents(cdClient)
 			ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
 			name = "public.ecr.aws/test-image/test-image"
 			tag = "test-tag"
@@ -66,6 +84,30 @@ var _ = Describe("Image Pull API ", func() {
 				return authCfg.Username, authCfg.Password, nil
 			}
 			resolver = &mockResolver{}
+			store = mocks_image.NewMockStore(mockCtrl)
+			out = &bytes.Buffer{}
+
+			// the image is not pulled yet and no progress is reported by default
+			cdClient.EXPECT().ImageService().Return(store).AnyTimes()
+			records = map[string]images.Image{}
+			store.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, name string) (images.Image, error) {
+					if img, ok := records[name]; ok {
+						return img, nil
+					}
+					return images.Image{}, cerrdefs.ErrNotFound
+				}).AnyTimes()
+			progress = func() ([]backend.LayerProgress, error) { return nil, cerrdefs.ErrNotFound }
+			ncClient.EXPECT().GetLayerProgress(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(context.Context, ocispec.Descriptor, ocispec.Platform, string) ([]backend.LayerProgress, error) {
+					return progress()
+				}).AnyTimes()
+			// the verification policy does not require verifying the image by default
+			verified, verifyErr = "", nil
+			ncClient.EXPECT().VerifyImage(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(context.Context, string) (digest.Digest, error) {
+					return verified, verifyErr
+				}).AnyTimes()
 
 			s = service{
 				client:       cdClient,
@@ -88,14 +130,38 @@ var _ = Describe("Image Pull API ", func() {
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Not(gomock.Nil())).Return(
 				resolver, nil, nil,
 			)
-			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}).Return(
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
 				nil, nil,
 			)
 
 			// service should return no error
-			err := s.Pull(ctx, name, tag, platform, &authCfg, nil)
+			err := s.Pull(ctx, name, tag, platform, "", &authCfg, out)
 			Expect(err).ShouldNot(HaveOccurred())
 		})
+		It("should unpack the image into the requested snapshotter", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().HasSnapshotter(gomock.Any(), "stargz").Return(true, nil)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				resolver, nil, nil,
+			)
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "stargz").Return(
+				nil, nil,
+			)
+
+			// service should return no error
+			err := s.Pull(ctx, name, tag, "", "stargz", nil, out)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("should return an invalid format error if the snapshotter is not available", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().HasSnapshotter(gomock.Any(), "unknown").Return(false, nil)
+
+			// nothing should be pulled or written
+			err := s.Pull(ctx, name, tag, "", "unknown", nil, out)
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+			Expect(out.Len()).Should(BeZero())
+		})
 		It("should return no errors when reference spec includes a digest spec", func() {
 			tag := "sha256:7ea94d4e7f346a9328a9ff053ab149e3c99c1737f8d251094e7cc38664c3d4b9"
 			imageRef := fmt.Sprintf("%s@%s", name, tag)
@@ -113,12 +179,12 @@ var _ = Describe("Image Pull API ", func() {
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Not(gomock.Nil())).Return(
 				resolver, nil, nil,
 			)
-			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}).Return(
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
 				nil, nil,
 			)
 
 			// service should return no error
-			err := s.Pull(ctx, name, tag, platform, &authCfg, nil)
+			err := s.Pull(ctx, name, tag, platform, "", &authCfg, out)
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		It("should use default platform if not specified", func() {
@@ -133,12 +199,12 @@ var _ = Describe("Image Pull API ", func() {
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Not(gomock.Nil())).Return(
 				resolver, nil, nil,
 			)
-			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}).Return(
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
 				nil, nil,
 			)
 
 			// service should return no error
-			err := s.Pull(ctx, name, tag, "", &authCfg, nil)
+			err := s.Pull(ctx, name, tag, "", "", &authCfg, out)
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		It("should succeed without authentication", func() {
@@ -150,12 +216,12 @@ var _ = Describe("Image Pull API ", func() {
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
 				resolver, nil, nil,
 			)
-			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}).Return(
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
 				nil, nil,
 			)
 
 			// service should return no error
-			err := s.Pull(ctx, name, tag, "", nil, nil)
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		It("should return an error if platform is invalid", func() {
@@ -165,7 +231,7 @@ var _ = Describe("Image Pull API ", func() {
 			)
 
 			// service should return invalid platform error
-			err := s.Pull(ctx, name, tag, platform, nil, nil)
+			err := s.Pull(ctx, name, tag, platform, "", nil, out)
 			Expect(err).Should(HaveOccurred())
 		})
 		It("should return an error if image reference is invalid", func() {
@@ -176,7 +242,7 @@ var _ = Describe("Image Pull API ", func() {
 			)
 
 			// service should return invalid reference error
-			err := s.Pull(ctx, name, tag, "", nil, nil)
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
 			Expect(err).Should(HaveOccurred())
 		})
 		It("should return an error if credentials are invalid", func() {
@@ -190,7 +256,7 @@ var _ = Describe("Image Pull API ", func() {
 			)
 
 			// service should return invalid credentials error
-			err := s.Pull(ctx, name, tag, "", &authCfg, nil)
+			err := s.Pull(ctx, name, tag, "", "", &authCfg, out)
 			Expect(err).Should(HaveOccurred())
 			Expect(err.Error()).Should(ContainSubstring("invalid credentials"))
 		})
@@ -205,7 +271,7 @@ var _ = Describe("Image Pull API ", func() {
 			)
 
 			// service should return resolver error
-			err := s.Pull(ctx, name, tag, "", nil, nil)
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
 			Expect(err).Should(HaveOccurred())
 		})
 		It("should return an error upon service failure", func() {
@@ -217,12 +283,12 @@ var _ = Describe("Image Pull API ", func() {
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
 				resolver, nil, nil,
 			)
-			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}).Return(
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
 				nil, fmt.Errorf("service error"),
 			)
 
 			// service should return service error
-			err := s.Pull(ctx, name, tag, "", nil, nil)
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
 			Expect(err).Should(HaveOccurred())
 		})
 		It("should return a not found error if authorization failed", func() {
@@ -234,12 +300,12 @@ var _ = Describe("Image Pull API ", func() {
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
 				resolver, nil, nil,
 			)
-			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}).Return(
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
 				nil, docker.ErrInvalidAuthorization,
 			)
 
 			// service should return not found error
-			err := s.Pull(ctx, name, tag, "", nil, nil)
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
 			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 		})
 		It("should return a not found error if image cannot be resolved", func() {
@@ -251,13 +317,174 @@ var _ = Describe("Image Pull API ", func() {
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
 				resolver, nil, nil,
 			)
-			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}).Return(
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
 				nil, cerrdefs.ErrNotFound,
 			)
 
 			// service should return not found error
-			err := s.Pull(ctx, name, tag, "", nil, nil)
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+		})
+		It("should return a not found error if the resolver cannot find the image", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				&mockResolver{err: cerrdefs.ErrNotFound}, nil, nil,
+			)
+
+			// nothing should be written before the image is resolved
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
 			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+			Expect(out.Len()).Should(BeZero())
+		})
+		It("should return a forbidden error if the daemon has no key to decrypt the image", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				resolver, nil, nil,
+			)
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
+				nil, fmt.Errorf("failed to extract layer"),
+			)
+			// the encrypted layers are downloaded but cannot be unpacked
+			progress = func() ([]backend.LayerProgress, error) {
+				return []backend.LayerProgress{{
+					Descriptor: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip + "+encrypted"},
+					Downloaded: true,
+				}}, nil
+			}
+
+			// service should return a forbidden error
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(errdefs.IsForbiddenError(err)).Should(BeTrue())
+			Expect(err.Error()).Should(ContainSubstring("the daemon has no key to decrypt them"))
+		})
+		It("should return a forbidden error if the decryption keys cannot decrypt the image", func() {
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				resolver, nil, nil,
+			)
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(
+				nil, fmt.Errorf("%w: no decryption key of the daemon can decrypt image %s", cerrdefs.ErrPermissionDenied, imageRef),
+			)
+
+			// service should return a forbidden error
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(errdefs.IsForbiddenError(err)).Should(BeTrue())
+		})
+		It("should pull the image verified at the digest it resolves to and tag it", func() {
+			root := ocispec.Descriptor{Digest: digest.FromString("manifest")}
+			pinned := name + "@" + root.Digest.String()
+			verified = root.Digest
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				&mockResolver{root: root}, nil, nil,
+			)
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, gomock.Any(), pinned, []ocispec.Platform{ociPlatform}, "").DoAndReturn(
+				func(context.Context, io.Writer, io.Writer, remotes.Resolver, string, []ocispec.Platform, string) (*imgutil.EnsuredImage, error) {
+					records[pinned] = images.Image{Name: pinned, Target: root}
+					return nil, nil
+				})
+			tagged := images.Image{Name: imageRef, Target: root}
+			store.EXPECT().Update(gomock.Any(), tagged).Return(images.Image{}, cerrdefs.ErrNotFound)
+			store.EXPECT().Create(gomock.Any(), tagged).Return(tagged, nil)
+			store.EXPECT().Delete(gomock.Any(), pinned).Return(nil)
+
+			// service should return no error
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("should keep the record of the pinned reference if the image was pulled with it before", func() {
+			root := ocispec.Descriptor{Digest: digest.FromString("manifest")}
+			pinned := name + "@" + root.Digest.String()
+			records[pinned] = images.Image{Name: pinned, Target: root}
+			records[imageRef] = images.Image{Name: imageRef, Target: ocispec.Descriptor{Digest: digest.FromString("old")}}
+			verified = root.Digest
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				&mockResolver{root: root}, nil, nil,
+			)
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, gomock.Any(), pinned, []ocispec.Platform{ociPlatform}, "").Return(
+				nil, nil,
+			)
+			tagged := images.Image{Name: imageRef, Target: root}
+			store.EXPECT().Update(gomock.Any(), tagged).Return(tagged, nil)
+
+			// service should return no error
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("should return a forbidden error if the image fails verification", func() {
+			verifyErr = fmt.Errorf("no matching signatures")
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				resolver, nil, nil,
+			)
+
+			// service should return forbidden error before writing any progress
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(errdefs.IsForbiddenError(err)).Should(BeTrue())
+			Expect(err.Error()).Should(ContainSubstring("no matching signatures"))
+			Expect(out.Len()).Should(BeZero())
+		})
+		It("should return a forbidden error if the image was verified at another digest", func() {
+			verified = digest.FromString("other")
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(
+				&mockResolver{root: ocispec.Descriptor{Digest: digest.FromString("manifest")}}, nil, nil,
+			)
+
+			// service should return forbidden error
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(errdefs.IsForbiddenError(err)).Should(BeTrue())
+		})
+		It("should stream the progress of the layers followed by the digest and the status", func() {
+			root := ocispec.Descriptor{Digest: digest.FromString("manifest")}
+			layer := ocispec.Descriptor{Digest: digest.FromString("layer"), Size: 10}
+			resolver = &mockResolver{root: root}
+			cdClient.EXPECT().DefaultPlatformSpec().Return(ociPlatform)
+			cdClient.EXPECT().ParseDockerRef(imageRef).Return(imageRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Nil()).Return(resolver, nil, nil)
+			ncClient.EXPECT().PullImage(gomock.Any(), nil, nil, resolver, imageRef, []ocispec.Platform{ociPlatform}, "").Return(nil, nil)
+			// the manifest is only fetched once the pull has started
+			polls := 0
+			progress = func() ([]backend.LayerProgress, error) {
+				if polls++; polls == 1 {
+					return nil, cerrdefs.ErrNotFound
+				}
+				return []backend.LayerProgress{{Descriptor: layer, Downloaded: true, Unpacked: true}}, nil
+			}
+
+			err := s.Pull(ctx, name, tag, "", "", nil, out)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+				{ID: tag, Status: "Pulling from test-image/test-image"},
+				{ID: layerID(layer.Digest), Status: "Pulling fs layer"},
+				{ID: layerID(layer.Digest), Status: "Verifying Checksum"},
+				{ID: layerID(layer.Digest), Status: "Download complete"},
+				{ID: layerID(layer.Digest), Status: "Extracting"},
+				{ID: layerID(layer.Digest), Status: "Pull complete"},
+				{Status: "Digest: " + root.Digest.String()},
+				{Status: "Status: Downloaded newer image for " + imageRef},
+			}))
+			Expect(events()).Should(Equal([]*eventtype.Event{getImageEvent(pullEventAction, root.Digest, imageRef)}))
 		})
 	})
 })
+
+// decodeMessages decodes the JSON messages written to the buffer.
+func decodeMessages(buf *bytes.Buffer) []jsonmessage.JSONMessage {
+	var msgs []jsonmessage.JSONMessage
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var msg jsonmessage.JSONMessage
+		Expect(decoder.Decode(&msg)).Should(Succeed())
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
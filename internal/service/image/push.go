@@ -7,81 +7,209 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/containerd/containerd/v2/core/content"
 	"github.com/containerd/containerd/v2/core/images/converter"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/imgcrypt/v2/images/encryption"
+	"github.com/containerd/imgcrypt/v2/images/encryption/parsehelpers"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
 	dockertypes "github.com/docker/cli/cli/config/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 	"github.com/runfinch/finch-daemon/pkg/utility/imageutility"
 )
 
-func (s *service) Push(ctx context.Context, name, tag string, ac *dockertypes.AuthConfig, outStream io.Writer) (*types.PushResult, error) {
-	// Canonicalize and parse raw image reference as "image:tag" or "image@digest"
-	rawRef, err := imageutility.Canonicalize(name, tag)
+// Push pushes the image with the tag, or every tag of the repository if no tag is given, and writes the progress
+// of each push as JSON messages to outStream, followed by an aux message with the tag, digest and size of the
+// pushed manifest. If recipients are given, the layers are encrypted for them before they are pushed.
+func (s *service) Push(ctx context.Context, name, tag string, recipients []string, ac *dockertypes.AuthConfig, outStream io.Writer) error {
+	rawRefs, err := s.pushRefs(ctx, name, tag)
 	if err != nil {
-		return nil, errdefs.NewInvalidFormat(fmt.Errorf("failed to canonicalize the ref: %w", err))
+		return err
 	}
-	ref, refDomain, err := s.client.ParseDockerRef(rawRef)
+	var refs []string
+	var refDomain string
+	for _, rawRef := range rawRefs {
+		ref, domain, err := s.client.ParseDockerRef(rawRef)
+		if err != nil {
+			return errdefs.NewInvalidFormat(err)
+		}
+		refs = append(refs, ref)
+		refDomain = domain
+	}
+
+	// Get auth creds and the corresponding docker remotes resolver
+	var creds dockerconfigresolver.AuthCreds
+	if ac != nil {
+		creds, err = getAuthCredsFunc(refDomain, s.client, *ac)
+		if err != nil {
+			return err
+		}
+	}
+	resolver, tracker, err := s.nctlImageSvc.GetDockerResolver(ctx, refDomain, creds)
 	if err != nil {
-		return nil, errdefs.NewInvalidFormat(err)
+		return fmt.Errorf("failed to initialize remotes resolver: %s", err)
 	}
 
+	platMC := s.client.DefaultPlatformStrict()
+	convertOpts := []converter.Opt{converter.WithPlatform(platMC)}
+	if len(recipients) > 0 {
+		encrypt, err := encryptConvertFunc(recipients, platMC)
+		if err != nil {
+			return err
+		}
+		convertOpts = append(convertOpts, converter.WithIndexConvertFunc(encrypt))
+	}
+
+	progress := newPushProgress(outStream, tracker)
+	for i, ref := range refs {
+		if err := s.pushRef(ctx, ref, resolver, tracker, platMC, convertOpts, progress, i == 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushRefs returns the references to push, which are every tagged reference of the repository
+// if no tag is given, as `docker push --all-tags` does.
+func (s *service) pushRefs(ctx context.Context, name, tag string) ([]string, error) {
+	named, err := reference.ParseNormalizedNamed(name)
+	if tag != "" || err != nil || !reference.IsNameOnly(named) {
+		// Canonicalize and parse raw image reference as "image:tag" or "image@digest"
+		rawRef, err := imageutility.Canonicalize(name, tag)
+		if err != nil {
+			return nil, errdefs.NewInvalidFormat(fmt.Errorf("failed to canonicalize the ref: %w", err))
+		}
+		return []string{rawRef}, nil
+	}
+
+	imgs, err := s.client.ImageService().List(ctx, fmt.Sprintf("name~=^%s:", regexp.QuoteMeta(named.Name())))
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, img := range imgs {
+		ref, err := reference.ParseNormalizedNamed(img.Name)
+		if err != nil || ref.Name() != named.Name() {
+			continue
+		}
+		if _, ok := ref.(reference.NamedTagged); ok {
+			refs = append(refs, ref.String())
+		}
+	}
+	if len(refs) == 0 {
+		return nil, errdefs.NewNotFound(fmt.Errorf("no tags of repository %s found", name))
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+// pushRef pushes the image of the reference, reporting the progress of its layers and the pushed manifest.
+func (s *service) pushRef(ctx context.Context, ref string, resolver remotes.Resolver, tracker docker.StatusTracker,
+	platMC platforms.MatchComparer, convertOpts []converter.Opt, progress *pushProgress, first bool,
+) error {
 	// Create a reduced platform image locally to avoid "400 Bad request" for multi-platform manifests
 	// https://github.com/containerd/nerdctl/blob/v1.7.2/pkg/cmd/image/push.go#L93-L111
-	platMC := s.client.DefaultPlatformStrict()
+	// The layers of the image are also encrypted by the conversion if recipients are given.
 	pushRef := ref + "-tmp-reduced-platform"
-	platImg, err := s.client.ConvertImage(ctx, pushRef, ref, converter.WithPlatform(platMC))
+	platImg, err := s.client.ConvertImage(ctx, pushRef, ref, convertOpts...)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return nil, errdefs.NewNotFound(err)
+			return errdefs.NewNotFound(err)
 		}
-		return nil, fmt.Errorf("failed to create a tmp single-platform image %q: %s", pushRef, err)
-	}
-	if platImg != nil {
-		defer s.client.DeleteImage(ctx, platImg.Name)
+		return fmt.Errorf("failed to create a tmp single-platform image %q: %s", pushRef, err)
 	}
+	defer s.client.DeleteImage(ctx, platImg.Name)
 	s.logger.Debugf("pushing as a reduced-platform image (%s, %s)", platImg.Target.MediaType, platImg.Target.Digest)
 
-	// Get auth creds and the corresponding docker remotes resolver
-	var creds dockerconfigresolver.AuthCreds
-	if ac != nil {
-		creds, err = getAuthCredsFunc(refDomain, s.client, *ac)
-		if err != nil {
-			return nil, err
-		}
-	}
-	resolver, tracker, err := s.nctlImageSvc.GetDockerResolver(ctx, refDomain, creds)
+	layers, err := s.client.GetImageLayers(ctx, platImg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize remotes resolver: %s", err)
+		return fmt.Errorf("failed to get the layers of image %q: %w", ref, err)
+	}
+	if first {
+		progress.start(ref)
 	}
+	progress.prepare(ctx, layers)
+
+	// poll the progress of the layers while pushing, as the push does not report it.
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		ticker := time.NewTicker(pushPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+				progress.update()
+			}
+		}
+	}()
 
 	// finally, push the image
-	if err = s.nctlImageSvc.PushImage(
+	err = s.nctlImageSvc.PushImage(
 		ctx,
 		resolver,
 		tracker,
-		outStream,
+		nil,
 		pushRef, ref,
 		platMC,
-	); err != nil {
-		return nil, err
+	)
+	stopPolling()
+	<-polled
+	if err != nil {
+		return err
 	}
 
-	// send aux information for the pushed image
-	img, err := s.client.GetImage(ctx, ref)
-	if err != nil {
-		return nil, nil
+	progress.update()
+	tag := ""
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		if tagged, ok := named.(reference.Tagged); ok {
+			tag = tagged.Tag()
+		}
 	}
-	size, err := img.Size(ctx)
+	progress.complete(types.PushResult{
+		Tag:    tag,
+		Digest: platImg.Target.Digest.String(),
+		Size:   int(platImg.Target.Size),
+	})
+	s.publishImageEvent(ctx, pushEventAction, platImg.Target.Digest, familiarName(ref))
+	return nil
+}
+
+// encryptConvertFunc returns the conversion encrypting the layers of the image for the platform for the recipients,
+// which are given as `nerdctl image encrypt --recipient`, e.g. "jwe:/path/to/pubkey.pem".
+// Adapted from https://github.com/containerd/nerdctl/blob/v2.2.2/pkg/cmd/image/crypt.go
+func encryptConvertFunc(recipients []string, platMC platforms.MatchComparer) (converter.ConvertFunc, error) {
+	cc, err := parsehelpers.CreateCryptoConfig(parsehelpers.EncArgs{Recipient: recipients}, nil)
 	if err != nil {
-		return nil, nil
+		return nil, errdefs.NewInvalidFormat(fmt.Errorf("failed to load the encryption recipients: %w", err))
 	}
-	return &types.PushResult{
-		Tag:    tag,
-		Digest: img.Target().Digest.String(),
-		Size:   int(size),
+	if cc.EncryptConfig == nil || len(cc.EncryptConfig.Parameters) == 0 {
+		return nil, errdefs.NewInvalidFormat(fmt.Errorf("no key to encrypt the image with was found for the recipients %v", recipients))
+	}
+	platformConvert := converter.DefaultIndexConvertFunc(nil, false, platMC)
+	encrypt := encryption.GetImageEncryptConverter(&cc, func(ocispec.Descriptor) bool { return true })
+	return func(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Descriptor, error) {
+		newDesc, err := platformConvert(ctx, cs, desc)
+		if err != nil {
+			return nil, err
+		}
+		if newDesc != nil {
+			desc = *newDesc
+		}
+		return encrypt(ctx, cs, desc)
 	}, nil
 }
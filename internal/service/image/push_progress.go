@@ -0,0 +1,128 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/pkg/jsonmessage"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/runfinch/finch-daemon/api/types"
+)
+
+// pushPollInterval is the interval at which the progress of the layers is polled during a push.
+const pushPollInterval = 100 * time.Millisecond
+
+// pushLayer is a layer being pushed, identified by the key the push tracks its status with.
+type pushLayer struct {
+	desc    ocispec.Descriptor
+	key     string
+	started bool
+	offset  int64
+	done    bool
+}
+
+// pushProgress reports the progress of an image push as the JSON messages dockerd streams,
+// from the status tracker of the push.
+type pushProgress struct {
+	encoder *json.Encoder
+	tracker docker.StatusTracker
+	layers  []*pushLayer
+}
+
+func newPushProgress(w io.Writer, tracker docker.StatusTracker) *pushProgress {
+	return &pushProgress{
+		encoder: json.NewEncoder(w),
+		tracker: tracker,
+	}
+}
+
+// write writes a message, ignoring errors as the push goes on even if the client has gone away.
+func (p *pushProgress) write(msg jsonmessage.JSONMessage) {
+	_ = p.encoder.Encode(msg)
+}
+
+// start reports the repository the push refers to.
+func (p *pushProgress) start(ref string) {
+	repo := ref
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		repo = named.Name()
+	}
+	p.write(jsonmessage.JSONMessage{Status: fmt.Sprintf("The push refers to repository [%s]", repo)})
+}
+
+// prepare reports the layers of the image to push as preparing, except for the layers already pushed,
+// e.g. by the push of another tag. Non-distributable layers are not pushed, so they are not reported.
+func (p *pushProgress) prepare(ctx context.Context, layers []ocispec.Descriptor) {
+	p.layers = nil
+	for _, desc := range layers {
+		if images.IsNonDistributable(desc.MediaType) {
+			continue
+		}
+		layer := &pushLayer{desc: desc, key: remotes.MakeRefKey(ctx, desc)}
+		p.layers = append(p.layers, layer)
+		if status, err := p.tracker.GetStatus(layer.key); err == nil && status.Committed {
+			layer.done = true
+			p.write(jsonmessage.JSONMessage{ID: layerID(desc.Digest), Status: "Layer already exists"})
+			continue
+		}
+		p.write(jsonmessage.JSONMessage{ID: layerID(desc.Digest), Status: "Preparing"})
+	}
+}
+
+// update reports the changes in the status of the layers.
+func (p *pushProgress) update() {
+	for _, layer := range p.layers {
+		if layer.done {
+			continue
+		}
+		status, err := p.tracker.GetStatus(layer.key)
+		if err != nil {
+			// the push of the layer has not started yet.
+			continue
+		}
+		id := layerID(layer.desc.Digest)
+		switch {
+		case status.Committed && status.Exists:
+			layer.done = true
+			p.write(jsonmessage.JSONMessage{ID: id, Status: "Layer already exists"})
+		case status.Committed && status.MountedFrom != "":
+			layer.done = true
+			p.write(jsonmessage.JSONMessage{ID: id, Status: "Mounted from " + status.MountedFrom})
+		case status.Committed:
+			layer.done = true
+			p.write(jsonmessage.JSONMessage{ID: id, Status: "Pushed"})
+		case !layer.started || status.Offset != layer.offset:
+			progress := &jsonmessage.JSONProgress{Current: status.Offset, Total: layer.desc.Size}
+			p.write(jsonmessage.JSONMessage{
+				ID:              id,
+				Status:          "Pushing",
+				Progress:        progress,
+				ProgressMessage: progress.String(),
+			})
+			layer.started = true
+			layer.offset = status.Offset
+		}
+	}
+}
+
+// complete reports the manifest pushed for the tag, followed by the aux message with the push result.
+func (p *pushProgress) complete(result types.PushResult) {
+	p.write(jsonmessage.JSONMessage{Status: fmt.Sprintf("%s: digest: %s size: %d", result.Tag, result.Digest, result.Size)})
+	aux, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	raw := json.RawMessage(aux)
+	p.write(jsonmessage.JSONMessage{Progress: &jsonmessage.JSONProgress{}, Aux: &raw})
+}
@@ -4,26 +4,38 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 
-	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/content"
 	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/images/converter"
 	"github.com/containerd/containerd/v2/core/remotes"
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/platforms"
 	dockertypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"go.uber.org/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/api/handlers/image"
 	"github.com/runfinch/finch-daemon/api/types"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
@@ -35,17 +47,21 @@ var _ = Describe("Image Push API ", func() {
 		mockCtrl  *gomock.Controller
 		logger    *mocks_logger.Logger
 		cdClient  *mocks_backend.MockContainerdClient
+		events    func() []*eventtype.Event
 		ncClient  *mocks_backend.MockNerdctlImageSvc
+		store     *mocks_image.MockStore
 		name      string
 		tag       string
-		digest    string
 		domain    string
 		rawRef    string
 		pushRef   string
+		pushImage *images.Image
+		layer     ocispec.Descriptor
 		authCfg   dockertypes.AuthConfig
 		authCreds dockerconfigresolver.AuthCreds
 		resolver  remotes.Resolver
 		tracker   docker.StatusTracker
+		out       *bytes.Buffer
 		service   image.Service
 	)
 	BeforeEach(func() {
@@ -54,13 +70,23 @@ var _ = Describe("Image Push API ", func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		events = recordEvents(cdClient)
 		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
+		store = mocks_image.NewMockStore(mockCtrl)
 		name = "public.ecr.aws/test-image/test-image"
-		digest = "test-digest"
 		tag = "test-tag"
 		domain = "public.ecr.aws"
 		rawRef = fmt.Sprintf("%s:%s", name, tag)
-		pushRef = fmt.Sprintf("%s-tmp-reduced-platform", name)
+		pushRef = fmt.Sprintf("%s-tmp-reduced-platform", rawRef)
+		pushImage = &images.Image{
+			Name:   pushRef,
+			Target: ocispec.Descriptor{Digest: digest.FromString("manifest"), Size: 256},
+		}
+		layer = ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageLayerGzip,
+			Digest:    digest.FromString("layer"),
+			Size:      100,
+		}
 		authCfg = dockertypes.AuthConfig{
 			Username: "test-user",
 			Password: "test-password",
@@ -70,232 +96,254 @@ var _ = Describe("Image Push API ", func() {
 		}
 		resolver = &mockResolver{}
 		tracker = docker.NewInMemoryTracker()
+		out = &bytes.Buffer{}
 
+		cdClient.EXPECT().ImageService().Return(store).AnyTimes()
 		service = NewService(cdClient, ncClient, logger)
 
 		logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
 	})
+	// expectPush expects the push of the reference, which sets the status of the layer in the tracker.
+	expectPush := func(ref string, status docker.Status) {
+		refPushImage := &images.Image{Name: ref + "-tmp-reduced-platform", Target: pushImage.Target}
+		cdClient.EXPECT().ConvertImage(gomock.Any(), refPushImage.Name, ref, gomock.Any()).
+			Return(refPushImage, nil)
+		cdClient.EXPECT().GetImageLayers(gomock.Any(), refPushImage).
+			Return([]ocispec.Descriptor{layer}, nil)
+		ncClient.EXPECT().PushImage(gomock.Any(), resolver, tracker, nil, refPushImage.Name, ref, nil).
+			DoAndReturn(func(ctx context.Context, _ remotes.Resolver, _ docker.StatusTracker, _ io.Writer,
+				_, _ string, _ platforms.MatchComparer,
+			) error {
+				tracker.SetStatus(remotes.MakeRefKey(ctx, layer), status)
+				return nil
+			})
+		cdClient.EXPECT().DeleteImage(gomock.Any(), refPushImage.Name).
+			Return(nil)
+	}
+	auxMessage := func(result types.PushResult) jsonmessage.JSONMessage {
+		aux, err := json.Marshal(result)
+		Expect(err).ShouldNot(HaveOccurred())
+		raw := json.RawMessage(aux)
+		return jsonmessage.JSONMessage{Progress: &jsonmessage.JSONProgress{}, Aux: &raw}
+	}
 	Context("service", func() {
-		It("should return no errors upon success", func() {
-			pushImage := &images.Image{Name: pushRef}
-			image := &mockImage{
-				ImageName:   name,
-				ImageDigest: digest,
-				ImageTag:    tag,
-				ImageSize:   256,
-			}
-			expected := types.PushResult{
-				Tag:    tag,
-				Digest: digest,
-				Size:   256,
-			}
+		It("should report the progress of the layers and the push result upon success", func() {
+			id := layerID(layer.Digest)
 
 			// expected backend calls
 			cdClient.EXPECT().ParseDockerRef(rawRef).
-				Return(name, domain, nil)
-			cdClient.EXPECT().DefaultPlatformStrict().
-				Return(nil)
-			cdClient.EXPECT().ConvertImage(gomock.Any(), pushImage.Name, name, gomock.Any()).
-				Return(pushImage, nil)
-			cdClient.EXPECT().DeleteImage(gomock.Any(), pushImage.Name).
-				Return(nil)
+				Return(rawRef, domain, nil)
 			expectGetAuthCreds(mockCtrl, domain, authCfg).
 				Return(authCreds, nil)
 			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Not(gomock.Nil())).
 				Return(resolver, tracker, nil)
-			ncClient.EXPECT().PushImage(gomock.Any(), resolver, tracker, nil, pushImage.Name, name, nil).
+			cdClient.EXPECT().DefaultPlatformStrict().
 				Return(nil)
-			cdClient.EXPECT().GetImage(gomock.Any(), name).
-				Return(image, nil)
+			expectPush(rawRef, docker.Status{Status: content.Status{Offset: layer.Size}, Committed: true})
 
 			// service should return no error
-			result, err := service.Push(ctx, name, tag, &authCfg, nil)
+			err := service.Push(ctx, name, tag, nil, &authCfg, out)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+				{Status: "The push refers to repository [public.ecr.aws/test-image/test-image]"},
+				{ID: id, Status: "Preparing"},
+				{ID: id, Status: "Pushed"},
+				{Status: fmt.Sprintf("%s: digest: %s size: 256", tag, pushImage.Target.Digest)},
+				auxMessage(types.PushResult{Tag: tag, Digest: pushImage.Target.Digest.String(), Size: 256}),
+			}))
+		})
+		It("should push every tag of the repository if no tag is given", func() {
+			id := layerID(layer.Digest)
+			otherRef := name + ":other-tag"
+
+			// expected backend calls
+			store.EXPECT().List(gomock.Any(), `name~=^public\.ecr\.aws/test-image/test-image:`).
+				Return([]images.Image{{Name: rawRef}, {Name: otherRef}, {Name: name + "-other:latest"}}, nil)
+			cdClient.EXPECT().ParseDockerRef(otherRef).
+				Return(otherRef, domain, nil)
+			cdClient.EXPECT().ParseDockerRef(rawRef).
+				Return(rawRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, nil).
+				Return(resolver, tracker, nil)
+			cdClient.EXPECT().DefaultPlatformStrict().
+				Return(nil)
+			expectPush(otherRef, docker.Status{Committed: true, PushStatus: docker.PushStatus{Exists: true}})
+			expectPush(rawRef, docker.Status{Committed: true, PushStatus: docker.PushStatus{Exists: true}})
+
+			// service should push the tags in order
+			err := service.Push(ctx, name, "", nil, nil, out)
 			Expect(err).ShouldNot(HaveOccurred())
-			Expect(*result).Should(Equal(expected))
+			Expect(decodeMessages(out)).Should(Equal([]jsonmessage.JSONMessage{
+				{Status: "The push refers to repository [public.ecr.aws/test-image/test-image]"},
+				{ID: id, Status: "Preparing"},
+				{ID: id, Status: "Layer already exists"},
+				{Status: fmt.Sprintf("other-tag: digest: %s size: 256", pushImage.Target.Digest)},
+				auxMessage(types.PushResult{Tag: "other-tag", Digest: pushImage.Target.Digest.String(), Size: 256}),
+				{ID: id, Status: "Layer already exists"},
+				{Status: fmt.Sprintf("%s: digest: %s size: 256", tag, pushImage.Target.Digest)},
+				auxMessage(types.PushResult{Tag: tag, Digest: pushImage.Target.Digest.String(), Size: 256}),
+			}))
+			Expect(events()).Should(Equal([]*eventtype.Event{
+				getImageEvent(pushEventAction, pushImage.Target.Digest, otherRef),
+				getImageEvent(pushEventAction, pushImage.Target.Digest, rawRef),
+			}))
+		})
+		It("should return a NotFound error if the repository has no tags", func() {
+			store.EXPECT().List(gomock.Any(), gomock.Any()).
+				Return([]images.Image{}, nil)
+
+			// service should return not found error
+			err := service.Push(ctx, name, "", nil, &authCfg, out)
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
+			Expect(out.Len()).Should(BeZero())
+		})
+		It("should encrypt the layers for the recipients", func() {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ShouldNot(HaveOccurred())
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).ShouldNot(HaveOccurred())
+			pubKey := filepath.Join(GinkgoT().TempDir(), "pubkey.pem")
+			Expect(os.WriteFile(pubKey, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600)).Should(Succeed())
+
+			cdClient.EXPECT().ParseDockerRef(rawRef).
+				Return(rawRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, nil).
+				Return(resolver, tracker, nil)
+			cdClient.EXPECT().DefaultPlatformStrict().
+				Return(nil)
+			// the image is converted for the platform and encrypted
+			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, rawRef, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _ string, opts ...converter.Opt) (*images.Image, error) {
+					Expect(opts).Should(HaveLen(2))
+					return pushImage, nil
+				})
+			cdClient.EXPECT().GetImageLayers(gomock.Any(), pushImage).
+				Return([]ocispec.Descriptor{layer}, nil)
+			ncClient.EXPECT().PushImage(gomock.Any(), resolver, tracker, nil, pushRef, rawRef, nil).
+				Return(nil)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), pushRef).
+				Return(nil)
+
+			err = service.Push(ctx, name, tag, []string{"jwe:" + pubKey}, nil, out)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("should return an invalid format error if a recipient key is missing", func() {
+			cdClient.EXPECT().ParseDockerRef(rawRef).
+				Return(rawRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, nil).
+				Return(resolver, tracker, nil)
+			cdClient.EXPECT().DefaultPlatformStrict().
+				Return(nil)
+
+			// nothing should be pushed or written
+			err := service.Push(ctx, name, tag, []string{"jwe:/missing/pubkey.pem"}, nil, out)
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
+			Expect(err.Error()).Should(ContainSubstring("failed to load the encryption recipients"))
+			Expect(out.Len()).Should(BeZero())
 		})
 		It("should return error due to malformed name", func() {
 			// service should return error
-			result, err := service.Push(ctx, "malformed:/image:name", "malformed:tag", &authCfg, nil)
-			Expect(err).Should(HaveOccurred())
-			Expect(result).Should(BeNil())
+			err := service.Push(ctx, "malformed:/image:name", "malformed:tag", nil, &authCfg, out)
+			Expect(errdefs.IsInvalidFormat(err)).Should(BeTrue())
 		})
 		It("should return an error if image reference is invalid", func() {
 			expectedError := fmt.Errorf("invalid image reference")
 
 			// expected backend calls
 			cdClient.EXPECT().ParseDockerRef(rawRef).
-				Return("", "", fmt.Errorf("invalid image reference"))
+				Return("", "", expectedError)
 
 			// service should return invalid reference error
-			result, err := service.Push(ctx, name, tag, &authCfg, nil)
+			err := service.Push(ctx, name, tag, nil, &authCfg, out)
 			Expect(err.Error()).Should(ContainSubstring(expectedError.Error()))
-			Expect(result).Should(BeNil())
 		})
-		It("should return errors due to image conversion", func() {
-			expectedError := fmt.Errorf("convert image failed")
+		It("should return an error if credentials are invalid", func() {
+			expectedError := fmt.Errorf("invalid credentials")
 
 			// expected backend calls
 			cdClient.EXPECT().ParseDockerRef(rawRef).
-				Return(name, domain, nil)
-			cdClient.EXPECT().DefaultPlatformStrict().
-				Return(nil)
-			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, name, gomock.Any()).
+				Return(rawRef, domain, nil)
+			expectGetAuthCreds(mockCtrl, domain, authCfg).
 				Return(nil, expectedError)
 
 			// service should return error
-			result, err := service.Push(ctx, name, tag, &authCfg, nil)
+			err := service.Push(ctx, name, tag, nil, &authCfg, out)
 			Expect(err.Error()).Should(ContainSubstring(expectedError.Error()))
-			Expect(result).Should(BeNil())
 		})
-		It("should return a not found errors if image does not exist", func() {
-			expectedError := fmt.Errorf("image `%s`: not found", name)
+		It("should fail due to resolver error", func() {
+			expectedError := fmt.Errorf("resolver error")
 
 			// expected backend calls
 			cdClient.EXPECT().ParseDockerRef(rawRef).
-				Return(name, domain, nil)
-			cdClient.EXPECT().DefaultPlatformStrict().
-				Return(nil)
-			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, name, gomock.Any()).
-				Return(nil, expectedError)
+				Return(rawRef, domain, nil)
+			expectGetAuthCreds(mockCtrl, domain, authCfg).
+				Return(authCreds, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Not(gomock.Nil())).
+				Return(nil, nil, expectedError)
 
 			// service should return error
-			result, err := service.Push(ctx, name, tag, &authCfg, nil)
-			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
-			Expect(result).Should(BeNil())
+			err := service.Push(ctx, name, tag, nil, &authCfg, out)
+			Expect(err.Error()).Should(ContainSubstring(expectedError.Error()))
 		})
-		It("should return an error if credentials are invalid", func() {
-			pushImage := &images.Image{Name: pushRef}
-			expectedError := fmt.Errorf("invalid credentials")
+		It("should return errors due to image conversion", func() {
+			expectedError := fmt.Errorf("convert image failed")
 
 			// expected backend calls
 			cdClient.EXPECT().ParseDockerRef(rawRef).
-				Return(name, domain, nil)
+				Return(rawRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, nil).
+				Return(resolver, tracker, nil)
 			cdClient.EXPECT().DefaultPlatformStrict().
 				Return(nil)
-			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, name, gomock.Any()).
-				Return(pushImage, nil)
-			cdClient.EXPECT().DeleteImage(gomock.Any(), pushImage.Name).
-				Return(nil)
-			expectGetAuthCreds(mockCtrl, domain, authCfg).
+			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, rawRef, gomock.Any()).
 				Return(nil, expectedError)
 
-			// service should return error
-			result, err := service.Push(ctx, name, tag, &authCfg, nil)
+			// service should return error before writing any progress
+			err := service.Push(ctx, name, tag, nil, nil, out)
 			Expect(err.Error()).Should(ContainSubstring(expectedError.Error()))
-			Expect(result).Should(BeNil())
+			Expect(out.Len()).Should(BeZero())
 		})
-		It("should fail due to resolver error", func() {
-			pushImage := &images.Image{Name: pushRef}
-			expectedError := fmt.Errorf("resolver error")
+		It("should return a not found errors if image does not exist", func() {
+			expectedError := fmt.Errorf("image `%s`: not found", rawRef)
 
 			// expected backend calls
 			cdClient.EXPECT().ParseDockerRef(rawRef).
-				Return(name, domain, nil)
+				Return(rawRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, nil).
+				Return(resolver, tracker, nil)
 			cdClient.EXPECT().DefaultPlatformStrict().
 				Return(nil)
-			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, name, gomock.Any()).
-				Return(pushImage, nil)
-			cdClient.EXPECT().DeleteImage(gomock.Any(), pushImage.Name).
-				Return(nil)
-			expectGetAuthCreds(mockCtrl, domain, authCfg).
-				Return(authCreds, nil)
-			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Not(gomock.Nil())).
-				Return(nil, nil, expectedError)
+			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, rawRef, gomock.Any()).
+				Return(nil, expectedError)
 
 			// service should return error
-			result, err := service.Push(ctx, name, tag, &authCfg, nil)
-			Expect(err.Error()).Should(ContainSubstring(expectedError.Error()))
-			Expect(result).Should(BeNil())
+			err := service.Push(ctx, name, tag, nil, nil, out)
+			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 		})
 		It("should return an error upon service failure", func() {
-			pushImage := &images.Image{Name: pushRef}
 			expectedError := fmt.Errorf("failed to push image")
 
 			// expected backend calls
 			cdClient.EXPECT().ParseDockerRef(rawRef).
-				Return(name, domain, nil)
+				Return(rawRef, domain, nil)
+			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, nil).
+				Return(resolver, tracker, nil)
 			cdClient.EXPECT().DefaultPlatformStrict().
 				Return(nil)
-			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, name, gomock.Any()).
+			cdClient.EXPECT().ConvertImage(gomock.Any(), pushRef, rawRef, gomock.Any()).
 				Return(pushImage, nil)
-			cdClient.EXPECT().DeleteImage(gomock.Any(), pushImage.Name).
-				Return(nil)
-			expectGetAuthCreds(mockCtrl, domain, authCfg).
-				Return(authCreds, nil)
-			ncClient.EXPECT().GetDockerResolver(gomock.Any(), domain, gomock.Not(gomock.Nil())).
-				Return(resolver, tracker, nil)
-			ncClient.EXPECT().PushImage(gomock.Any(), resolver, tracker, nil, pushImage.Name, name, nil).
+			cdClient.EXPECT().GetImageLayers(gomock.Any(), pushImage).
+				Return([]ocispec.Descriptor{layer}, nil)
+			ncClient.EXPECT().PushImage(gomock.Any(), resolver, tracker, nil, pushRef, rawRef, nil).
 				Return(expectedError)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), pushRef).
+				Return(nil)
 
 			// service should return error
-			result, err := service.Push(ctx, name, tag, &authCfg, nil)
+			err := service.Push(ctx, name, tag, nil, nil, out)
 			Expect(err.Error()).Should(ContainSubstring(expectedError.Error()))
-			Expect(result).Should(BeNil())
 		})
 	})
 
 	// TODO: need to add an authenticated push unit test.
 })
-
-// dummy containerd image.
-type mockImage struct {
-	ImageName   string
-	ImageDigest string
-	ImageTag    string
-	ImageSize   int64
-}
-
-func (m *mockImage) Name() string {
-	return m.ImageName
-}
-
-func (m *mockImage) Target() ocispec.Descriptor {
-	return ocispec.Descriptor{Digest: digest.Digest(m.ImageDigest), Size: m.ImageSize}
-}
-
-func (m *mockImage) Labels() map[string]string {
-	return nil
-}
-
-func (m *mockImage) Unpack(context.Context, string, ...containerd.UnpackOpt) error {
-	return nil
-}
-
-func (m *mockImage) RootFS(context.Context) ([]digest.Digest, error) {
-	return nil, nil
-}
-
-func (m *mockImage) Size(context.Context) (int64, error) {
-	return m.ImageSize, nil
-}
-
-func (m *mockImage) Usage(context.Context, ...containerd.UsageOpt) (int64, error) {
-	return 0, nil
-}
-
-func (m *mockImage) Config(context.Context) (ocispec.Descriptor, error) {
-	return ocispec.Descriptor{Digest: digest.Digest(m.ImageDigest), Size: m.ImageSize}, nil
-}
-
-func (m *mockImage) IsUnpacked(context.Context, string) (bool, error) {
-	return false, nil
-}
-
-func (m *mockImage) ContentStore() content.Store {
-	return nil
-}
-
-func (m *mockImage) Metadata() images.Image {
-	return images.Image{
-		Name:   m.ImageName,
-		Target: ocispec.Descriptor{Digest: digest.Digest(m.ImageDigest), Size: m.ImageSize},
-	}
-}
-
-func (m *mockImage) Platform() platforms.MatchComparer {
-	return nil
-}
-
-func (m *mockImage) Spec(context.Context) (ocispec.Image, error) {
-	return ocispec.Image{}, nil
-}
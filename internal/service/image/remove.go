@@ -6,30 +6,58 @@ package image
 import (
 	"context"
 	"fmt"
+	"slices"
+
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
-func (s *service) Remove(ctx context.Context, name string, force bool) (deleted, untagged []string, err error) {
+// Remove removes the image with the name, which is either a reference or an image ID. Removing a reference
+// of an image with other references only untags it. Otherwise, the image is deleted, which garbage collects
+// its content and snapshots that are not used by other images, and unless noprune is set, its dangling
+// parent images are deleted too.
+func (s *service) Remove(ctx context.Context, name string, force, noprune bool) (untagged, deleted []string, err error) {
 	matchCount, uniqueCount, imgs, err := s.nctlImageSvc.SearchImage(ctx, name)
 	if err != nil {
-		return
+		return nil, nil, err
 	}
 	if matchCount == 0 {
-		err = errdefs.NewNotFound(fmt.Errorf("no such image: %s", name))
-		return
+		return nil, nil, errdefs.NewNotFound(fmt.Errorf("no such image: %s", name))
+	}
+	if uniqueCount > 1 {
+		return nil, nil, errdefs.NewConflict(fmt.Errorf(
+			"unable to delete %s - ambiguous ID which matches multiple images", name))
+	}
+
+	target := imgs[0].Target.Digest
+	records, err := s.client.ImageService().List(ctx, fmt.Sprintf("target.digest==%s", target))
+	if err != nil {
+		return nil, nil, err
 	}
-	if matchCount > 1 && !(force && uniqueCount == 1) {
-		err = errdefs.NewConflict(fmt.Errorf(
+
+	// removing one of several references of the image only untags it.
+	if ref := findReference(name, imgs); ref != nil && len(records) > 1 {
+		if err := s.client.DeleteImage(ctx, ref.Name); err != nil {
+			return nil, nil, err
+		}
+		untagged = untaggedNames([]images.Image{*ref}, target)
+		s.publishRemoveEvents(ctx, target, untagged, nil)
+		return untagged, []string{}, nil
+	}
+
+	if len(records) > 1 && !force {
+		return nil, nil, errdefs.NewConflict(fmt.Errorf(
 			"unable to delete %s (must be forced) - image is referenced in multiple repositories", name))
-		return
 	}
 	// check if the image can be deleted
 	stoppedImgs, runningImgs, err := s.client.GetUsedImages(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	for _, img := range imgs {
+	for _, img := range records {
 		if cid, ok := runningImgs[img.Name]; ok {
 			err = fmt.Errorf("unable to delete %s (cannot be forced) - image is being used by running container %s", name, cid)
 			return nil, nil, errdefs.NewConflict(err)
@@ -40,25 +68,151 @@ func (s *service) Remove(ctx context.Context, name string, force bool) (deleted,
 		}
 	}
 
-	deleted = []string{}
-	untagged = []string{}
+	// the layers have to be read before the content of the image is garbage collected.
+	var diffIDs []digest.Digest
+	if !noprune {
+		if diffIDs, err = s.client.GetImageDigests(ctx, &records[0]); err != nil {
+			s.logger.Warnf("Failed to enumerate rootfs, parent images will not be pruned. Error: %s", err)
+			noprune = true
+		}
+	}
+
+	if err := s.deleteRecords(ctx, records); err != nil {
+		return nil, nil, err
+	}
+	untagged = untaggedNames(records, target)
+	deleted = []string{target.String()}
+	if !noprune {
+		deleted = append(deleted, s.pruneParents(ctx, diffIDs, stoppedImgs, runningImgs)...)
+	}
+	s.publishRemoveEvents(ctx, target, untagged, deleted)
+	return untagged, deleted, nil
+}
+
+// publishRemoveEvents publishes an untag event for every untagged name of the image with the target, and a delete
+// event for every deleted image, which is named with its ID as dockerd does.
+func (s *service) publishRemoveEvents(ctx context.Context, target digest.Digest, untagged, deleted []string) {
+	for _, name := range untagged {
+		s.publishImageEvent(ctx, untagEventAction, target, name)
+	}
+	for _, id := range deleted {
+		s.publishImageEvent(ctx, deleteEventAction, digest.Digest(id), id)
+	}
+}
+
+// findReference returns the record of the image whose name is the reference name refers to, if any.
+func findReference(name string, imgs []*images.Image) *images.Image {
+	named, err := reference.ParseDockerRef(name)
+	if err != nil {
+		return nil
+	}
 	for _, img := range imgs {
-		digests, err := s.client.GetImageDigests(ctx, img)
+		if img.Name == named.String() {
+			return img
+		}
+	}
+	return nil
+}
+
+// untaggedNames returns the familiar names of the records, skipping the records of dangling images,
+// which are named after the target digest.
+func untaggedNames(records []images.Image, target digest.Digest) []string {
+	names := []string{}
+	for _, img := range records {
+		if img.Name == target.String() {
+			continue
+		}
+		named, err := reference.ParseNormalizedNamed(img.Name)
 		if err != nil {
-			s.logger.Warnf("Failed to enumerate rootfs. Error: %s", err)
+			names = append(names, img.Name)
+			continue
+		}
+		names = append(names, reference.FamiliarString(named))
+	}
+	return names
+}
+
+// deleteRecords deletes the records of an image. The deletion of the last record garbage collects the content
+// and the snapshots of the image which are not referenced anymore.
+func (s *service) deleteRecords(ctx context.Context, records []images.Image) error {
+	for _, img := range records {
+		if err := s.client.DeleteImage(ctx, img.Name); err != nil {
+			return err
 		}
-		err = s.client.DeleteImage(ctx, img.Name)
+	}
+	return nil
+}
+
+// pruneParents deletes the dangling parent images of a deleted image, i.e. the images whose layers are
+// a prefix of its layers, from the closest one up to the first parent which is tagged or used by a container.
+// It returns the IDs of the deleted parents. Failing to delete a parent does not fail the removal.
+func (s *service) pruneParents(ctx context.Context, diffIDs []digest.Digest, stoppedImgs, runningImgs map[string]string) []string {
+	all, err := s.client.ImageService().List(ctx)
+	if err != nil {
+		s.logger.Warnf("Failed to list images, parent images will not be pruned. Error: %s", err)
+		return nil
+	}
+	var targets []digest.Digest
+	groups := make(map[digest.Digest][]images.Image)
+	for _, img := range all {
+		target := img.Target.Digest
+		if _, ok := groups[target]; !ok {
+			targets = append(targets, target)
+		}
+		groups[target] = append(groups[target], img)
+	}
+
+	type parent struct {
+		target digest.Digest
+		layers int
+	}
+	var parents []parent
+	for _, target := range targets {
+		parentDiffIDs, err := s.client.GetImageDigests(ctx, &groups[target][0])
 		if err != nil {
-			return nil, nil, err
+			continue
+		}
+		if len(parentDiffIDs) > 0 && len(parentDiffIDs) < len(diffIDs) && slices.Equal(parentDiffIDs, diffIDs[:len(parentDiffIDs)]) {
+			parents = append(parents, parent{target: target, layers: len(parentDiffIDs)})
+		}
+	}
+	slices.SortStableFunc(parents, func(a, b parent) int { return b.layers - a.layers })
+
+	var deleted []string
+	for _, p := range parents {
+		records := groups[p.target]
+		if !isDangling(records, p.target) || isUsed(records, stoppedImgs, runningImgs) {
+			break
+		}
+		if err := s.deleteRecords(ctx, records); err != nil {
+			s.logger.Warnf("Failed to delete parent image %s. Error: %s", p.target, err)
+			break
 		}
+		deleted = append(deleted, p.target.String())
+	}
+	return deleted
+}
 
-		// TODO: a digest only gets deleted when all the images ref is deleted. Need to fix this later.
-		// Nerdctl also has the same problem. it also reports digest got deleted even there are other images
-		// reference to that digest.
-		for _, d := range digests {
-			deleted = append(deleted, d.String())
+// isDangling returns whether none of the records of an image is a reference. The images pulled by their digest are
+// named with a digested reference, so they are not dangling.
+func isDangling(records []images.Image, target digest.Digest) bool {
+	for _, img := range records {
+		if _, ok := imageReference(img, target); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isUsed returns whether any record of an image is used by a container.
+func isUsed(records []images.Image, stoppedImgs, runningImgs map[string]string) bool {
+	for _, img := range records {
+		if _, ok := stoppedImgs[img.Name]; ok {
+			return true
+		}
+		if _, ok := runningImgs[img.Name]; ok {
+			return true
 		}
-		untagged = append(untagged, fmt.Sprintf("%s:%s", img.Name, img.Target.Digest))
 	}
-	return untagged, deleted, err
+	return false
 }
@@ -5,6 +5,7 @@ package image
 
 import (
 	"context"
+	"errors"
 
 	"github.com/containerd/containerd/v2/core/images"
 	"go.uber.org/mock/gomock"
@@ -13,8 +14,10 @@ import (
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
+	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/api/handlers/image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_backend"
+	"github.com/runfinch/finch-daemon/mocks/mocks_image"
 	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
@@ -26,9 +29,13 @@ var _ = Describe("Image Remove API", func() {
 		mockCtrl *gomock.Controller
 		logger   *mocks_logger.Logger
 		cdClient *mocks_backend.MockContainerdClient
+		events   func() []*eventtype.Event
 		ncClient *mocks_backend.MockNerdctlImageSvc
+		store    *mocks_image.MockStore
 		name     string
+		target   digest.Digest
 		img      images.Image
+		diffIDs  []digest.Digest
 		service  image.Service
 	)
 	BeforeEach(func() {
@@ -37,36 +44,86 @@ var _ = Describe("Image Remove API", func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		logger = mocks_logger.NewLogger(mockCtrl)
 		cdClient = mocks_backend.NewMockContainerdClient(mockCtrl)
+		events = recordEvents(cdClient)
 		ncClient = mocks_backend.NewMockNerdctlImageSvc(mockCtrl)
+		store = mocks_image.NewMockStore(mockCtrl)
 		name = "test-image"
+		target = digest.FromString("test-image")
 		img = images.Image{
-			Name: name,
-			Target: ocispec.Descriptor{
-				Digest: "test-digest",
-			},
+			Name:   "docker.io/library/test-image:latest",
+			Target: ocispec.Descriptor{Digest: target},
 		}
+		diffIDs = []digest.Digest{digest.FromString("layer1"), digest.FromString("layer2")}
+		cdClient.EXPECT().ImageService().Return(store).AnyTimes()
 		service = NewService(cdClient, ncClient, logger)
 	})
+	expectRecords := func(records ...images.Image) {
+		store.EXPECT().List(gomock.Any(), "target.digest=="+target.String()).Return(records, nil)
+	}
 	Context("service", func() {
-		It("should successfully remove the image", func() {
-			// search image method returns one image
-			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(
-				1, 1, []*images.Image{&img}, nil)
+		It("should delete the image and prune its dangling parents", func() {
+			parent := images.Image{Name: "sha256:parent", Target: ocispec.Descriptor{Digest: "sha256:parent"}}
+			base := images.Image{Name: "docker.io/library/base:latest", Target: ocispec.Descriptor{Digest: "sha256:base"}}
+			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(1, 1, []*images.Image{&img}, nil)
+			expectRecords(img)
+			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(map[string]string{}, map[string]string{}, nil)
+			cdClient.EXPECT().GetImageDigests(gomock.Any(), &img).Return(append(diffIDs, digest.FromString("layer3")), nil)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), img.Name).Return(nil)
+			store.EXPECT().List(gomock.Any()).Return([]images.Image{base, parent}, nil)
+			cdClient.EXPECT().GetImageDigests(gomock.Any(), &base).Return(diffIDs[:1], nil)
+			cdClient.EXPECT().GetImageDigests(gomock.Any(), &parent).Return(diffIDs, nil)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), parent.Name).Return(nil)
 
-			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(
-				make(map[string]string),
-				make(map[string]string),
-				nil)
-			cdClient.EXPECT().DeleteImage(gomock.Any(), gomock.Any()).Return(nil)
-			cdClient.EXPECT().GetImageDigests(gomock.Any(), gomock.Any()).Return([]digest.Digest{"test-digest"}, nil)
+			untagged, deleted, err := service.Remove(ctx, name, false, false)
+			Expect(err).Should(BeNil())
+			Expect(untagged).Should(Equal([]string{"test-image:latest"}))
+			// the tagged base image stops the pruning
+			Expect(deleted).Should(Equal([]string{target.String(), "sha256:parent"}))
+			Expect(events()).Should(Equal([]*eventtype.Event{
+				getImageEvent(untagEventAction, target, "test-image:latest"),
+				getImageEvent(deleteEventAction, target, target.String()),
+				getImageEvent(deleteEventAction, "sha256:parent", "sha256:parent"),
+			}))
+		})
+		It("should not prune the parents pulled by their digest", func() {
+			pinned := images.Image{
+				Name:   "docker.io/library/base@" + digest.FromString("base").String(),
+				Target: ocispec.Descriptor{Digest: digest.FromString("base")},
+			}
+			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(1, 1, []*images.Image{&img}, nil)
+			expectRecords(img)
+			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(map[string]string{}, map[string]string{}, nil)
+			cdClient.EXPECT().GetImageDigests(gomock.Any(), &img).Return(diffIDs, nil)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), img.Name).Return(nil)
+			store.EXPECT().List(gomock.Any()).Return([]images.Image{pinned}, nil)
+			cdClient.EXPECT().GetImageDigests(gomock.Any(), &pinned).Return(diffIDs[:1], nil)
+
+			_, deleted, err := service.Remove(ctx, name, false, false)
+			Expect(err).Should(BeNil())
+			Expect(deleted).Should(Equal([]string{target.String()}))
+		})
+		It("should not prune the parents with noprune", func() {
+			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(1, 1, []*images.Image{&img}, nil)
+			expectRecords(img)
+			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(map[string]string{}, map[string]string{}, nil)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), img.Name).Return(nil)
+
+			untagged, deleted, err := service.Remove(ctx, name, false, true)
+			Expect(err).Should(BeNil())
+			Expect(untagged).Should(Equal([]string{"test-image:latest"}))
+			Expect(deleted).Should(Equal([]string{target.String()}))
+		})
+		It("should only untag the image if it has other references", func() {
+			other := images.Image{Name: "docker.io/library/test-image:1", Target: img.Target}
+			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(1, 1, []*images.Image{&img}, nil)
+			expectRecords(img, other)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), img.Name).Return(nil)
 
-			// service should return inspect object
-			untagged, deleted, err := service.Remove(ctx, name, false)
+			untagged, deleted, err := service.Remove(ctx, name, false, false)
 			Expect(err).Should(BeNil())
-			Expect(untagged).Should(HaveLen(1))
-			Expect(untagged).Should(ContainElement("test-image:test-digest"))
-			Expect(deleted).Should(HaveLen(1))
-			Expect(deleted).Should(ContainElement("test-digest"))
+			Expect(untagged).Should(Equal([]string{"test-image:latest"}))
+			Expect(deleted).Should(BeEmpty())
+			Expect(events()).Should(Equal([]*eventtype.Event{getImageEvent(untagEventAction, target, "test-image:latest")}))
 		})
 		It("should return NotFound error if image was not found", func() {
 			// search image method returns no image
@@ -75,73 +132,86 @@ var _ = Describe("Image Remove API", func() {
 			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
 
 			// service should return a NotFound error
-			untagged, deleted, err := service.Remove(ctx, name, false)
+			untagged, deleted, err := service.Remove(ctx, name, false, false)
 			Expect(untagged).Should(HaveLen(0))
 			Expect(deleted).Should(HaveLen(0))
 			Expect(errdefs.IsNotFound(err)).Should(BeTrue())
 		})
-		It("should return an error if multiple images were found for the given Id", func() {
-			// search image method returns multiple images
-			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(
-				2, 1, []*images.Image{&img, &img}, nil)
-			logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+		It("should return a Conflict error if the ID matches multiple images", func() {
+			other := images.Image{Name: "other", Target: ocispec.Descriptor{Digest: "sha256:456"}}
+			ncClient.EXPECT().SearchImage(gomock.Any(), "sha256").Return(2, 2, []*images.Image{&img, &other}, nil)
+
+			untagged, deleted, err := service.Remove(ctx, "sha256", true, false)
+			Expect(errdefs.IsConflict(err)).Should(BeTrue())
+			Expect(untagged).Should(HaveLen(0))
+			Expect(deleted).Should(HaveLen(0))
+		})
+		It("should return a Conflict error if the ID of an image with multiple references is removed without force", func() {
+			other := images.Image{Name: "docker.io/library/test-image:1", Target: img.Target}
+			ncClient.EXPECT().SearchImage(gomock.Any(), target.Encoded()).Return(2, 1, []*images.Image{&img, &other}, nil)
+			expectRecords(img, other)
 
-			// service should return an error
-			untagged, deleted, err := service.Remove(ctx, name, false)
+			untagged, deleted, err := service.Remove(ctx, target.Encoded(), false, false)
+			Expect(errdefs.IsConflict(err)).Should(BeTrue())
+			Expect(err.Error()).Should(ContainSubstring("image is referenced in multiple repositories"))
 			Expect(untagged).Should(HaveLen(0))
 			Expect(deleted).Should(HaveLen(0))
-			Expect(err).Should(HaveOccurred())
+		})
+		It("should delete all the references of the image with force", func() {
+			other := images.Image{Name: "docker.io/library/test-image:1", Target: img.Target}
+			ncClient.EXPECT().SearchImage(gomock.Any(), target.Encoded()).Return(2, 1, []*images.Image{&img, &other}, nil)
+			expectRecords(img, other)
+			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(map[string]string{}, map[string]string{}, nil)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), img.Name).Return(nil)
+			cdClient.EXPECT().DeleteImage(gomock.Any(), other.Name).Return(nil)
+
+			untagged, deleted, err := service.Remove(ctx, target.Encoded(), true, true)
+			Expect(err).Should(BeNil())
+			Expect(untagged).Should(Equal([]string{"test-image:latest", "test-image:1"}))
+			Expect(deleted).Should(Equal([]string{target.String()}))
 		})
 		It("should return an error image is being used by a running container", func() {
-			// search image method returns one image
 			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(1, 1, []*images.Image{&img}, nil)
-
+			expectRecords(img)
 			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(
-				make(map[string]string),
-				map[string]string{"test-image": "test-running-container"},
+				map[string]string{},
+				map[string]string{img.Name: "test-running-container"},
 				nil)
 
-			// service should return inspect object
-			untagged, deleted, err := service.Remove(ctx, name, false)
-			Expect(err).Should(Not(BeNil()))
+			// the image of a running container cannot be deleted even with force
+			untagged, deleted, err := service.Remove(ctx, name, true, false)
 			Expect(errdefs.IsConflict(err)).Should(BeTrue())
 			Expect(untagged).Should(HaveLen(0))
 			Expect(deleted).Should(HaveLen(0))
 		})
 		It("should return an error image is being used by a stopped container", func() {
-			// search image method returns one image
 			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(1, 1, []*images.Image{&img}, nil)
-
+			expectRecords(img)
 			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(
-				map[string]string{"test-image": "test-stopped-container"},
-				make(map[string]string),
+				map[string]string{img.Name: "test-stopped-container"},
+				map[string]string{},
 				nil)
 
-			// service should return inspect object
-			untagged, deleted, err := service.Remove(ctx, name, false)
-			Expect(err).Should(HaveOccurred())
+			untagged, deleted, err := service.Remove(ctx, name, false, false)
 			Expect(errdefs.IsConflict(err)).Should(BeTrue())
 			Expect(untagged).Should(HaveLen(0))
 			Expect(deleted).Should(HaveLen(0))
 		})
 		It("should successfully remove the image used by stopped container with force flag", func() {
-			// search image method returns one image
 			ncClient.EXPECT().SearchImage(gomock.Any(), name).Return(1, 1, []*images.Image{&img}, nil)
-
+			expectRecords(img)
 			cdClient.EXPECT().GetUsedImages(gomock.Any()).Return(
-				map[string]string{"test-image": "test-stopped-container"},
-				make(map[string]string),
+				map[string]string{img.Name: "test-stopped-container"},
+				map[string]string{},
 				nil)
-			cdClient.EXPECT().DeleteImage(gomock.Any(), gomock.Any()).Return(nil)
-			cdClient.EXPECT().GetImageDigests(gomock.Any(), gomock.Any()).Return([]digest.Digest{"test-digest"}, nil)
+			cdClient.EXPECT().GetImageDigests(gomock.Any(), &img).Return(nil, errors.New("rootfs error"))
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
+			cdClient.EXPECT().DeleteImage(gomock.Any(), img.Name).Return(nil)
 
-			// service should return inspect object
-			untagged, deleted, err := service.Remove(ctx, name, true)
+			untagged, deleted, err := service.Remove(ctx, name, true, false)
 			Expect(err).Should(BeNil())
-			Expect(untagged).Should(HaveLen(1))
-			Expect(untagged).Should(ContainElement("test-image:test-digest"))
-			Expect(deleted).Should(HaveLen(1))
-			Expect(deleted).Should(ContainElement("test-digest"))
+			Expect(untagged).Should(Equal([]string{"test-image:latest"}))
+			Expect(deleted).Should(Equal([]string{target.String()}))
 		})
 	})
 })
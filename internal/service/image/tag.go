@@ -11,12 +11,9 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 
-	eventtype "github.com/runfinch/finch-daemon/api/events"
 	"github.com/runfinch/finch-daemon/pkg/errdefs"
 )
 
-const tagEventAction = "tag"
-
 func (s *service) Tag(ctx context.Context, srcImg string, repo, tag string) error {
 	imgStore := s.client.GetClient().ImageService()
 	srcImgName, err := s.getFullImageName(ctx, srcImg)
@@ -46,7 +43,7 @@ func (s *service) Tag(ctx context.Context, srcImg string, repo, tag string) erro
 		}
 	}
 
-	err = s.client.PublishEvent(ctx, tagTopic(), getTagEvent(image.Target.Digest.String(), rawRef))
+	err = s.client.PublishEvent(ctx, imageTopic(tagEventAction), getImageEvent(tagEventAction, image.Target.Digest, rawRef))
 	if err != nil {
 		return err
 	}
@@ -75,22 +72,3 @@ func (s *service) getFullImageName(ctx context.Context, name string) (string, er
 	}
 	return srcName, nil
 }
-
-func tagTopic() string {
-	return fmt.Sprintf("/%s/%s/%s", eventtype.CompatibleTopicPrefix, eventType, tagEventAction)
-}
-
-func getTagEvent(digest, imgName string) *eventtype.Event {
-	return &eventtype.Event{
-		ID:     digest,
-		Status: tagEventAction,
-		Type:   "image",
-		Action: tagEventAction,
-		Actor: eventtype.EventActor{
-			Id: digest,
-			Attributes: map[string]string{
-				"name": imgName,
-			},
-		},
-	}
-}
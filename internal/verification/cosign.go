@@ -0,0 +1,25 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// VerifyCosignIgnoringTlog verifies the cosign signature of the image with the digested reference against the key,
+// without checking that the signature is recorded in the Rekor transparency log, which nerdctl always checks.
+func VerifyCosignIgnoringTlog(ctx context.Context, ref, key string) error {
+	cosign, err := exec.LookPath("cosign")
+	if err != nil {
+		return fmt.Errorf("cosign executable not found in $PATH: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, cosign, "verify", "--key", key, "--insecure-ignore-tlog=true", ref) //nolint:gosec // the arguments are from the policy of the daemon
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package verification implements the image signature verification policy of the daemon,
+// which is enforced on the images pulled through the API.
+package verification
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/distribution/reference"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// The providers images can be verified with.
+const (
+	ProviderNone     = "none"
+	ProviderCosign   = "cosign"
+	ProviderNotation = "notation"
+)
+
+// Policy is the image signature verification policy, which applies the rule of the most specific
+// scope matching an image, or the default rule if none matches.
+//
+// An example policy requiring the images of a repository to be signed with a cosign key:
+//
+//	[default]
+//	provider = "none"
+//
+//	[[rules]]
+//	scope = "registry.example.com/team"
+//	provider = "cosign"
+//	cosign_key = "/etc/finch/keys/team.pub"
+type Policy struct {
+	Default Rule   `toml:"default"`
+	Rules   []Rule `toml:"rules"`
+}
+
+// Rule is how the images of a scope are verified.
+type Rule struct {
+	// Scope is a registry, e.g. "localhost:5000", or a repository or repository prefix with its registry,
+	// e.g. "docker.io/library/alpine". It is ignored for the default rule.
+	Scope string `toml:"scope"`
+	// Provider is the provider the images are verified with, which is one of none, cosign or notation.
+	// Notation verifies the images with the trust policy and trust store of the notation configuration
+	// of the daemon.
+	Provider string `toml:"provider"`
+	// CosignKey is the path to the public key, or the KMS URI of the key, the images are signed with.
+	CosignKey string `toml:"cosign_key"`
+	// CosignCertificateIdentity and CosignCertificateOidcIssuer are the identity and the OIDC issuer
+	// expected in the certificate of images signed without a key.
+	CosignCertificateIdentity   string `toml:"cosign_certificate_identity"`
+	CosignCertificateOidcIssuer string `toml:"cosign_certificate_oidc_issuer"`
+	// CosignIgnoreTlog verifies the images signed with the cosign key without checking that their signatures
+	// are recorded in the Rekor transparency log, e.g. for images signed offline.
+	CosignIgnoreTlog bool `toml:"cosign_ignore_tlog"`
+}
+
+// Load loads the policy of the file at path and validates its rules.
+func Load(path string) (*Policy, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var policy Policy
+	dec := toml.NewDecoder(r).DisallowUnknownFields()
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to load the verification policy from %q: %w", path, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid verification policy %q: %w", path, err)
+	}
+	return &policy, nil
+}
+
+func (p *Policy) validate() error {
+	if err := p.Default.validate(); err != nil {
+		return fmt.Errorf("default rule: %w", err)
+	}
+	scopes := make(map[string]bool)
+	for _, rule := range p.Rules {
+		if rule.Scope == "" {
+			return errors.New("rule without a scope")
+		}
+		if scopes[rule.Scope] {
+			return fmt.Errorf("duplicate rules for scope %q", rule.Scope)
+		}
+		scopes[rule.Scope] = true
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule for scope %q: %w", rule.Scope, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if r.CosignIgnoreTlog && (r.Provider != ProviderCosign || r.CosignKey == "") {
+		return errors.New("cosign_ignore_tlog requires the cosign provider with a key")
+	}
+	switch r.Provider {
+	case "", ProviderNone, ProviderNotation:
+		return nil
+	case ProviderCosign:
+		if r.CosignKey == "" {
+			if r.CosignCertificateIdentity == "" || r.CosignCertificateOidcIssuer == "" {
+				return errors.New("cosign requires a key, or a certificate identity and OIDC issuer")
+			}
+			return nil
+		}
+		// keys which are not URIs, e.g. of a KMS, are files of the host.
+		if !strings.Contains(r.CosignKey, "://") {
+			if _, err := os.Stat(r.CosignKey); err != nil {
+				return fmt.Errorf("cosign key: %w", err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown provider %q", r.Provider)
+	}
+}
+
+// Rule returns the rule of the most specific scope matching the image, or the default rule if none matches.
+func (p *Policy) Rule(named reference.Named) Rule {
+	name := named.Name()
+	match, matchLen := p.Default, 0
+	for _, rule := range p.Rules {
+		if (name == rule.Scope || strings.HasPrefix(name, rule.Scope+"/")) && len(rule.Scope) > matchLen {
+			match, matchLen = rule, len(rule.Scope)
+		}
+	}
+	return match
+}
+
+// VerifyOptions returns the nerdctl options verifying images with the rule.
+func (r Rule) VerifyOptions() ncTypes.ImageVerifyOptions {
+	provider := r.Provider
+	if provider == "" {
+		provider = ProviderNone
+	}
+	return ncTypes.ImageVerifyOptions{
+		Provider:                    provider,
+		CosignKey:                   r.CosignKey,
+		CosignCertificateIdentity:   r.CosignCertificateIdentity,
+		CosignCertificateOidcIssuer: r.CosignCertificateOidcIssuer,
+	}
+}
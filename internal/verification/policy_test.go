@@ -0,0 +1,172 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package verification
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ncTypes "github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/distribution/reference"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// TestVerification is the entry point of the verification package's unit tests using ginkgo.
+func TestVerification(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UnitTests - Verification")
+}
+
+var _ = Describe("Verification policy", func() {
+	var dir string
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+	writePolicy := func(content string) string {
+		path := filepath.Join(dir, "policy.toml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).Should(Succeed())
+		return path
+	}
+	named := func(name string) reference.Named {
+		ref, err := reference.ParseNormalizedNamed(name)
+		Expect(err).ShouldNot(HaveOccurred())
+		return ref
+	}
+
+	Context("Load", func() {
+		It("should load a valid policy", func() {
+			key := filepath.Join(dir, "cosign.pub")
+			Expect(os.WriteFile(key, []byte("key"), 0o600)).Should(Succeed())
+			policy, err := Load(writePolicy(`
+[default]
+provider = "notation"
+
+[[rules]]
+scope = "registry.example.com/team"
+provider = "cosign"
+cosign_key = "` + key + `"
+
+[[rules]]
+scope = "ghcr.io/org"
+provider = "cosign"
+cosign_certificate_identity = "user@example.com"
+cosign_certificate_oidc_issuer = "https://accounts.example.com"
+
+[[rules]]
+scope = "localhost:5000"
+provider = "cosign"
+cosign_key = "` + key + `"
+cosign_ignore_tlog = true
+`))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(policy.Default.Provider).Should(Equal(ProviderNotation))
+			Expect(policy.Rules).Should(HaveLen(3))
+			Expect(policy.Rules[0].CosignKey).Should(Equal(key))
+			Expect(policy.Rules[2].CosignIgnoreTlog).Should(BeTrue())
+		})
+		It("should return an error if the file does not exist", func() {
+			_, err := Load(filepath.Join(dir, "missing.toml"))
+			Expect(os.IsNotExist(err)).Should(BeTrue())
+		})
+		It("should reject unknown fields", func() {
+			_, err := Load(writePolicy("[default]\nprovider = \"none\"\nkey = \"value\"\n"))
+			Expect(err).Should(MatchError(ContainSubstring("failed to load the verification policy")))
+		})
+		It("should reject unknown providers", func() {
+			_, err := Load(writePolicy("[default]\nprovider = \"gpg\"\n"))
+			Expect(err).Should(MatchError(ContainSubstring(`unknown provider "gpg"`)))
+		})
+		It("should reject rules without a scope", func() {
+			_, err := Load(writePolicy("[[rules]]\nprovider = \"notation\"\n"))
+			Expect(err).Should(MatchError(ContainSubstring("rule without a scope")))
+		})
+		It("should reject duplicate scopes", func() {
+			_, err := Load(writePolicy(`
+[[rules]]
+scope = "docker.io/library"
+provider = "notation"
+
+[[rules]]
+scope = "docker.io/library"
+provider = "none"
+`))
+			Expect(err).Should(MatchError(ContainSubstring(`duplicate rules for scope "docker.io/library"`)))
+		})
+		It("should reject cosign rules without a key or a certificate identity", func() {
+			_, err := Load(writePolicy(`
+[[rules]]
+scope = "ghcr.io/org"
+provider = "cosign"
+cosign_certificate_identity = "user@example.com"
+`))
+			Expect(err).Should(MatchError(ContainSubstring("cosign requires a key")))
+		})
+		It("should reject cosign key files which do not exist", func() {
+			_, err := Load(writePolicy("[default]\nprovider = \"cosign\"\ncosign_key = \"" +
+				filepath.Join(dir, "missing.pub") + "\"\n"))
+			Expect(err).Should(MatchError(ContainSubstring("cosign key")))
+		})
+		It("should reject cosign_ignore_tlog without a cosign key", func() {
+			_, err := Load(writePolicy(`
+[[rules]]
+scope = "ghcr.io/org"
+provider = "cosign"
+cosign_certificate_identity = "user@example.com"
+cosign_certificate_oidc_issuer = "https://accounts.example.com"
+cosign_ignore_tlog = true
+`))
+			Expect(err).Should(MatchError(ContainSubstring("cosign_ignore_tlog requires the cosign provider with a key")))
+			_, err = Load(writePolicy("[default]\nprovider = \"notation\"\ncosign_ignore_tlog = true\n"))
+			Expect(err).Should(MatchError(ContainSubstring("cosign_ignore_tlog requires")))
+		})
+		It("should accept cosign key URIs", func() {
+			_, err := Load(writePolicy("[default]\nprovider = \"cosign\"\ncosign_key = \"awskms:///alias/key\"\n"))
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+	Context("Rule", func() {
+		var policy *Policy
+		BeforeEach(func() {
+			policy = &Policy{
+				Default: Rule{Provider: ProviderNone},
+				Rules: []Rule{
+					{Scope: "registry.example.com", Provider: ProviderNotation},
+					{Scope: "registry.example.com/team", Provider: ProviderCosign, CosignKey: "team.pub"},
+					{Scope: "docker.io/library/alpine", Provider: ProviderNotation},
+				},
+			}
+		})
+		It("should return the rule of the most specific scope", func() {
+			Expect(policy.Rule(named("registry.example.com/team/app:1")).CosignKey).Should(Equal("team.pub"))
+			Expect(policy.Rule(named("registry.example.com/other/app")).Provider).Should(Equal(ProviderNotation))
+			Expect(policy.Rule(named("alpine:latest")).Provider).Should(Equal(ProviderNotation))
+		})
+		It("should only match scopes on path component boundaries", func() {
+			Expect(policy.Rule(named("registry.example.com/teammate/app")).Provider).Should(Equal(ProviderNotation))
+			Expect(policy.Rule(named("alpine-extra")).Provider).Should(Equal(ProviderNone))
+		})
+		It("should return the default rule if no scope matches", func() {
+			Expect(policy.Rule(named("ghcr.io/org/app")).Provider).Should(Equal(ProviderNone))
+		})
+	})
+	Context("VerifyOptions", func() {
+		It("should return the nerdctl options of the rule", func() {
+			rule := Rule{
+				Provider:                    ProviderCosign,
+				CosignCertificateIdentity:   "user@example.com",
+				CosignCertificateOidcIssuer: "https://accounts.example.com",
+			}
+			Expect(rule.VerifyOptions()).Should(Equal(ncTypes.ImageVerifyOptions{
+				Provider:                    ProviderCosign,
+				CosignCertificateIdentity:   "user@example.com",
+				CosignCertificateOidcIssuer: "https://accounts.example.com",
+			}))
+		})
+		It("should not verify images of rules without a provider", func() {
+			Expect(Rule{}.VerifyOptions().Provider).Should(Equal(ProviderNone))
+		})
+	})
+})
@@ -10,9 +10,9 @@
 package mocks_archive
 
 import (
+	io "io"
 	reflect "reflect"
 
-	ecc "github.com/runfinch/finch-daemon/pkg/ecc"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,17 +40,16 @@ func (m *MockTarCreator) EXPECT() *MockTarCreatorMockRecorder {
 	return m.recorder
 }
 
-// CreateTarCommand mocks base method.
-func (m *MockTarCreator) CreateTarCommand(srcPath string, slashDot bool) (ecc.ExecCmd, error) {
+// CreateTar mocks base method.
+func (m *MockTarCreator) CreateTar(srcPath string, writer io.Writer, slashDot bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTarCommand", srcPath, slashDot)
-	ret0, _ := ret[0].(ecc.ExecCmd)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateTar", srcPath, writer, slashDot)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// CreateTarCommand indicates an expected call of CreateTarCommand.
-func (mr *MockTarCreatorMockRecorder) CreateTarCommand(srcPath, slashDot any) *gomock.Call {
+// CreateTar indicates an expected call of CreateTar.
+func (mr *MockTarCreatorMockRecorder) CreateTar(srcPath, writer, slashDot any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTarCommand", reflect.TypeOf((*MockTarCreator)(nil).CreateTarCommand), srcPath, slashDot)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTar", reflect.TypeOf((*MockTarCreator)(nil).CreateTar), srcPath, writer, slashDot)
 }
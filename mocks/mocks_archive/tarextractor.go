@@ -69,18 +69,18 @@ func (mr *MockTarExtractorMockRecorder) CreateExtractCmd(reader, destDir any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExtractCmd", reflect.TypeOf((*MockTarExtractor)(nil).CreateExtractCmd), reader, destDir)
 }
 
-// ExtractCompressed mocks base method.
-func (m *MockTarExtractor) ExtractCompressed(tarArchive io.Reader, dest string, options *archive.TarOptions) error {
+// ExtractInRoot mocks base method.
+func (m *MockTarExtractor) ExtractInRoot(tarArchive io.Reader, root, dest string, options *archive.TarOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExtractCompressed", tarArchive, dest, options)
+	ret := m.ctrl.Call(m, "ExtractInRoot", tarArchive, root, dest, options)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ExtractCompressed indicates an expected call of ExtractCompressed.
-func (mr *MockTarExtractorMockRecorder) ExtractCompressed(tarArchive, dest, options any) *gomock.Call {
+// ExtractInRoot indicates an expected call of ExtractInRoot.
+func (mr *MockTarExtractorMockRecorder) ExtractInRoot(tarArchive, root, dest, options any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractCompressed", reflect.TypeOf((*MockTarExtractor)(nil).ExtractCompressed), tarArchive, dest, options)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractInRoot", reflect.TypeOf((*MockTarExtractor)(nil).ExtractInRoot), tarArchive, root, dest, options)
 }
 
 // ExtractInTemp mocks base method.
@@ -206,6 +206,21 @@ func (mr *MockContainerdClientMockRecorder) GetContainers(ctx any, filters ...an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainers", reflect.TypeOf((*MockContainerdClient)(nil).GetContainers), varargs...)
 }
 
+// GetContentLabels mocks base method.
+func (m *MockContainerdClient) GetContentLabels(ctx context.Context, dgst digest.Digest) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContentLabels", ctx, dgst)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContentLabels indicates an expected call of GetContentLabels.
+func (mr *MockContainerdClientMockRecorder) GetContentLabels(ctx, dgst any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContentLabels", reflect.TypeOf((*MockContainerdClient)(nil).GetContentLabels), ctx, dgst)
+}
+
 // GetCurrentCapabilities mocks base method.
 func (m *MockContainerdClient) GetCurrentCapabilities() ([]string, error) {
 	m.ctrl.T.Helper()
@@ -251,6 +266,21 @@ func (mr *MockContainerdClientMockRecorder) GetImageDigests(ctx, img any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageDigests", reflect.TypeOf((*MockContainerdClient)(nil).GetImageDigests), ctx, img)
 }
 
+// GetImageLayers mocks base method.
+func (m *MockContainerdClient) GetImageLayers(ctx context.Context, img *images.Image) ([]v1.Descriptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageLayers", ctx, img)
+	ret0, _ := ret[0].([]v1.Descriptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageLayers indicates an expected call of GetImageLayers.
+func (mr *MockContainerdClientMockRecorder) GetImageLayers(ctx, img any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageLayers", reflect.TypeOf((*MockContainerdClient)(nil).GetImageLayers), ctx, img)
+}
+
 // GetUsedImages mocks base method.
 func (m *MockContainerdClient) GetUsedImages(ctx context.Context) (map[string]string, map[string]string, error) {
 	m.ctrl.T.Helper()
@@ -267,6 +297,21 @@ func (mr *MockContainerdClientMockRecorder) GetUsedImages(ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsedImages", reflect.TypeOf((*MockContainerdClient)(nil).GetUsedImages), ctx)
 }
 
+// HasSnapshotter mocks base method.
+func (m *MockContainerdClient) HasSnapshotter(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSnapshotter", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasSnapshotter indicates an expected call of HasSnapshotter.
+func (mr *MockContainerdClientMockRecorder) HasSnapshotter(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSnapshotter", reflect.TypeOf((*MockContainerdClient)(nil).HasSnapshotter), ctx, name)
+}
+
 // ImageService mocks base method.
 func (m *MockContainerdClient) ImageService() images.Store {
 	m.ctrl.T.Helper()
@@ -476,3 +521,32 @@ func (mr *MockContainerdClientMockRecorder) Unmount(mPath, flags any) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmount", reflect.TypeOf((*MockContainerdClient)(nil).Unmount), mPath, flags)
 }
+
+// UpdateContainerExtension mocks base method.
+func (m *MockContainerdClient) UpdateContainerExtension(ctx context.Context, id, name string, extension any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContainerExtension", ctx, id, name, extension)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateContainerExtension indicates an expected call of UpdateContainerExtension.
+func (mr *MockContainerdClientMockRecorder) UpdateContainerExtension(ctx, id, name, extension any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContainerExtension", reflect.TypeOf((*MockContainerdClient)(nil).UpdateContainerExtension), ctx, id, name, extension)
+}
+
+// ViewSnapshotMounts mocks base method.
+func (m *MockContainerdClient) ViewSnapshotMounts(ctx context.Context, snapshotter, key string) ([]mount.Mount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewSnapshotMounts", ctx, snapshotter, key)
+	ret0, _ := ret[0].([]mount.Mount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ViewSnapshotMounts indicates an expected call of ViewSnapshotMounts.
+func (mr *MockContainerdClientMockRecorder) ViewSnapshotMounts(ctx, snapshotter, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewSnapshotMounts", reflect.TypeOf((*MockContainerdClient)(nil).ViewSnapshotMounts), ctx, snapshotter, key)
+}
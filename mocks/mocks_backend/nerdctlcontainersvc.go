@@ -22,6 +22,7 @@ import (
 	dockercompat "github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	native "github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	logging "github.com/containerd/nerdctl/v2/pkg/logging"
+	digest "github.com/opencontainers/go-digest"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -268,6 +269,20 @@ func (mr *MockNerdctlContainerSvcMockRecorder) RenameContainer(ctx, arg1, newNam
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameContainer", reflect.TypeOf((*MockNerdctlContainerSvc)(nil).RenameContainer), ctx, arg1, newName, options)
 }
 
+// Snapshotter mocks base method.
+func (m *MockNerdctlContainerSvc) Snapshotter() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshotter")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Snapshotter indicates an expected call of Snapshotter.
+func (mr *MockNerdctlContainerSvcMockRecorder) Snapshotter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshotter", reflect.TypeOf((*MockNerdctlContainerSvc)(nil).Snapshotter))
+}
+
 // StartContainer mocks base method.
 func (m *MockNerdctlContainerSvc) StartContainer(ctx context.Context, cid string, options types.ContainerStartOptions) error {
 	m.ctrl.T.Helper()
@@ -296,6 +311,20 @@ func (mr *MockNerdctlContainerSvcMockRecorder) StopContainer(ctx, cid, options a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopContainer", reflect.TypeOf((*MockNerdctlContainerSvc)(nil).StopContainer), ctx, cid, options)
 }
 
+// UnpackImage mocks base method.
+func (m *MockNerdctlContainerSvc) UnpackImage(ctx context.Context, rawRef string, options types.ContainerCreateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpackImage", ctx, rawRef, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnpackImage indicates an expected call of UnpackImage.
+func (mr *MockNerdctlContainerSvcMockRecorder) UnpackImage(ctx, rawRef, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpackImage", reflect.TypeOf((*MockNerdctlContainerSvc)(nil).UnpackImage), ctx, rawRef, options)
+}
+
 // UnpauseContainer mocks base method.
 func (m *MockNerdctlContainerSvc) UnpauseContainer(ctx context.Context, cid string, options types.ContainerUnpauseOptions) error {
 	m.ctrl.T.Helper()
@@ -309,3 +338,18 @@ func (mr *MockNerdctlContainerSvcMockRecorder) UnpauseContainer(ctx, cid, option
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpauseContainer", reflect.TypeOf((*MockNerdctlContainerSvc)(nil).UnpauseContainer), ctx, cid, options)
 }
+
+// VerifyImage mocks base method.
+func (m *MockNerdctlContainerSvc) VerifyImage(ctx context.Context, rawRef string) (digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyImage", ctx, rawRef)
+	ret0, _ := ret[0].(digest.Digest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyImage indicates an expected call of VerifyImage.
+func (mr *MockNerdctlContainerSvcMockRecorder) VerifyImage(ctx, rawRef any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyImage", reflect.TypeOf((*MockNerdctlContainerSvc)(nil).VerifyImage), ctx, rawRef)
+}
@@ -21,7 +21,9 @@ import (
 	dockerconfigresolver "github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	dockercompat "github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	platforms "github.com/containerd/platforms"
+	digest "github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	backend "github.com/runfinch/finch-daemon/internal/backend"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -49,6 +51,20 @@ func (m *MockNerdctlImageSvc) EXPECT() *MockNerdctlImageSvcMockRecorder {
 	return m.recorder
 }
 
+// ExportImage mocks base method.
+func (m *MockNerdctlImageSvc) ExportImage(ctx context.Context, imageNames []string, platform *v1.Platform, writer io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportImage", ctx, imageNames, platform, writer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportImage indicates an expected call of ExportImage.
+func (mr *MockNerdctlImageSvcMockRecorder) ExportImage(ctx, imageNames, platform, writer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).ExportImage), ctx, imageNames, platform, writer)
+}
+
 // GetDataStore mocks base method.
 func (m *MockNerdctlImageSvc) GetDataStore() (string, error) {
 	m.ctrl.T.Helper()
@@ -80,47 +96,124 @@ func (mr *MockNerdctlImageSvcMockRecorder) GetDockerResolver(ctx, refDomain, cre
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDockerResolver", reflect.TypeOf((*MockNerdctlImageSvc)(nil).GetDockerResolver), ctx, refDomain, creds)
 }
 
+// GetImageConfig mocks base method.
+func (m *MockNerdctlImageSvc) GetImageConfig(ctx context.Context, image images.Image) (*v1.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageConfig", ctx, image)
+	ret0, _ := ret[0].(*v1.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageConfig indicates an expected call of GetImageConfig.
+func (mr *MockNerdctlImageSvcMockRecorder) GetImageConfig(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageConfig", reflect.TypeOf((*MockNerdctlImageSvc)(nil).GetImageConfig), ctx, image)
+}
+
+// GetImageLayerSizes mocks base method.
+func (m *MockNerdctlImageSvc) GetImageLayerSizes(ctx context.Context, image images.Image) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageLayerSizes", ctx, image)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageLayerSizes indicates an expected call of GetImageLayerSizes.
+func (mr *MockNerdctlImageSvcMockRecorder) GetImageLayerSizes(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageLayerSizes", reflect.TypeOf((*MockNerdctlImageSvc)(nil).GetImageLayerSizes), ctx, image)
+}
+
+// GetImageSnapshotters mocks base method.
+func (m *MockNerdctlImageSvc) GetImageSnapshotters(ctx context.Context, image images.Image, platform *v1.Platform) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageSnapshotters", ctx, image, platform)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageSnapshotters indicates an expected call of GetImageSnapshotters.
+func (mr *MockNerdctlImageSvcMockRecorder) GetImageSnapshotters(ctx, image, platform any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageSnapshotters", reflect.TypeOf((*MockNerdctlImageSvc)(nil).GetImageSnapshotters), ctx, image, platform)
+}
+
+// GetLayerProgress mocks base method.
+func (m *MockNerdctlImageSvc) GetLayerProgress(ctx context.Context, root v1.Descriptor, platform v1.Platform, snapshotter string) ([]backend.LayerProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLayerProgress", ctx, root, platform, snapshotter)
+	ret0, _ := ret[0].([]backend.LayerProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLayerProgress indicates an expected call of GetLayerProgress.
+func (mr *MockNerdctlImageSvcMockRecorder) GetLayerProgress(ctx, root, platform, snapshotter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayerProgress", reflect.TypeOf((*MockNerdctlImageSvc)(nil).GetLayerProgress), ctx, root, platform, snapshotter)
+}
+
+// ImportImage mocks base method.
+func (m *MockNerdctlImageSvc) ImportImage(ctx context.Context, rootfs io.Reader, ref string, platform v1.Platform, config v1.ImageConfig, message string) (*images.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportImage", ctx, rootfs, ref, platform, config, message)
+	ret0, _ := ret[0].(*images.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportImage indicates an expected call of ImportImage.
+func (mr *MockNerdctlImageSvcMockRecorder) ImportImage(ctx, rootfs, ref, platform, config, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).ImportImage), ctx, rootfs, ref, platform, config, message)
+}
+
 // InspectImage mocks base method.
-func (m *MockNerdctlImageSvc) InspectImage(ctx context.Context, image images.Image) (*dockercompat.Image, error) {
+func (m *MockNerdctlImageSvc) InspectImage(ctx context.Context, image images.Image, platform *v1.Platform) (*dockercompat.Image, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InspectImage", ctx, image)
+	ret := m.ctrl.Call(m, "InspectImage", ctx, image, platform)
 	ret0, _ := ret[0].(*dockercompat.Image)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // InspectImage indicates an expected call of InspectImage.
-func (mr *MockNerdctlImageSvcMockRecorder) InspectImage(ctx, image any) *gomock.Call {
+func (mr *MockNerdctlImageSvcMockRecorder) InspectImage(ctx, image, platform any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).InspectImage), ctx, image)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).InspectImage), ctx, image, platform)
 }
 
-// LoadImage mocks base method.
-func (m *MockNerdctlImageSvc) LoadImage(ctx context.Context, img string, stdout io.Writer, quiet bool) error {
+// ListImages mocks base method.
+func (m *MockNerdctlImageSvc) ListImages(ctx context.Context, filters *imgutil.Filters) ([]images.Image, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadImage", ctx, img, stdout, quiet)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListImages", ctx, filters)
+	ret0, _ := ret[0].([]images.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// LoadImage indicates an expected call of LoadImage.
-func (mr *MockNerdctlImageSvcMockRecorder) LoadImage(ctx, img, stdout, quiet any) *gomock.Call {
+// ListImages indicates an expected call of ListImages.
+func (mr *MockNerdctlImageSvcMockRecorder) ListImages(ctx, filters any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).LoadImage), ctx, img, stdout, quiet)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockNerdctlImageSvc)(nil).ListImages), ctx, filters)
 }
 
-// ExportImage mocks base method.
-func (m *MockNerdctlImageSvc) ExportImage(ctx context.Context, imageNames []string, platform *v1.Platform, writer io.Writer) error {
+// LoadImage mocks base method.
+func (m *MockNerdctlImageSvc) LoadImage(ctx context.Context, img string) ([]images.Image, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExportImage", ctx, imageNames, platform, writer)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "LoadImage", ctx, img)
+	ret0, _ := ret[0].([]images.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// ExportImage indicates an expected call of ExportImage.
-func (mr *MockNerdctlImageSvcMockRecorder) ExportImage(ctx, imgs, platform, writer any) *gomock.Call {
+// LoadImage indicates an expected call of LoadImage.
+func (mr *MockNerdctlImageSvcMockRecorder) LoadImage(ctx, img any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).ExportImage), ctx, imgs, platform, writer)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).LoadImage), ctx, img)
 }
 
 // Namespace mocks base method.
@@ -138,18 +231,18 @@ func (mr *MockNerdctlImageSvcMockRecorder) Namespace() *gomock.Call {
 }
 
 // PullImage mocks base method.
-func (m *MockNerdctlImageSvc) PullImage(ctx context.Context, stdout, stderr io.Writer, resolver remotes.Resolver, ref string, arg5 []v1.Platform) (*imgutil.EnsuredImage, error) {
+func (m *MockNerdctlImageSvc) PullImage(ctx context.Context, stdout, stderr io.Writer, resolver remotes.Resolver, ref string, arg5 []v1.Platform, snapshotter string) (*imgutil.EnsuredImage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PullImage", ctx, stdout, stderr, resolver, ref, arg5)
+	ret := m.ctrl.Call(m, "PullImage", ctx, stdout, stderr, resolver, ref, arg5, snapshotter)
 	ret0, _ := ret[0].(*imgutil.EnsuredImage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PullImage indicates an expected call of PullImage.
-func (mr *MockNerdctlImageSvcMockRecorder) PullImage(ctx, stdout, stderr, resolver, ref, arg5 any) *gomock.Call {
+func (mr *MockNerdctlImageSvcMockRecorder) PullImage(ctx, stdout, stderr, resolver, ref, arg5, snapshotter any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).PullImage), ctx, stdout, stderr, resolver, ref, arg5)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).PullImage), ctx, stdout, stderr, resolver, ref, arg5, snapshotter)
 }
 
 // PushImage mocks base method.
@@ -182,3 +275,32 @@ func (mr *MockNerdctlImageSvcMockRecorder) SearchImage(ctx, name any) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).SearchImage), ctx, name)
 }
+
+// Snapshotter mocks base method.
+func (m *MockNerdctlImageSvc) Snapshotter() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshotter")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Snapshotter indicates an expected call of Snapshotter.
+func (mr *MockNerdctlImageSvcMockRecorder) Snapshotter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshotter", reflect.TypeOf((*MockNerdctlImageSvc)(nil).Snapshotter))
+}
+
+// VerifyImage mocks base method.
+func (m *MockNerdctlImageSvc) VerifyImage(ctx context.Context, rawRef string) (digest.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyImage", ctx, rawRef)
+	ret0, _ := ret[0].(digest.Digest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyImage indicates an expected call of VerifyImage.
+func (mr *MockNerdctlImageSvcMockRecorder) VerifyImage(ctx, rawRef any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyImage", reflect.TypeOf((*MockNerdctlImageSvc)(nil).VerifyImage), ctx, rawRef)
+}
@@ -90,6 +90,21 @@ func (mr *MockNerdctlNetworkSvcMockRecorder) FilterNetworks(filterf any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterNetworks", reflect.TypeOf((*MockNerdctlNetworkSvc)(nil).FilterNetworks), filterf)
 }
 
+// GetNetworkListCachedResult mocks base method.
+func (m *MockNerdctlNetworkSvc) GetNetworkListCachedResult(netconflist *libcni.NetworkConfigList, conf *libcni.RuntimeConf) (types0.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetworkListCachedResult", netconflist, conf)
+	ret0, _ := ret[0].(types0.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNetworkListCachedResult indicates an expected call of GetNetworkListCachedResult.
+func (mr *MockNerdctlNetworkSvcMockRecorder) GetNetworkListCachedResult(netconflist, conf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetworkListCachedResult", reflect.TypeOf((*MockNerdctlNetworkSvc)(nil).GetNetworkListCachedResult), netconflist, conf)
+}
+
 // InspectNetwork mocks base method.
 func (m *MockNerdctlNetworkSvc) InspectNetwork(ctx context.Context, networkConfig *netutil.NetworkConfig) (*dockercompat.Network, error) {
 	m.ctrl.T.Helper()
@@ -58,18 +58,19 @@ func (mr *MockServiceMockRecorder) Attach(ctx, cid, opts any) *gomock.Call {
 }
 
 // Create mocks base method.
-func (m *MockService) Create(ctx context.Context, image string, cmd []string, createOpt types.ContainerCreateOptions, netOpt types.NetworkOptions) (string, error) {
+func (m *MockService) Create(ctx context.Context, image string, cmd []string, createOpt types.ContainerCreateOptions, netOpt types.NetworkOptions, req *types0.ContainerCreateRequest) (string, []string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, image, cmd, createOpt, netOpt)
+	ret := m.ctrl.Call(m, "Create", ctx, image, cmd, createOpt, netOpt, req)
 	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockServiceMockRecorder) Create(ctx, image, cmd, createOpt, netOpt any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Create(ctx, image, cmd, createOpt, netOpt, req any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, image, cmd, createOpt, netOpt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, image, cmd, createOpt, netOpt, req)
 }
 
 // ExecCreate mocks base method.
@@ -42,18 +42,18 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // Inspect mocks base method.
-func (m *MockService) Inspect(ctx context.Context, conId, execId string) (*types.ExecInspect, error) {
+func (m *MockService) Inspect(ctx context.Context, execId string) (*types.ExecInspect, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Inspect", ctx, conId, execId)
+	ret := m.ctrl.Call(m, "Inspect", ctx, execId)
 	ret0, _ := ret[0].(*types.ExecInspect)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Inspect indicates an expected call of Inspect.
-func (mr *MockServiceMockRecorder) Inspect(ctx, conId, execId any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Inspect(ctx, execId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inspect", reflect.TypeOf((*MockService)(nil).Inspect), ctx, conId, execId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inspect", reflect.TypeOf((*MockService)(nil).Inspect), ctx, execId)
 }
 
 // Resize mocks base method.
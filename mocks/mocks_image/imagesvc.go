@@ -14,7 +14,6 @@ import (
 	io "io"
 	reflect "reflect"
 
-	dockercompat "github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	types "github.com/docker/cli/cli/config/types"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	types0 "github.com/runfinch/finch-daemon/api/types"
@@ -45,34 +44,77 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// Export mocks base method.
+func (m *MockService) Export(ctx context.Context, names []string, platform *v1.Platform, outStream io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, names, platform, outStream)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockServiceMockRecorder) Export(ctx, names, platform, outStream any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockService)(nil).Export), ctx, names, platform, outStream)
+}
+
+// History mocks base method.
+func (m *MockService) History(ctx context.Context, name string) ([]types0.ImageHistoryItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, name)
+	ret0, _ := ret[0].([]types0.ImageHistoryItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockServiceMockRecorder) History(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockService)(nil).History), ctx, name)
+}
+
+// Import mocks base method.
+func (m *MockService) Import(ctx context.Context, options types0.ImageImportOptions, outStream io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, options, outStream)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockServiceMockRecorder) Import(ctx, options, outStream any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockService)(nil).Import), ctx, options, outStream)
+}
+
 // Inspect mocks base method.
-func (m *MockService) Inspect(ctx context.Context, name string) (*dockercompat.Image, error) {
+func (m *MockService) Inspect(ctx context.Context, name string, platform *v1.Platform) (*types0.ImageInspect, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Inspect", ctx, name)
-	ret0, _ := ret[0].(*dockercompat.Image)
+	ret := m.ctrl.Call(m, "Inspect", ctx, name, platform)
+	ret0, _ := ret[0].(*types0.ImageInspect)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Inspect indicates an expected call of Inspect.
-func (mr *MockServiceMockRecorder) Inspect(ctx, name any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Inspect(ctx, name, platform any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inspect", reflect.TypeOf((*MockService)(nil).Inspect), ctx, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inspect", reflect.TypeOf((*MockService)(nil).Inspect), ctx, name, platform)
 }
 
 // List mocks base method.
-func (m *MockService) List(ctx context.Context) ([]types0.ImageSummary, error) {
+func (m *MockService) List(ctx context.Context, options types0.ImageListOptions) ([]types0.ImageSummary, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
+	ret := m.ctrl.Call(m, "List", ctx, options)
 	ret0, _ := ret[0].([]types0.ImageSummary)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockServiceMockRecorder) List(ctx any) *gomock.Call {
+func (mr *MockServiceMockRecorder) List(ctx, options any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), ctx, options)
 }
 
 // Load mocks base method.
@@ -89,53 +131,38 @@ func (mr *MockServiceMockRecorder) Load(ctx, inStream, outStream, quiet any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockService)(nil).Load), ctx, inStream, outStream, quiet)
 }
 
-// Export mocks base method.
-func (m *MockService) Export(ctx context.Context, name string, platform *v1.Platform, outStream io.Writer) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Export", ctx, name, platform, outStream)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Export indicates an expected call of Export.
-func (mr *MockServiceMockRecorder) Export(ctx, name, platform, outStream any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockService)(nil).Export), ctx, name, platform, outStream)
-}
-
 // Pull mocks base method.
-func (m *MockService) Pull(ctx context.Context, name, tag, platform string, authCfg *types.AuthConfig, outStream io.Writer) error {
+func (m *MockService) Pull(ctx context.Context, name, tag, platform, snapshotter string, authCfg *types.AuthConfig, outStream io.Writer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Pull", ctx, name, tag, platform, authCfg, outStream)
+	ret := m.ctrl.Call(m, "Pull", ctx, name, tag, platform, snapshotter, authCfg, outStream)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Pull indicates an expected call of Pull.
-func (mr *MockServiceMockRecorder) Pull(ctx, name, tag, platform, authCfg, outStream any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Pull(ctx, name, tag, platform, snapshotter, authCfg, outStream any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pull", reflect.TypeOf((*MockService)(nil).Pull), ctx, name, tag, platform, authCfg, outStream)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pull", reflect.TypeOf((*MockService)(nil).Pull), ctx, name, tag, platform, snapshotter, authCfg, outStream)
 }
 
 // Push mocks base method.
-func (m *MockService) Push(ctx context.Context, name, tag string, authCfg *types.AuthConfig, outStream io.Writer) (*types0.PushResult, error) {
+func (m *MockService) Push(ctx context.Context, name, tag string, recipients []string, authCfg *types.AuthConfig, outStream io.Writer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Push", ctx, name, tag, authCfg, outStream)
-	ret0, _ := ret[0].(*types0.PushResult)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Push", ctx, name, tag, recipients, authCfg, outStream)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Push indicates an expected call of Push.
-func (mr *MockServiceMockRecorder) Push(ctx, name, tag, authCfg, outStream any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Push(ctx, name, tag, recipients, authCfg, outStream any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockService)(nil).Push), ctx, name, tag, authCfg, outStream)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockService)(nil).Push), ctx, name, tag, recipients, authCfg, outStream)
 }
 
 // Remove mocks base method.
-func (m *MockService) Remove(ctx context.Context, name string, force bool) ([]string, []string, error) {
+func (m *MockService) Remove(ctx context.Context, name string, force, noprune bool) ([]string, []string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Remove", ctx, name, force)
+	ret := m.ctrl.Call(m, "Remove", ctx, name, force, noprune)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].([]string)
 	ret2, _ := ret[2].(error)
@@ -143,9 +170,9 @@ func (m *MockService) Remove(ctx context.Context, name string, force bool) ([]st
 }
 
 // Remove indicates an expected call of Remove.
-func (mr *MockServiceMockRecorder) Remove(ctx, name, force any) *gomock.Call {
+func (mr *MockServiceMockRecorder) Remove(ctx, name, force, noprune any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockService)(nil).Remove), ctx, name, force)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockService)(nil).Remove), ctx, name, force, noprune)
 }
 
 // Tag mocks base method.
@@ -17,29 +17,22 @@ import (
 
 //go:generate mockgen --destination=../../mocks/mocks_archive/tarcreator.go -package=mocks_archive github.com/runfinch/finch-daemon/pkg/archive TarCreator
 type TarCreator interface {
-	CreateTarCommand(srcPath string, slashDot bool) (ecc.ExecCmd, error)
+	CreateTar(srcPath string, writer io.Writer, slashDot bool) error
 }
 
 type tarCreator struct {
-	ecc    ecc.ExecCmdCreator
 	logger flog.Logger
 }
 
-func NewTarCreator(ecc ecc.ExecCmdCreator, logger flog.Logger) TarCreator {
+func NewTarCreator(logger flog.Logger) TarCreator {
 	return &tarCreator{
-		ecc:    ecc,
 		logger: logger,
 	}
 }
 
-// CreateTarCommand creates an *exec.Cmd that will output a tar archive of the provided srcPath to stdout.
-func (c *tarCreator) CreateTarCommand(srcPath string, slashDot bool) (ecc.ExecCmd, error) {
-	tarBinary, _, err := tarutil.FindTarBinary()
-	if err != nil {
-		c.logger.Debugf("error getting tar binary: %s", err.Error())
-		return nil, err
-	}
-
+// CreateTar writes a tar archive of the provided srcPath to writer without shelling out to a tar binary.
+// Symlinks inside srcPath are archived as links and never followed, so srcPath must already be resolved.
+func (c *tarCreator) CreateTar(srcPath string, writer io.Writer, slashDot bool) error {
 	// "/." is a Docker thing that instructions the copy command to download contents of the folder only
 	var tarDir, tarPath string
 	if slashDot {
@@ -50,9 +43,14 @@ func (c *tarCreator) CreateTarCommand(srcPath string, slashDot bool) (ecc.ExecCm
 		tarPath = path.Base(srcPath)
 	}
 
-	cmd := c.ecc.Command(tarBinary, []string{"-c", "-f", "-", tarPath}...)
-	cmd.SetDir(tarDir)
-	return cmd, nil
+	content, err := archive.TarWithOptions(tarDir, archive.TarResourceRebaseOpts(tarPath, tarPath))
+	if err != nil {
+		c.logger.Debugf("error creating tar archive of %s: %s", srcPath, err.Error())
+		return err
+	}
+	defer content.Close()
+	_, err = io.Copy(writer, content)
+	return err
 }
 
 // TarExtractor interface to extract a tar file
@@ -62,7 +60,7 @@ type TarExtractor interface {
 	ExtractInTemp(reader io.Reader, dirPrefix string) (ecc.ExecCmd, error)
 	CreateExtractCmd(reader io.Reader, destDir string) (ecc.ExecCmd, error)
 	Cleanup(cmd ecc.ExecCmd)
-	ExtractCompressed(tarArchive io.Reader, dest string, options *archive.TarOptions) error
+	ExtractInRoot(tarArchive io.Reader, root, dest string, options *archive.TarOptions) error
 }
 
 // tarExtractor struct in an implementation of TarExtractor. It extracts uncompressed tar file.
@@ -115,13 +113,3 @@ func (ext *tarExtractor) Cleanup(cmd ecc.ExecCmd) {
 		ext.logger.Debugf("successfully cleaned up folder. path: %s", cmd.GetDir())
 	}
 }
-
-// Wraps https://github.com/moby/moby/blob/master/pkg/archive/archive.go#L1233
-// ExtractCompressed reads a stream of bytes from `archive`, parses it as a tar archive,
-// and unpacks it into the directory at `dest`.
-// The archive may be compressed with one of the following algorithms:
-// identity (uncompressed), gzip, bzip2, xz.
-
-func (ext *tarExtractor) ExtractCompressed(tarArchive io.Reader, dest string, options *archive.TarOptions) error {
-	return archive.Untar(tarArchive, dest, options)
-}
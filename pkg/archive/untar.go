@@ -0,0 +1,270 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/moby/go-archive"
+	"github.com/moby/go-archive/compression"
+	"golang.org/x/sys/unix"
+)
+
+// xattrPAXPrefix is the PAX record prefix used by GNU tar and Go's archive/tar for extended attributes.
+const xattrPAXPrefix = "SCHILY.xattr."
+
+// ErrOverwriteDirNonDir is returned when NoOverwriteDirNonDir is set and extracting an entry would replace a
+// directory with a non-directory or vice versa.
+var ErrOverwriteDirNonDir = errors.New("cannot overwrite directory with non-directory or non-directory with directory")
+
+// ExtractInRoot reads a tar archive, optionally compressed with gzip, bzip2, xz or zstd, and unpacks it into dest.
+// dest and every entry of the archive are interpreted relative to root, and all symlinks are resolved as if root
+// were the filesystem root, so entries can never be written outside of it.
+//
+// Only NoOverwriteDirNonDir and ChownOpts of options are honored. When ChownOpts is nil, the ownership recorded in
+// the archive is preserved.
+func (ext *tarExtractor) ExtractInRoot(tarArchive io.Reader, root, dest string, options *archive.TarOptions) error {
+	if options == nil {
+		options = &archive.TarOptions{}
+	}
+	decompressed, err := compression.DecompressStream(tarArchive)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+
+	u := &untarrer{
+		root:    filepath.Clean(root),
+		dest:    path.Clean("/" + dest),
+		options: options,
+	}
+	tr := tar.NewReader(decompressed)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := u.extractEntry(hdr, tr); err != nil {
+			ext.logger.Debugf("failed to extract %s: %s", hdr.Name, err)
+			return err
+		}
+	}
+	return u.restoreDirTimes()
+}
+
+type untarrer struct {
+	root    string
+	dest    string
+	options *archive.TarOptions
+	// dirs holds the directories created or updated during extraction so their
+	// times can be restored once all of their children have been written.
+	dirs []*dirTimes
+}
+
+type dirTimes struct {
+	path  string
+	atime time.Time
+	mtime time.Time
+}
+
+// resolve returns the host path of the archive entry name. Every component but the last one is resolved
+// inside the root; the last one is returned as is so that it can be replaced without being followed.
+func (u *untarrer) resolve(name string) (string, error) {
+	containerPath := path.Join(u.dest, name)
+	if containerPath == "/" {
+		return u.root, nil
+	}
+	parent, err := securejoin.SecureJoin(u.root, path.Dir(containerPath))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, path.Base(containerPath)), nil
+}
+
+// mkdirAll creates the parent directories of an archive entry that the archive did not explicitly include.
+func (u *untarrer) mkdirAll(name string) error {
+	current := "/"
+	for _, component := range strings.Split(path.Join(u.dest, name), "/") {
+		if component == "" {
+			continue
+		}
+		current = path.Join(current, component)
+		hostPath, err := securejoin.SecureJoin(u.root, current)
+		if err != nil {
+			return err
+		}
+		fi, err := os.Lstat(hostPath)
+		if err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("%s is not a directory", current)
+			}
+			continue
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		// the missing part of the resolved path is purely lexical, so it is safe to create it as a whole.
+		if err := os.MkdirAll(hostPath, 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *untarrer) extractEntry(hdr *tar.Header, r io.Reader) error {
+	name := path.Clean("/" + filepath.ToSlash(hdr.Name))
+	switch hdr.Typeflag {
+	case tar.TypeXGlobalHeader, tar.TypeXHeader:
+		return nil
+	}
+	if name == "/" && hdr.Typeflag != tar.TypeDir {
+		return fmt.Errorf("invalid archive entry %q", hdr.Name)
+	}
+	if err := u.mkdirAll(path.Dir(name)); err != nil {
+		return err
+	}
+	hostPath, err := u.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	if fi, err := os.Lstat(hostPath); err == nil {
+		if u.options.NoOverwriteDirNonDir {
+			if fi.IsDir() && hdr.Typeflag != tar.TypeDir {
+				return fmt.Errorf("%w: %s", ErrOverwriteDirNonDir, name)
+			}
+			if !fi.IsDir() && hdr.Typeflag == tar.TypeDir {
+				return fmt.Errorf("%w: %s", ErrOverwriteDirNonDir, name)
+			}
+		}
+		// an existing directory is merged with the directory from the archive, anything else is replaced.
+		if !(fi.IsDir() && hdr.Typeflag == tar.TypeDir) {
+			if err := os.RemoveAll(hostPath); err != nil {
+				return err
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	mode := os.FileMode(hdr.Mode).Perm()
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := os.Mkdir(hostPath, mode); err != nil && !os.IsExist(err) {
+			return err
+		}
+	case tar.TypeReg, tar.TypeRegA:
+		f, err := os.OpenFile(hostPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY|unix.O_NOFOLLOW, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	case tar.TypeSymlink:
+		// the link target is kept verbatim: it is only ever followed from inside the container, or
+		// through securejoin by this daemon.
+		if err := os.Symlink(hdr.Linkname, hostPath); err != nil {
+			return err
+		}
+	case tar.TypeLink:
+		target, err := securejoin.SecureJoin(u.root, path.Join(u.dest, path.Clean("/"+hdr.Linkname)))
+		if err != nil {
+			return err
+		}
+		if err := os.Link(target, hostPath); err != nil {
+			return err
+		}
+	case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		devMode := uint32(mode)
+		switch hdr.Typeflag {
+		case tar.TypeChar:
+			devMode |= unix.S_IFCHR
+		case tar.TypeBlock:
+			devMode |= unix.S_IFBLK
+		case tar.TypeFifo:
+			devMode |= unix.S_IFIFO
+		}
+		if err := unix.Mknod(hostPath, devMode, int(unix.Mkdev(uint32(hdr.Devmajor), uint32(hdr.Devminor)))); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unhandled tar header type %d for %s", hdr.Typeflag, hdr.Name)
+	}
+
+	uid, gid := hdr.Uid, hdr.Gid
+	if u.options.ChownOpts != nil {
+		uid, gid = u.options.ChownOpts.UID, u.options.ChownOpts.GID
+	}
+	if err := os.Lchown(hostPath, uid, gid); err != nil {
+		return err
+	}
+
+	for key, value := range hdr.PAXRecords {
+		attr, ok := strings.CutPrefix(key, xattrPAXPrefix)
+		if !ok {
+			continue
+		}
+		if err := unix.Lsetxattr(hostPath, attr, []byte(value), 0); err != nil && !errors.Is(err, unix.ENOTSUP) {
+			return err
+		}
+	}
+
+	if hdr.Typeflag == tar.TypeSymlink {
+		return nil
+	}
+	// chmod after chown, as chown clears the setuid and setgid bits.
+	if err := os.Chmod(hostPath, os.FileMode(hdr.Mode)&os.ModePerm|modeBits(hdr.Mode)); err != nil {
+		return err
+	}
+	atime := hdr.AccessTime
+	if atime.IsZero() {
+		atime = hdr.ModTime
+	}
+	if hdr.Typeflag == tar.TypeDir {
+		u.dirs = append(u.dirs, &dirTimes{path: hostPath, atime: atime, mtime: hdr.ModTime})
+		return nil
+	}
+	return os.Chtimes(hostPath, atime, hdr.ModTime)
+}
+
+func (u *untarrer) restoreDirTimes() error {
+	for i := len(u.dirs) - 1; i >= 0; i-- {
+		if err := os.Chtimes(u.dirs[i].path, u.dirs[i].atime, u.dirs[i].mtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// modeBits converts the setuid, setgid and sticky bits of a tar header mode into their os.FileMode equivalent.
+func modeBits(mode int64) os.FileMode {
+	var m os.FileMode
+	if mode&unix.S_ISUID != 0 {
+		m |= os.ModeSetuid
+	}
+	if mode&unix.S_ISGID != 0 {
+		m |= os.ModeSetgid
+	}
+	if mode&unix.S_ISVTX != 0 {
+		m |= os.ModeSticky
+	}
+	return m
+}
@@ -0,0 +1,199 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/moby/go-archive"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"github.com/runfinch/finch-daemon/mocks/mocks_logger"
+	"github.com/runfinch/finch-daemon/pkg/ecc"
+)
+
+type tarEntry struct {
+	hdr     *tar.Header
+	content string
+}
+
+func buildTar(entries ...tarEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		if e.hdr.Typeflag == tar.TypeReg {
+			e.hdr.Size = int64(len(e.content))
+		}
+		Expect(tw.WriteHeader(e.hdr)).Should(Succeed())
+		if e.content != "" {
+			_, err := tw.Write([]byte(e.content))
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+	}
+	Expect(tw.Close()).Should(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("TarExtractor's ExtractInRoot method", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		logger       *mocks_logger.Logger
+		tarExtractor TarExtractor
+		root         string
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		logger.EXPECT().Debugf(gomock.Any(), gomock.Any()).AnyTimes()
+		tarExtractor = NewTarExtractor(ecc.NewExecCmdCreator(), logger)
+		root = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(root, "dest"), 0o755)).Should(Succeed())
+	})
+	It("should extract a gzip compressed archive", func() {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write(buildTar(
+			tarEntry{hdr: &tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0o755}},
+			tarEntry{hdr: &tar.Header{Name: "dir/file", Typeflag: tar.TypeReg, Mode: 0o644}, content: "hello"},
+		))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(gz.Close()).Should(Succeed())
+
+		err = tarExtractor.ExtractInRoot(&buf, root, "/dest", &archive.TarOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		b, err := os.ReadFile(filepath.Join(root, "dest", "dir", "file"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(b)).Should(Equal("hello"))
+	})
+	It("should resolve symlinks that exist in the root inside the root", func() {
+		outside := GinkgoT().TempDir()
+		Expect(os.Symlink(outside, filepath.Join(root, "dest", "link"))).Should(Succeed())
+		data := buildTar(
+			tarEntry{hdr: &tar.Header{Name: "link/file", Typeflag: tar.TypeReg, Mode: 0o644}, content: "hello"},
+		)
+
+		err := tarExtractor.ExtractInRoot(bytes.NewReader(data), root, "/dest", &archive.TarOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = os.Stat(filepath.Join(outside, "file"))
+		Expect(errors.Is(err, os.ErrNotExist)).Should(BeTrue())
+		_, err = os.Stat(filepath.Join(root, outside, "file"))
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+	It("should not follow symlinks from the archive outside of the root", func() {
+		data := buildTar(
+			tarEntry{hdr: &tar.Header{Name: "escape", Typeflag: tar.TypeSymlink, Linkname: "../../../../../.."}},
+			tarEntry{hdr: &tar.Header{Name: "escape/file", Typeflag: tar.TypeReg, Mode: 0o644}, content: "hello"},
+		)
+
+		err := tarExtractor.ExtractInRoot(bytes.NewReader(data), root, "/dest", &archive.TarOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		b, err := os.ReadFile(filepath.Join(root, "file"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(b)).Should(Equal("hello"))
+	})
+	It("should not escape the root through dot-dot entries", func() {
+		data := buildTar(
+			tarEntry{hdr: &tar.Header{Name: "../../file", Typeflag: tar.TypeReg, Mode: 0o644}, content: "hello"},
+		)
+
+		err := tarExtractor.ExtractInRoot(bytes.NewReader(data), root, "/dest", &archive.TarOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = os.Stat(filepath.Join(root, "dest", "file"))
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+	It("should refuse to replace a directory with a file when NoOverwriteDirNonDir is set", func() {
+		Expect(os.Mkdir(filepath.Join(root, "dest", "dir"), 0o755)).Should(Succeed())
+		data := buildTar(
+			tarEntry{hdr: &tar.Header{Name: "dir", Typeflag: tar.TypeReg, Mode: 0o644}, content: "hello"},
+		)
+
+		err := tarExtractor.ExtractInRoot(bytes.NewReader(data), root, "/dest", &archive.TarOptions{NoOverwriteDirNonDir: true})
+		Expect(errors.Is(err, ErrOverwriteDirNonDir)).Should(BeTrue())
+	})
+	It("should replace a directory with a file when NoOverwriteDirNonDir is not set", func() {
+		Expect(os.Mkdir(filepath.Join(root, "dest", "dir"), 0o755)).Should(Succeed())
+		data := buildTar(
+			tarEntry{hdr: &tar.Header{Name: "dir", Typeflag: tar.TypeReg, Mode: 0o644}, content: "hello"},
+		)
+
+		err := tarExtractor.ExtractInRoot(bytes.NewReader(data), root, "/dest", &archive.TarOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		fi, err := os.Stat(filepath.Join(root, "dest", "dir"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fi.Mode().IsRegular()).Should(BeTrue())
+	})
+	It("should chown extracted files when ChownOpts is set", func() {
+		if os.Geteuid() != 0 {
+			Skip("changing file ownership requires root")
+		}
+		data := buildTar(
+			tarEntry{hdr: &tar.Header{Name: "file", Typeflag: tar.TypeReg, Mode: 0o644, Uid: 10, Gid: 10}, content: "hello"},
+		)
+
+		err := tarExtractor.ExtractInRoot(bytes.NewReader(data), root, "/dest", &archive.TarOptions{
+			ChownOpts: &archive.ChownOpts{UID: 1234, GID: 5678},
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		fi, err := os.Stat(filepath.Join(root, "dest", "file"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fi.Sys().(*syscall.Stat_t).Uid).Should(Equal(uint32(1234)))
+		Expect(fi.Sys().(*syscall.Stat_t).Gid).Should(Equal(uint32(5678)))
+	})
+})
+
+var _ = Describe("TarCreator's CreateTar method", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		logger     *mocks_logger.Logger
+		tarCreator TarCreator
+		dir        string
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		logger = mocks_logger.NewLogger(mockCtrl)
+		tarCreator = NewTarCreator(logger)
+		dir = filepath.Join(GinkgoT().TempDir(), "dir")
+		Expect(os.Mkdir(dir, 0o755)).Should(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "file"), []byte("hello"), 0o644)).Should(Succeed())
+		Expect(os.Symlink("/etc/passwd", filepath.Join(dir, "link"))).Should(Succeed())
+	})
+	readNames := func(data []byte) map[string]*tar.Header {
+		names := map[string]*tar.Header{}
+		tr := tar.NewReader(bytes.NewReader(data))
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return names
+			}
+			Expect(err).ShouldNot(HaveOccurred())
+			names[hdr.Name] = hdr
+		}
+	}
+	It("should archive the directory itself", func() {
+		var buf bytes.Buffer
+		Expect(tarCreator.CreateTar(dir, &buf, false)).Should(Succeed())
+		names := readNames(buf.Bytes())
+		Expect(names).Should(HaveKey("dir/"))
+		Expect(names).Should(HaveKey("dir/file"))
+		Expect(names).Should(HaveKey("dir/link"))
+		Expect(names["dir/link"].Typeflag).Should(Equal(byte(tar.TypeSymlink)))
+		Expect(names["dir/link"].Linkname).Should(Equal("/etc/passwd"))
+	})
+	It("should archive only the contents of the directory with slashDot", func() {
+		var buf bytes.Buffer
+		Expect(tarCreator.CreateTar(dir, &buf, true)).Should(Succeed())
+		names := readNames(buf.Bytes())
+		Expect(names).Should(HaveKey("./file"))
+		Expect(names).ShouldNot(HaveKey("dir/file"))
+	})
+})
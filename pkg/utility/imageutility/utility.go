@@ -4,8 +4,11 @@
 package imageutility
 
 import (
+	"context"
 	"strings"
 
+	"github.com/containerd/containerd/v2/core/images"
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/distribution/reference"
 )
 
@@ -43,3 +46,25 @@ func refNeedsTag(ref reference.Reference) bool {
 	_, digested := ref.(reference.Digested)
 	return !(tagged || digested)
 }
+
+// TagPinnedImage names the image pulled with the pinned reference with ref, as a pull of ref would. The record of
+// the pinned reference is then deleted unless it is kept, so that the image does not get another name by the pull.
+func TagPinnedImage(ctx context.Context, store images.Store, pinned, ref string, keepPinned bool) error {
+	img, err := store.Get(ctx, pinned)
+	if err != nil {
+		return err
+	}
+	img.Name = ref
+	if _, err := store.Update(ctx, img); err != nil {
+		if !cerrdefs.IsNotFound(err) {
+			return err
+		}
+		if _, err := store.Create(ctx, img); err != nil {
+			return err
+		}
+	}
+	if keepPinned {
+		return nil
+	}
+	return store.Delete(ctx, pinned)
+}